@@ -6,6 +6,7 @@ import (
 	gohttp "net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
 	"github.com/streadway/amqp"
@@ -14,18 +15,27 @@ import (
 	"github.com/WebEngineeringGroupI/backend/internal/app"
 	"github.com/WebEngineeringGroupI/backend/pkg/application/grpc"
 	"github.com/WebEngineeringGroupI/backend/pkg/application/http"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event/serializer/json"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/expirer"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/healthchecker"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/validationsaver"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/broker/rabbitmq"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/clock"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/postgres"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/geolocation"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/metrics"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/netsafety"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/reachable"
 )
 
 type factory struct {
+	ctx                  context.Context
 	metricsSingleton     url.Metrics
-	eventBrokerSingleton event.Broker
+	eventBrokerSingleton *event.QueuedBroker
 }
 
 func (f *factory) NewHTTPAndGRPCWebRouter() gohttp.Handler {
@@ -55,15 +65,55 @@ func (f *factory) httpConfig() http.Config {
 		CustomMetrics:              f.customMetrics(),
 		ShortURLRepository:         f.newShortURLRepository(),
 		LoadBalancedURLsRepository: f.newLoadBalancedURLsRepository(),
+		LoadBalancerCounters:       f.newPostgresDB(json.NewSerializer()),
+		ClickerRepository:          f.newPostgresDB(json.NewSerializer()),
+		ExcludeBotClicks:           app.ExcludeBotClicks(),
+		Geolocator:                 f.newGeolocator(),
+		AnonymizeIPs:               app.AnonymizeIPs(),
+		Normalizer:                 f.newNormalizer(),
 	}
 }
 
+func (f *factory) newNormalizer() *url.Normalizer {
+	var options []url.NormalizerOption
+	if app.SortQueryParameters() {
+		options = append(options, url.WithSortedQueryParameters())
+	}
+	if app.StripTrackingParameters() {
+		options = append(options, url.WithoutTrackingParameters())
+	}
+	return url.NewNormalizer(options...)
+}
+
+// newGeolocator returns nil when there is no geolocation database, so the clicks are not located
+func (f *factory) newGeolocator() click.Geolocator {
+	path := app.GeolocationDatabase()
+	if path == "" {
+		return nil
+	}
+
+	if strings.HasSuffix(path, ".csv") {
+		geolocator, err := geolocation.NewCSV(path)
+		if err != nil {
+			log.Fatalf("unable to load the geolocation database: %s", err)
+		}
+		return geolocator
+	}
+
+	geolocator, err := geolocation.NewMMDB(path)
+	if err != nil {
+		log.Fatalf("unable to load the geolocation database: %s", err)
+	}
+	return geolocator
+}
+
 func (f *factory) grpcConfig() grpc.Config {
 	return grpc.Config{
 		BaseDomain:                 f.baseDomain(),
 		ShortURLRepository:         f.newShortURLRepository(),
 		CustomMetrics:              f.customMetrics(),
 		LoadBalancedURLsRepository: f.newLoadBalancedURLsRepository(),
+		Normalizer:                 f.newNormalizer(),
 	}
 }
 
@@ -95,35 +145,94 @@ func (f *factory) newPostgresDB(eventSerializer event.Serializer) *postgres.DB {
 }
 
 func (f *factory) newShortURLRepository() event.Repository {
-	return event.NewRepository(&url.ShortURL{}, f.newPostgresDB(json.NewSerializer(
-		&url.ShortURLCreated{},
-		&url.ShortURLVerified{},
-		&url.ShortURLClicked{},
-	)), f.eventBroker())
+	db := f.newPostgresDB(json.NewSerializer(shortURLEvents()...))
+	return event.NewRepository(&url.ShortURL{}, db, f.eventBroker(), event.WithSnapshots(db, app.SnapshotEvery()))
 }
 
 func (f *factory) newLoadBalancedURLsRepository() event.Repository {
-	return event.NewRepository(&url.LoadBalancedURL{}, f.newPostgresDB(json.NewSerializer(
-		&url.LoadBalancedURLCreated{},
-		&url.LoadBalancedURLVerified{},
-	)), f.eventBroker())
+	db := f.newPostgresDB(json.NewSerializer(loadBalancedURLEvents()...))
+	return event.NewRepository(&url.LoadBalancedURL{}, db, f.eventBroker(), event.WithSnapshots(db, app.SnapshotEvery()))
 }
 
-func (f *factory) eventBroker() event.Broker {
+func (f *factory) eventBroker() *event.QueuedBroker {
 	if f.eventBrokerSingleton == nil {
-		f.eventBrokerSingleton = event.NewBroker()
+		retryPolicy := event.RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     5 * time.Second,
+		}
+		deadLetters := f.newPostgresDB(f.allEventsSerializer())
+		f.eventBrokerSingleton = event.NewQueuedBroker(f.ctx, 100, retryPolicy, deadLetters)
 	}
 	return f.eventBrokerSingleton
 }
 
+// WaitForEventBroker blocks until the events queued in the event broker are delivered, once the context is cancelled.
+func (f *factory) WaitForEventBroker() {
+	f.eventBroker().Wait()
+}
+
+func (f *factory) NewProjectionRunner() *projection.Runner {
+	db := f.newPostgresDB(f.allEventsSerializer())
+	return projection.NewRunner(db, f.eventBroker(), db, 100, 1*time.Second)
+}
+
+func (f *factory) NewShortURLViewProjection() projection.Projection {
+	return projection.NewShortURLViewProjection(f.newPostgresDB(json.NewSerializer()))
+}
+
+// allEventsSerializer is used to read the events of all the entities of the store at once,
+// so it has to know every event type saved.
+func (f *factory) allEventsSerializer() event.Serializer {
+	return json.NewSerializer(append(shortURLEvents(), loadBalancedURLEvents()...)...)
+}
+
+func shortURLEvents() []event.Event {
+	return []event.Event{
+		&url.ShortURLCreated{},
+		&url.ShortURLVerified{},
+		&url.ShortURLRejected{},
+		&url.ShortURLClicked{},
+		&url.ShortURLExpirationScheduled{},
+		&url.ShortURLExpired{},
+		&url.ShortURLDisabled{},
+		&url.ShortURLEnabled{},
+		&url.ShortURLDeleted{},
+		&url.ShortURLTargetChanged{},
+	}
+}
+
+func loadBalancedURLEvents() []event.Event {
+	return []event.Event{
+		&url.LoadBalancedURLCreated{},
+		&url.LoadBalancedURLVerified{},
+		&url.LoadBalancedURLTargetAdded{},
+		&url.LoadBalancedURLTargetRemoved{},
+		&url.LoadBalancedURLTargetsReplaced{},
+		&url.LoadBalancedURLUnhealthy{},
+		&url.LoadBalancedURLRecovered{},
+		&url.LoadBalancedURLExpirationScheduled{},
+		&url.LoadBalancedURLExpired{},
+	}
+}
+
 func (f *factory) NewValidationSaver(ctx context.Context) *validationsaver.Service {
 	serializer := json.NewSerializer(
 		&url.ShortURLVerified{},
+		&url.ShortURLRejected{},
 		&url.LoadBalancedURLVerified{},
 	)
-	eventRepo := event.NewRepository(&url.ShortURL{}, f.newPostgresDB(serializer), f.eventBroker())
 
-	return validationsaver.NewService(eventRepo, f.newRabbitMQReceiver(ctx), serializer)
+	return validationsaver.NewService(f.newShortURLRepository(), f.newLoadBalancedURLsRepository(), f.newRabbitMQReceiver(ctx), serializer)
+}
+
+func (f *factory) NewExpirer() *expirer.Service {
+	return expirer.NewService(f.NewProjectionRunner(), f.newPostgresDB(json.NewSerializer()), f.newShortURLRepository(), f.newLoadBalancedURLsRepository(), clock.NewFromSystem(), 1*time.Second)
+}
+
+func (f *factory) NewHealthChecker() *healthchecker.Service {
+	validator := f.reachableValidator()
+	return healthchecker.NewService(f.NewProjectionRunner(), f.newPostgresDB(json.NewSerializer()), f.newLoadBalancedURLsRepository(), validator, clock.NewFromSystem(), app.HealthCheckInterval())
 }
 
 func (f *factory) newRabbitMQReceiver(ctx context.Context) *rabbitmq.ReceiverClient {
@@ -167,6 +276,18 @@ func (f *factory) defineRabbitMQRoutingToReceive(ch *amqp.Channel) {
 	}
 }
 
-func newFactory() *factory {
-	return &factory{}
+func newFactory(ctx context.Context) *factory {
+	return &factory{
+		ctx: ctx,
+	}
+}
+
+// reachableValidator tries HEAD before GET, and retries the URLs with transient failures
+func (f *factory) reachableValidator() *reachable.Validator {
+	return reachable.NewValidator(netsafety.NewHTTPClient(5*time.Second), 5*time.Second,
+		reachable.WithAcceptedStatusClasses(app.ReachableAcceptedStatusClasses()...),
+		reachable.WithHeadFirst(),
+		reachable.WithRetries(app.ReachableRetries(), 500*time.Millisecond),
+		reachable.WithUserAgent(app.ReachableUserAgent()),
+	)
 }
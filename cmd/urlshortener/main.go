@@ -14,15 +14,19 @@ import (
 func main() {
 	ctx := gracefulShutdownOnSignal()
 	wg := sync.WaitGroup{}
-	factory := newFactory()
+	factory := newFactory(ctx)
 
 	launchHTTPServer(ctx, factory, &wg)
 	launchGRPCServer(ctx, factory, &wg)
 	launchValidationSaver(ctx, factory, &wg)
+	launchProjections(ctx, factory, &wg)
+	launchExpirer(ctx, factory, &wg)
+	launchHealthChecker(ctx, factory, &wg)
 
 	<-ctx.Done()
 	log.Println("attempting graceful shutdown...")
 	wg.Wait()
+	factory.WaitForEventBroker()
 	log.Println("server exited properly")
 }
 
@@ -88,6 +92,46 @@ func launchValidationSaver(ctx context.Context, f *factory, wg *sync.WaitGroup)
 	}()
 }
 
+func launchProjections(ctx context.Context, f *factory, wg *sync.WaitGroup) {
+	runner := f.NewProjectionRunner()
+	shortURLViewProjection := f.NewShortURLViewProjection()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Printf("launching %s projection", shortURLViewProjection.Name())
+		err := runner.Run(ctx, shortURLViewProjection)
+		if err != nil {
+			log.Fatalf("unable to start %s projection: %s", shortURLViewProjection.Name(), err)
+		}
+		log.Printf("closed %s projection", shortURLViewProjection.Name())
+	}()
+}
+
+func launchExpirer(ctx context.Context, f *factory, wg *sync.WaitGroup) {
+	expirerService := f.NewExpirer()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Println("launching expirer service")
+		expirerService.Start(ctx)
+		log.Println("closed expirer service")
+	}()
+}
+
+func launchHealthChecker(ctx context.Context, f *factory, wg *sync.WaitGroup) {
+	healthCheckerService := f.NewHealthChecker()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Println("launching health checker service")
+		healthCheckerService.Start(ctx)
+		log.Println("closed health checker service")
+	}()
+}
+
 func gracefulShutdownOnSignal() context.Context {
 	ctx, _ := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	return ctx
@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"log"
-	"net/http"
 	"time"
 
 	"github.com/streadway/amqp"
@@ -14,10 +13,13 @@ import (
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/validator"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/broker/rabbitmq"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/clock"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/domainlist"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/netsafety"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/pipeline"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/reachable"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/safebrowsing"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/schema"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/threatlist"
 )
 
 type Factory struct {
@@ -29,8 +31,8 @@ func (f *Factory) NewValidator(ctx context.Context) *validator.Service {
 	return validator.NewService(
 		f.brokerReceiver(ctx),
 		f.brokerSender(ctx),
-		f.urlValidator(),
-		json.NewSerializer(&url.ShortURLCreated{}, &url.LoadBalancedURLCreated{}),
+		f.urlValidator(ctx),
+		json.NewSerializer(&url.ShortURLCreated{}, &url.ShortURLTargetChanged{}, &url.LoadBalancedURLCreated{}, &url.LoadBalancedURLTargetAdded{}, &url.LoadBalancedURLTargetsReplaced{}),
 		clock.NewFromSystem())
 }
 
@@ -88,15 +90,73 @@ func (f *Factory) defineRabbitMQRoutingToSend(ch *amqp.Channel) {
 	}
 }
 
-func (f *Factory) urlValidator() url.Validator {
+func (f *Factory) urlValidator(ctx context.Context) url.Validator {
+	validators := []url.Validator{schema.NewValidator("https", "http")}
+	if domainList := app.DomainListFile(); domainList != "" {
+		validators = append(validators, f.domainListValidator(ctx, domainList))
+	}
+	validators = append(validators,
+		netsafety.NewValidator(),
+		f.reachableValidator(),
+		f.threatValidator(),
+	)
+	return pipeline.NewValidatorWithMode(pipeline.RunAll, validators...)
+}
+
+// threatValidator checks the URLs with the Safe Browsing API, or with the local threat lists instead
+// when THREAT_CHECK is local. There is no fallback to the local lists when the API can't be reached.
+func (f *Factory) threatValidator() url.Validator {
+	if app.ThreatCheck() == "local" {
+		return f.threatListValidator()
+	}
+
 	safebrowsingValidator, err := safebrowsing.NewValidator(app.SafeBrowsingAPIKey())
 	if err != nil {
 		log.Fatalf("unable to create safebrowsing validator: %s", err)
 	}
+	return safebrowsingValidator
+}
+
+func (f *Factory) threatListValidator() *threatlist.Validator {
+	mode := threatlist.LookupExact
+	if app.ThreatLookupCanonical() {
+		mode = threatlist.LookupCanonical
+	}
+
+	var lists []threatlist.List
+	for _, path := range app.ThreatHostLists() {
+		lists = append(lists, threatlist.HostList(path))
+	}
+	for _, path := range app.ThreatURLPrefixLists() {
+		lists = append(lists, threatlist.URLPrefixList(path))
+	}
+	for _, path := range app.ThreatHashPrefixFiles() {
+		lists = append(lists, threatlist.HashPrefixFile(path, app.ThreatHashPrefixSize()))
+	}
+
+	threatListValidator, err := threatlist.NewValidator(mode, lists...)
+	if err != nil {
+		log.Fatalf("unable to create threat list validator: %s", err)
+	}
+	return threatListValidator
+}
+
+// domainListValidator is reloaded when the file changes until the context is cancelled
+func (f *Factory) domainListValidator(ctx context.Context, path string) *domainlist.Validator {
+	domainListValidator, err := domainlist.NewValidator(path, app.DomainListReloadInterval())
+	if err != nil {
+		log.Fatalf("unable to create domain list validator: %s", err)
+	}
+	go domainListValidator.Start(ctx)
+	return domainListValidator
+}
 
-	return pipeline.NewValidator(
-		schema.NewValidator("https", "http"),
-		reachable.NewValidator(http.DefaultClient, 5*time.Second),
-		safebrowsingValidator,
+// reachableValidator tries HEAD before GET, and retries the URLs with transient failures
+func (f *Factory) reachableValidator() *reachable.Validator {
+	return reachable.NewValidator(netsafety.NewHTTPClient(5*time.Second), 5*time.Second,
+		reachable.WithAcceptedStatusClasses(app.ReachableAcceptedStatusClasses()...),
+		reachable.WithHeadFirst(),
+		reachable.WithRetries(app.ReachableRetries(), 500*time.Millisecond),
+		reachable.WithUserAgent(app.ReachableUserAgent()),
 	)
 }
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS domain_event_snapshot
+(
+    id         VARCHAR   NOT NULL PRIMARY KEY,
+    version    INTEGER   NOT NULL,
+    payload    JSON      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS domain_event_position;
+ALTER TABLE domain_event DROP COLUMN IF EXISTS position;
@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE domain_event
+    ADD COLUMN IF NOT EXISTS position BIGSERIAL NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS domain_event_position
+    ON domain_event (position);
+
+COMMIT TRANSACTION;
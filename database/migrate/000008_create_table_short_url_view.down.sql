@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS short_url_view;
+DROP TABLE IF EXISTS projection_checkpoint;
@@ -0,0 +1,26 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS short_url_view
+(
+    hash         VARCHAR        NOT NULL PRIMARY KEY,
+    original_url VARCHAR(65535) NOT NULL,
+    is_valid     BOOLEAN        NOT NULL DEFAULT FALSE,
+    clicks       INTEGER        NOT NULL DEFAULT 0,
+    created_at   TIMESTAMP      NOT NULL,
+    version      INTEGER        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS short_url_view_is_valid
+    ON short_url_view (is_valid);
+
+CREATE INDEX IF NOT EXISTS short_url_view_created_at
+    ON short_url_view (created_at);
+
+CREATE TABLE IF NOT EXISTS projection_checkpoint
+(
+    name       VARCHAR   NOT NULL PRIMARY KEY,
+    position   BIGINT    NOT NULL,
+    updated_at TIMESTAMP DEFAULT now()
+);
+
+COMMIT TRANSACTION;
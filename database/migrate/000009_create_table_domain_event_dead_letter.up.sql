@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS domain_event_dead_letter
+(
+    id         SERIAL PRIMARY KEY,
+    entity_id  VARCHAR NOT NULL,
+    subscriber VARCHAR NOT NULL,
+    error      TEXT    NOT NULL,
+    attempts   INTEGER NOT NULL,
+    payload    JSON    NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
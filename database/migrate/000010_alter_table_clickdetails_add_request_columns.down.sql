@@ -0,0 +1,13 @@
+BEGIN TRANSACTION;
+
+DROP INDEX IF EXISTS clickdetails_hash;
+
+ALTER TABLE clickdetails
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS referer,
+    DROP COLUMN IF EXISTS accept_language,
+    DROP COLUMN IF EXISTS clicked_at,
+    ALTER COLUMN ip TYPE VARCHAR(39),
+    ALTER COLUMN hash TYPE VARCHAR(8);
+
+COMMIT TRANSACTION;
@@ -0,0 +1,14 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE clickdetails
+    ALTER COLUMN hash TYPE VARCHAR(64),
+    ALTER COLUMN ip TYPE VARCHAR(45),
+    ADD COLUMN IF NOT EXISTS user_agent      TEXT      NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS referer         TEXT      NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS accept_language TEXT      NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS clicked_at      TIMESTAMP NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS clickdetails_hash
+    ON clickdetails (hash);
+
+COMMIT TRANSACTION;
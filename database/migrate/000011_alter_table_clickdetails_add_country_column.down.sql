@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+DROP INDEX IF EXISTS clickdetails_hash_clicked_at;
+
+ALTER TABLE clickdetails
+    DROP COLUMN IF EXISTS country;
+
+COMMIT TRANSACTION;
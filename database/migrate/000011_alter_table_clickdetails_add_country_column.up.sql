@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE clickdetails
+    ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS clickdetails_hash_clicked_at
+    ON clickdetails (hash, clicked_at);
+
+COMMIT TRANSACTION;
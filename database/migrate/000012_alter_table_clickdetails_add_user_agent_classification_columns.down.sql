@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE clickdetails
+    DROP COLUMN IF EXISTS is_bot,
+    DROP COLUMN IF EXISTS device,
+    DROP COLUMN IF EXISTS os,
+    DROP COLUMN IF EXISTS browser;
+
+COMMIT TRANSACTION;
@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE clickdetails
+    ADD COLUMN IF NOT EXISTS browser VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS os      VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS device  VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS is_bot  BOOLEAN     NOT NULL DEFAULT FALSE;
+
+COMMIT TRANSACTION;
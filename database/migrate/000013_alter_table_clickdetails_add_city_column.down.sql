@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE clickdetails
+    DROP COLUMN IF EXISTS city;
+
+COMMIT TRANSACTION;
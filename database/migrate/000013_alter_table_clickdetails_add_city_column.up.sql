@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE clickdetails
+    ADD COLUMN IF NOT EXISTS city VARCHAR(128) NOT NULL DEFAULT '';
+
+COMMIT TRANSACTION;
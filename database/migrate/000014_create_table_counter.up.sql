@@ -0,0 +1,10 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS counter
+(
+    key        VARCHAR   NOT NULL PRIMARY KEY,
+    value      BIGINT    NOT NULL,
+    updated_at TIMESTAMP DEFAULT now()
+);
+
+COMMIT TRANSACTION;
@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE short_url_view
+    DROP COLUMN IF EXISTS deleted;
+
+COMMIT TRANSACTION;
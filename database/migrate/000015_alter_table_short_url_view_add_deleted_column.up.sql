@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE short_url_view
+    ADD COLUMN IF NOT EXISTS deleted BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMIT TRANSACTION;
@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE short_url_view
+    DROP COLUMN IF EXISTS is_rejected,
+    DROP COLUMN IF EXISTS rejection_reason;
+
+COMMIT TRANSACTION;
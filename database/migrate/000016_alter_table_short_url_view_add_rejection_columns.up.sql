@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE short_url_view
+    ADD COLUMN IF NOT EXISTS is_rejected      BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS rejection_reason TEXT    NOT NULL DEFAULT '';
+
+COMMIT TRANSACTION;
@@ -0,0 +1,13 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS pending_expiration
+(
+    id            VARCHAR   NOT NULL PRIMARY KEY,
+    load_balanced BOOLEAN   NOT NULL DEFAULT FALSE,
+    expires_at    TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS pending_expiration_expires_at
+    ON pending_expiration (expires_at);
+
+COMMIT TRANSACTION;
@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS monitored_link
+(
+    id VARCHAR NOT NULL PRIMARY KEY
+);
+
+COMMIT TRANSACTION;
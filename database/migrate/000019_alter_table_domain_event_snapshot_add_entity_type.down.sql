@@ -0,0 +1,11 @@
+BEGIN TRANSACTION;
+
+DELETE FROM domain_event_snapshot;
+
+ALTER TABLE domain_event_snapshot
+    DROP CONSTRAINT IF EXISTS domain_event_snapshot_pkey,
+    DROP COLUMN IF EXISTS entity_type,
+    DROP COLUMN IF EXISTS schema_version,
+    ADD PRIMARY KEY (id);
+
+COMMIT TRANSACTION;
@@ -0,0 +1,12 @@
+BEGIN TRANSACTION;
+
+-- the snapshots taken before don't know the type of their entity, they are taken again when the entities are loaded
+DELETE FROM domain_event_snapshot;
+
+ALTER TABLE domain_event_snapshot
+    ADD COLUMN IF NOT EXISTS entity_type    VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS schema_version VARCHAR NOT NULL DEFAULT '',
+    DROP CONSTRAINT IF EXISTS domain_event_snapshot_pkey,
+    ADD PRIMARY KEY (entity_type, id);
+
+COMMIT TRANSACTION;
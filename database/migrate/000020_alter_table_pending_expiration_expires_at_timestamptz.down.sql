@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE pending_expiration
+    ALTER COLUMN expires_at TYPE TIMESTAMP USING expires_at AT TIME ZONE 'UTC';
+
+COMMIT TRANSACTION;
@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE clickdetails
+    ALTER COLUMN clicked_at TYPE TIMESTAMP USING clicked_at AT TIME ZONE 'UTC';
+
+COMMIT TRANSACTION;
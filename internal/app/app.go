@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/postgres"
 )
@@ -38,6 +40,148 @@ func RabbitMQConnectionString() string {
 		rabbitMQPort)
 }
 
+func SnapshotEvery() int {
+	snapshotEvery, err := strconv.Atoi(optionalEnvVarValue("SNAPSHOT_EVERY", "100"))
+	if err != nil || snapshotEvery <= 0 {
+		log.Fatalf("unable to parse SNAPSHOT_EVERY as a positive int, make sure it has a valid value")
+	}
+	return snapshotEvery
+}
+
+func ExcludeBotClicks() bool {
+	excludeBotClicks, err := strconv.ParseBool(optionalEnvVarValue("EXCLUDE_BOT_CLICKS", "false"))
+	if err != nil {
+		log.Fatalf("unable to parse EXCLUDE_BOT_CLICKS as a bool, make sure it has a valid value")
+	}
+	return excludeBotClicks
+}
+
+// GeolocationDatabase is the path of the mmdb or csv database the clicks are located with,
+// empty if they must not be located
+func GeolocationDatabase() string {
+	return optionalEnvVarValue("GEOLOCATION_DATABASE", "")
+}
+
+func AnonymizeIPs() bool {
+	anonymizeIPs, err := strconv.ParseBool(optionalEnvVarValue("ANONYMIZE_IPS", "false"))
+	if err != nil {
+		log.Fatalf("unable to parse ANONYMIZE_IPS as a bool, make sure it has a valid value")
+	}
+	return anonymizeIPs
+}
+
+func SortQueryParameters() bool {
+	sortQueryParameters, err := strconv.ParseBool(optionalEnvVarValue("SORT_QUERY_PARAMETERS", "false"))
+	if err != nil {
+		log.Fatalf("unable to parse SORT_QUERY_PARAMETERS as a bool, make sure it has a valid value")
+	}
+	return sortQueryParameters
+}
+
+func StripTrackingParameters() bool {
+	stripTrackingParameters, err := strconv.ParseBool(optionalEnvVarValue("STRIP_TRACKING_PARAMETERS", "false"))
+	if err != nil {
+		log.Fatalf("unable to parse STRIP_TRACKING_PARAMETERS as a bool, make sure it has a valid value")
+	}
+	return stripTrackingParameters
+}
+
+// HealthCheckInterval is how often the targets of the load balanced URLs are checked
+func HealthCheckInterval() time.Duration {
+	healthCheckInterval, err := time.ParseDuration(optionalEnvVarValue("HEALTH_CHECK_INTERVAL", "1m"))
+	if err != nil || healthCheckInterval <= 0 {
+		log.Fatalf("unable to parse HEALTH_CHECK_INTERVAL as a positive duration, make sure it has a valid value")
+	}
+	return healthCheckInterval
+}
+
+// DomainListFile is the path of the file with the allow and deny rules of the hosts of the URLs,
+// empty if they must not be checked
+func DomainListFile() string {
+	return optionalEnvVarValue("DOMAIN_LIST_FILE", "")
+}
+
+// DomainListReloadInterval is how often the domain list file is checked for changes
+func DomainListReloadInterval() time.Duration {
+	reloadInterval, err := time.ParseDuration(optionalEnvVarValue("DOMAIN_LIST_RELOAD_INTERVAL", "30s"))
+	if err != nil || reloadInterval <= 0 {
+		log.Fatalf("unable to parse DOMAIN_LIST_RELOAD_INTERVAL as a positive duration, make sure it has a valid value")
+	}
+	return reloadInterval
+}
+
+// ReachableAcceptedStatusClasses are the classes of the status codes of the reachable URLs, like 2 for the 2xx ones
+func ReachableAcceptedStatusClasses() []int {
+	var classes []int
+	for _, value := range strings.Split(optionalEnvVarValue("REACHABLE_ACCEPTED_STATUS_CLASSES", "2"), ",") {
+		class, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || class < 1 || class > 5 {
+			log.Fatalf("unable to parse REACHABLE_ACCEPTED_STATUS_CLASSES as a list of status classes from 1 to 5, make sure it has a valid value")
+		}
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+// ReachableRetries is how many times the URLs that time out or fail with a 5xx status code are checked again
+func ReachableRetries() int {
+	retries, err := strconv.Atoi(optionalEnvVarValue("REACHABLE_RETRIES", "2"))
+	if err != nil || retries < 0 {
+		log.Fatalf("unable to parse REACHABLE_RETRIES as a non negative int, make sure it has a valid value")
+	}
+	return retries
+}
+
+// ReachableUserAgent is the User-Agent of the requests made to check if the URLs are reachable
+func ReachableUserAgent() string {
+	return optionalEnvVarValue("REACHABLE_USER_AGENT", "WebEngineeringGroupI-URLShortener/1.0")
+}
+
+// ThreatCheck is how the URLs are checked for threats, "remote" with the Safe Browsing API, or "local" with
+// the threat lists loaded from files
+func ThreatCheck() string {
+	threatCheck := optionalEnvVarValue("THREAT_CHECK", "remote")
+	if threatCheck != "remote" && threatCheck != "local" {
+		log.Fatalf("unable to parse THREAT_CHECK, make sure it is either remote or local")
+	}
+	return threatCheck
+}
+
+// ThreatHostLists are the paths of the files with the hosts of the threats
+func ThreatHostLists() []string {
+	return optionalEnvVarList("THREAT_HOST_LISTS")
+}
+
+// ThreatURLPrefixLists are the paths of the files with the URL prefixes of the threats
+func ThreatURLPrefixLists() []string {
+	return optionalEnvVarList("THREAT_URL_PREFIX_LISTS")
+}
+
+// ThreatHashPrefixFiles are the paths of the files with the hash prefixes of the threats, in the Safe Browsing v4 format
+func ThreatHashPrefixFiles() []string {
+	return optionalEnvVarList("THREAT_HASH_PREFIX_FILES")
+}
+
+// ThreatHashPrefixSize is the size in bytes of the hash prefixes of the ThreatHashPrefixFiles. Only the full
+// hashes of 32 bytes reject the URLs, the ones matching a shorter prefix can't be validated.
+func ThreatHashPrefixSize() int {
+	prefixSize, err := strconv.Atoi(optionalEnvVarValue("THREAT_HASH_PREFIX_SIZE", "32"))
+	if err != nil || prefixSize < 4 || prefixSize > 32 {
+		log.Fatalf("unable to parse THREAT_HASH_PREFIX_SIZE as an int from 4 to 32, make sure it has a valid value")
+	}
+	return prefixSize
+}
+
+// ThreatLookupCanonical tells if the URLs are canonicalized as the Safe Browsing API does before looking them up
+// in the threat lists, instead of looking them up as they are written, which can't be done with ThreatHashPrefixFiles
+func ThreatLookupCanonical() bool {
+	lookupCanonical, err := strconv.ParseBool(optionalEnvVarValue("THREAT_LOOKUP_CANONICAL", "true"))
+	if err != nil {
+		log.Fatalf("unable to parse THREAT_LOOKUP_CANONICAL as a bool, make sure it has a valid value")
+	}
+	return lookupCanonical
+}
+
 func SafeBrowsingAPIKey() string {
 	return mandatoryEnvVarValue("SAFE_BROWSING_API_KEY")
 }
@@ -57,3 +201,14 @@ func optionalEnvVarValue(variable string, defaultValue string) string {
 	}
 	return value
 }
+
+// optionalEnvVarList splits the comma separated values of the variable, empty if it's not set
+func optionalEnvVarList(variable string) []string {
+	var values []string
+	for _, value := range strings.Split(optionalEnvVarValue(variable, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,51 @@
+package grpc_test
+
+import (
+	genproto "github.com/WebEngineeringGroupI/genproto-go/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The features pending in the contract of genproto-go, described in the gRPC API section of CONTRIBUTING.md.
+// Once a bump of the module defines any of them, its entry fails until the server implements it and the entry is removed.
+var _ = Describe("Server contract", func() {
+	rpcs := func() []string {
+		var names []string
+		for _, method := range genproto.URLShortening_ServiceDesc.Methods {
+			names = append(names, method.MethodName)
+		}
+		for _, stream := range genproto.URLShortening_ServiceDesc.Streams {
+			names = append(names, stream.StreamName)
+		}
+		return names
+	}
+
+	DescribeTable("doesn't define the RPCs pending to be implemented yet",
+		func(rpc string) {
+			Expect(rpcs()).ToNot(ContainElement(rpc))
+		},
+		Entry("DisableShortURL (user-009)", "DisableShortURL"),
+		Entry("EnableShortURL (user-009)", "EnableShortURL"),
+		Entry("DeleteShortURL (user-009)", "DeleteShortURL"),
+		Entry("ChangeShortURLTarget (user-009)", "ChangeShortURLTarget"),
+		Entry("GetShortURLStats (user-011)", "GetShortURLStats"),
+		Entry("AddLoadBalancedURLTarget (user-017)", "AddLoadBalancedURLTarget"),
+		Entry("RemoveLoadBalancedURLTarget (user-017)", "RemoveLoadBalancedURLTarget"),
+		Entry("ReplaceLoadBalancedURLTargets (user-017)", "ReplaceLoadBalancedURLTargets"),
+		Entry("GetShortURL (user-020)", "GetShortURL"),
+	)
+
+	DescribeTable("doesn't define the fields pending to be implemented yet",
+		func(message proto.Message, field protoreflect.Name) {
+			Expect(message.ProtoReflect().Descriptor().Fields().ByName(field)).To(BeNil())
+		},
+		Entry("the alias of ShortSingleURLRequest (user-006)", &genproto.ShortSingleURLRequest{}, protoreflect.Name("alias")),
+		Entry("the expiration time of ShortSingleURLRequest (user-008)", &genproto.ShortSingleURLRequest{}, protoreflect.Name("expires_at")),
+		Entry("the TTL of ShortSingleURLRequest (user-008)", &genproto.ShortSingleURLRequest{}, protoreflect.Name("ttl")),
+		Entry("the expiration time of BalanceURLsRequest (user-008)", &genproto.BalanceURLsRequest{}, protoreflect.Name("expires_at")),
+		Entry("the TTL of BalanceURLsRequest (user-008)", &genproto.BalanceURLsRequest{}, protoreflect.Name("ttl")),
+	)
+})
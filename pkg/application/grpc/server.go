@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 
@@ -16,6 +17,9 @@ import (
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/clock"
 )
 
+// server implements the RPCs the URLShortening service of genproto-go defines. The ones it doesn't define yet, like
+// the ones of the url.ShortURLManager, the url.LoadBalancedURLManager, the click.Analytics and the validation status
+// of the links, are pending as described in the gRPC API section of CONTRIBUTING.md.
 type server struct {
 	genproto.UnimplementedURLShorteningServer
 	baseDomain   string
@@ -68,9 +72,10 @@ func (s *server) ShortSingleURL(ctx context.Context, req *genproto.ShortSingleUR
 		return nil, status.Errorf(codes.FailedPrecondition, "empty URL provided")
 	}
 
+	// the request has no alias nor expiration, see the gRPC API section of CONTRIBUTING.md
 	shortURL, err := s.urlShortener.HashFromURL(ctx, req.GetUrl())
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, status.Errorf(codeFromError(err), err.Error())
 	}
 	return &genproto.ShortSingleURLResponse{
 		ShortUrl: fmt.Sprintf("%s/r/%s", s.baseDomain, shortURL.Hash),
@@ -80,26 +85,45 @@ func (s *server) ShortSingleURL(ctx context.Context, req *genproto.ShortSingleUR
 
 func (s *server) BalanceURLs(ctx context.Context, req *genproto.BalanceURLsRequest) (*genproto.BalanceURLsResponse, error) {
 	//fixme(fede): use the ctx for cancellation
+	// the request has no expiration, see the gRPC API section of CONTRIBUTING.md
 	balancedURL, err := s.loadBalancer.ShortURLs(ctx, req.GetUrls())
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, status.Errorf(codeFromError(err), err.Error())
 	}
 	return &genproto.BalanceURLsResponse{ShortUrl: fmt.Sprintf("%s/lb/%s", s.baseDomain, balancedURL.Hash)}, nil
 }
 
+// codeFromError returns InvalidArgument for the errors caused by the URLs of the request, and Internal for the rest
+func codeFromError(err error) codes.Code {
+	switch {
+	case errors.Is(err, url.ErrInvalidLongURLSpecified), errors.Is(err, url.ErrNoURLsSpecified),
+		errors.Is(err, url.ErrTooMuchMultipleURLs), errors.Is(err, url.ErrDuplicatedTarget):
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
+
 type Config struct {
 	BaseDomain                 string
 	ShortURLRepository         event.Repository
 	CustomMetrics              url.Metrics
 	LoadBalancedURLsRepository event.Repository
+	// Normalizer normalizes the URLs before they are shortened, the default one if it's nil
+	Normalizer *url.Normalizer
 }
 
 func NewServer(config Config) *grpc.Server {
+	var options []url.ShortenerOption
+	if config.Normalizer != nil {
+		options = append(options, url.WithNormalizer(config.Normalizer))
+	}
+
 	grpcServer := grpc.NewServer()
 	srv := &server{
 		baseDomain:   config.BaseDomain,
-		urlShortener: url.NewSingleURLShortener(config.ShortURLRepository, clock.NewFromSystem(), config.CustomMetrics),
-		loadBalancer: url.NewLoadBalancer(config.LoadBalancedURLsRepository, clock.NewFromSystem()),
+		urlShortener: url.NewSingleURLShortener(config.ShortURLRepository, clock.NewFromSystem(), config.CustomMetrics, url.NewURLSafeHashGenerator(), options...),
+		loadBalancer: url.NewLoadBalancer(config.LoadBalancedURLsRepository, clock.NewFromSystem(), url.NewURLSafeHashGenerator(), options...),
 	}
 
 	genproto.RegisterURLShorteningServer(grpcServer, srv)
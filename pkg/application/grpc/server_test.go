@@ -8,6 +8,8 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/application/grpc"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
@@ -93,9 +95,17 @@ var _ = Describe("Server", func() {
 					balanceURLsResponse, err := client.BalanceURLs(ctx, &genproto.BalanceURLsRequest{})
 
 					Expect(err).To(MatchError(ContainSubstring("no URLs specified")))
+					Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
 					Expect(balanceURLsResponse.GetShortUrl()).To(BeEmpty())
 				})
 			})
+			Context("but one of the URLs points to a private host", func() {
+				It("returns an invalid argument error", func() {
+					_, err := client.BalanceURLs(ctx, &genproto.BalanceURLsRequest{Urls: []string{"https://google.com", "http://127.0.0.1"}})
+
+					Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+				})
+			})
 		})
 
 		It("shortens a single URL", func() {
@@ -115,5 +125,13 @@ var _ = Describe("Server", func() {
 				Expect(response).To(BeNil())
 			})
 		})
+		When("the url is not valid", func() {
+			It("returns an invalid argument error", func() {
+				response, err := client.ShortSingleURL(ctx, &genproto.ShortSingleURLRequest{Url: "foo"})
+
+				Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+				Expect(response).To(BeNil())
+			})
+		})
 	})
 })
@@ -1,23 +1,35 @@
 package http
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/redirect"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/formatter"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/clock"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/qrcode"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/useragent"
 )
 
 type HandlerRepository struct {
 	config            Config
 	variableExtractor VariableExtractor
+	clicker           *click.Clicker
+	userAgents        click.UserAgentClassifier
+	clock             event.Clock
 }
 
 type VariableExtractor interface {
@@ -25,7 +37,8 @@ type VariableExtractor interface {
 }
 
 func (e *HandlerRepository) shortener() http.HandlerFunc {
-	urlShortener := url.NewSingleURLShortener(e.config.ShortURLRepository, clock.NewFromSystem(), e.config.CustomMetrics)
+	urlShortener := url.NewSingleURLShortener(e.config.ShortURLRepository, clock.NewFromSystem(), e.config.CustomMetrics, url.NewURLSafeHashGenerator(), shortenerOptions(e.config)...)
+	qrGenerator := qrcode.NewGenerator()
 
 	return func(writer http.ResponseWriter, request *http.Request) {
 		var dataIn shortURLDataIn
@@ -39,7 +52,18 @@ func (e *HandlerRepository) shortener() http.HandlerFunc {
 			return
 		}
 
-		shortURL, err := urlShortener.HashFromURL(request.Context(), dataIn.URL)
+		shortURL, err := urlShortener.HashFromURLWithOptions(request.Context(), dataIn.URL, url.ShortURLOptions{
+			Alias:      dataIn.Alias,
+			Expiration: dataIn.expiration(),
+		})
+		if errors.Is(err, url.ErrInvalidAlias) || errors.Is(err, url.ErrInvalidExpiration) {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, url.ErrAliasAlreadyInUse) {
+			http.Error(writer, err.Error(), http.StatusConflict)
+			return
+		}
 		if errors.Is(err, url.ErrInvalidLongURLSpecified) {
 			log.Print(err.Error())
 			http.Error(writer, err.Error(), http.StatusBadRequest)
@@ -59,6 +83,14 @@ func (e *HandlerRepository) shortener() http.HandlerFunc {
 		dataOut := shortURLDataOut{
 			URL: fmt.Sprintf("%s/r/%s", e.baseDomain(), shortURL.Hash),
 		}
+		if dataIn.QRCode {
+			dataOut.QRCode, err = qrCodeDataURI(qrGenerator, dataOut.URL)
+			if err != nil {
+				http.Error(writer, "internal server error", http.StatusInternalServerError)
+				log.Printf("error generating the qr code: %s", err)
+				return
+			}
+		}
 		err = json.NewEncoder(writer).Encode(&dataOut)
 		if err != nil {
 			writer.WriteHeader(http.StatusInternalServerError)
@@ -69,7 +101,7 @@ func (e *HandlerRepository) shortener() http.HandlerFunc {
 }
 
 func (e *HandlerRepository) loadBalancingURLCreator() http.HandlerFunc {
-	loadBalancerCreator := url.NewLoadBalancer(e.config.LoadBalancedURLsRepository, clock.NewFromSystem())
+	loadBalancerCreator := url.NewLoadBalancer(e.config.LoadBalancedURLsRepository, clock.NewFromSystem(), url.NewURLSafeHashGenerator(), shortenerOptions(e.config)...)
 
 	return func(writer http.ResponseWriter, request *http.Request) {
 		var dataIn loadBalancerURLDataIn
@@ -79,7 +111,11 @@ func (e *HandlerRepository) loadBalancingURLCreator() http.HandlerFunc {
 			return
 		}
 
-		shortURL, err := loadBalancerCreator.ShortURLs(request.Context(), dataIn.URLs)
+		shortURL, err := loadBalancerCreator.ShortURLsWithOptions(request.Context(), dataIn.URLs, url.LoadBalancedURLOptions{
+			Expiration: dataIn.expiration(),
+			Strategy:   url.Strategy(dataIn.Strategy),
+			Weights:    dataIn.Weights,
+		})
 		if errors.Is(err, url.ErrNoURLsSpecified) {
 			log.Print(err.Error())
 			http.Error(writer, err.Error(), http.StatusBadRequest)
@@ -90,6 +126,11 @@ func (e *HandlerRepository) loadBalancingURLCreator() http.HandlerFunc {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		if errors.Is(err, url.ErrInvalidLongURLSpecified) || errors.Is(err, url.ErrInvalidExpiration) || errors.Is(err, url.ErrInvalidStrategy) ||
+			errors.Is(err, url.ErrDuplicatedTarget) {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			http.Error(writer, "internal server error", http.StatusInternalServerError)
 			log.Printf("error retrieving hash from long URL: %s", err)
@@ -109,45 +150,524 @@ func (e *HandlerRepository) loadBalancingURLCreator() http.HandlerFunc {
 }
 
 func (e *HandlerRepository) redirector() http.HandlerFunc {
-	redirector := redirect.NewRedirector(e.config.ShortURLRepository, clock.NewFromSystem())
+	var options []redirect.RedirectorOption
+	if e.config.ExcludeBotClicks {
+		options = append(options, redirect.WithoutBotClicks(e.userAgents))
+	}
+	if recorder, ok := e.config.CustomMetrics.(redirect.LostClickRecorder); ok {
+		options = append(options, redirect.WithLostClickRecorder(recorder))
+	}
+	redirector := redirect.NewRedirector(e.config.ShortURLRepository, clock.NewFromSystem(), options...)
 
 	return func(writer http.ResponseWriter, request *http.Request) {
 		shortURLHash := e.variableExtractor.Extract(request, "hash")
 
-		originalURL, err := redirector.ReturnOriginalURL(request.Context(), shortURLHash)
+		originalURL, err := redirector.ReturnOriginalURL(request.Context(), shortURLHash, request.UserAgent())
 		if errors.Is(err, url.ErrShortURLNotFound) {
 			writer.WriteHeader(http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, url.ErrShortURLExpired) {
+			http.Error(writer, err.Error(), http.StatusGone)
+			return
+		}
+		if errors.Is(err, url.ErrShortURLDisabled) {
+			http.Error(writer, err.Error(), http.StatusForbidden)
+			return
+		}
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		http.Redirect(writer, request, originalURL, http.StatusPermanentRedirect)
+		e.logClick(request, shortURLHash)
+		// the redirect is not cached, as the short URL can change, be disabled or expire, and every visit is a click
+		writer.Header().Set("Cache-Control", "no-store")
+		http.Redirect(writer, request, originalURL, http.StatusTemporaryRedirect)
 	}
 }
 
 func (e *HandlerRepository) loadBalancingRedirector() http.HandlerFunc {
-	redirector := redirect.NewLoadBalancerRedirectorService(e.config.LoadBalancedURLsRepository)
+	redirector := redirect.NewLoadBalancerRedirectorService(e.config.LoadBalancedURLsRepository, clock.NewFromSystem(), redirect.NewStrategies(e.config.LoadBalancerCounters))
 
 	return func(writer http.ResponseWriter, request *http.Request) {
 		hash := e.variableExtractor.Extract(request, "hash")
 
-		originalURL, err := redirector.ReturnAValidOriginalURL(request.Context(), hash)
+		originalURL, err := redirector.ReturnAValidOriginalURL(request.Context(), hash, redirect.Visitor{IP: remoteIP(request)})
 		if errors.Is(err, url.ErrValidURLNotFound) {
 			http.Error(writer, err.Error(), http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, url.ErrShortURLExpired) {
+			http.Error(writer, err.Error(), http.StatusGone)
+			return
+		}
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		e.logClick(request, hash)
+		writer.Header().Set("Cache-Control", "no-store")
 		http.Redirect(writer, request, originalURL, http.StatusTemporaryRedirect)
 	}
 }
 
+// qrCodeMaxAge is how long the clients can cache a QR code, unless the link expires before
+const qrCodeMaxAge = 24 * time.Hour
+
+func (e *HandlerRepository) shortURLQRCode() http.HandlerFunc {
+	generator := qrcode.NewGenerator()
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		hash := e.variableExtractor.Extract(request, "hash")
+
+		entity, _, err := e.config.ShortURLRepository.Load(request.Context(), hash)
+		if errors.Is(err, event.ErrEntityNotFound) {
+			http.Error(writer, url.ErrShortURLNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(writer, "internal server error", http.StatusInternalServerError)
+			log.Printf("error loading the short URL: %s", err)
+			return
+		}
+		shortURL, ok := entity.(*url.ShortURL)
+		if !ok || shortURL.Deleted {
+			http.Error(writer, url.ErrShortURLNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if shortURL.IsExpired(e.clock.Now()) {
+			http.Error(writer, url.ErrShortURLExpired.Error(), http.StatusGone)
+			return
+		}
+
+		e.writeQRCode(writer, request, generator, fmt.Sprintf("%s/r/%s", e.baseDomain(), hash), shortURL.ExpiresAt)
+	}
+}
+
+func (e *HandlerRepository) loadBalancedURLQRCode() http.HandlerFunc {
+	generator := qrcode.NewGenerator()
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		hash := e.variableExtractor.Extract(request, "hash")
+
+		entity, _, err := e.config.LoadBalancedURLsRepository.Load(request.Context(), hash)
+		if errors.Is(err, event.ErrEntityNotFound) {
+			http.Error(writer, url.ErrValidURLNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(writer, "internal server error", http.StatusInternalServerError)
+			log.Printf("error loading the load balanced URL: %s", err)
+			return
+		}
+		loadBalancedURL, ok := entity.(*url.LoadBalancedURL)
+		if !ok {
+			http.Error(writer, url.ErrValidURLNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if loadBalancedURL.IsExpired(e.clock.Now()) {
+			http.Error(writer, url.ErrShortURLExpired.Error(), http.StatusGone)
+			return
+		}
+
+		e.writeQRCode(writer, request, generator, fmt.Sprintf("%s/lb/%s", e.baseDomain(), hash), loadBalancedURL.ExpiresAt)
+	}
+}
+
+// writeQRCode renders the link with the 'format', 'size' and 'level' parameters of the request.
+// The image only depends on them, so it's cached by its ETag until the link expires, at most for qrCodeMaxAge.
+func (e *HandlerRepository) writeQRCode(writer http.ResponseWriter, request *http.Request, generator *qrcode.Generator, link string, expiresAt time.Time) {
+	options, err := qrCodeOptions(request)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	maxAge := qrCodeMaxAge
+	if !expiresAt.IsZero() && expiresAt.Sub(e.clock.Now()) < maxAge {
+		maxAge = expiresAt.Sub(e.clock.Now())
+	}
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s", link, options.Format, options.Size, options.Level))))
+	writer.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	writer.Header().Set("ETag", etag)
+	if request.Header.Get("If-None-Match") == etag {
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	image, err := generator.Generate(link, options)
+	if errors.Is(err, qrcode.ErrInvalidOptions) {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(writer, "internal server error", http.StatusInternalServerError)
+		log.Printf("error generating the qr code: %s", err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", image.ContentType)
+	writer.Header().Set("Content-Length", strconv.Itoa(len(image.Data)))
+	_, err = writer.Write(image.Data)
+	if err != nil {
+		log.Printf("error writing the qr code: %s", err)
+	}
+}
+
+// qrCodeOptions reads the 'format' (png or svg), 'size' (in pixels) and 'level' (L, M, Q or H) parameters,
+// which are validated when the QR code is generated
+func qrCodeOptions(request *http.Request) (qrcode.Options, error) {
+	parameters := request.URL.Query()
+	options := qrcode.DefaultOptions()
+
+	if format := parameters.Get("format"); format != "" {
+		options.Format = qrcode.Format(strings.ToLower(format))
+	}
+	if level := parameters.Get("level"); level != "" {
+		options.Level = qrcode.Level(strings.ToUpper(level))
+	}
+	if size := parameters.Get("size"); size != "" {
+		parsed, err := strconv.Atoi(size)
+		if err != nil {
+			return qrcode.Options{}, fmt.Errorf("invalid 'size' parameter: %w", err)
+		}
+		options.Size = parsed
+	}
+	return options, nil
+}
+
+// qrCodeDataURI renders the link as a QR code with the default options, to be embedded in a response
+func qrCodeDataURI(generator *qrcode.Generator, link string) (string, error) {
+	image, err := generator.Generate(link, qrcode.DefaultOptions())
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data:%s;base64,%s", image.ContentType, base64.StdEncoding.EncodeToString(image.Data)), nil
+}
+
+func (e *HandlerRepository) linkStatus() http.HandlerFunc {
+	manager := url.NewShortURLManager(e.config.ShortURLRepository, clock.NewFromSystem(), shortenerOptions(e.config)...)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		shortURL, err := manager.Find(request.Context(), e.variableExtractor.Extract(request, "hash"))
+		if err != nil {
+			writeLinkManagementError(writer, err)
+			return
+		}
+
+		dataOut := linkStatusDataOut{
+			linkDataOut: e.linkDataOutFrom(shortURL),
+			Status:      string(shortURL.ValidationStatus()),
+			CreatedAt:   shortURL.CreatedAt,
+		}
+		if shortURL.Rejection != nil {
+			dataOut.Validator = shortURL.Rejection.Validator
+			dataOut.Reason = shortURL.Rejection.Reason
+		}
+		if !shortURL.ValidatedAt.IsZero() {
+			dataOut.ValidatedAt = &shortURL.ValidatedAt
+		}
+		for _, verdict := range shortURL.Verdicts {
+			dataOut.Verdicts = append(dataOut.Verdicts, verdictDataOut{
+				Validator:  verdict.Validator,
+				Result:     string(verdict.Result),
+				Reason:     verdict.Reason,
+				FinalURL:   verdict.FinalURL,
+				StatusCode: verdict.StatusCode,
+				LatencyMs:  verdict.Latency.Milliseconds(),
+			})
+		}
+		err = json.NewEncoder(writer).Encode(&dataOut)
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			log.Printf("error marshaling the response: %s", err)
+			return
+		}
+	}
+}
+
+func (e *HandlerRepository) linkDisabler() http.HandlerFunc {
+	manager := url.NewShortURLManager(e.config.ShortURLRepository, clock.NewFromSystem(), shortenerOptions(e.config)...)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		shortURL, err := manager.Disable(request.Context(), e.variableExtractor.Extract(request, "hash"))
+		if err != nil {
+			writeLinkManagementError(writer, err)
+			return
+		}
+		e.writeLink(writer, shortURL)
+	}
+}
+
+func (e *HandlerRepository) linkEnabler() http.HandlerFunc {
+	manager := url.NewShortURLManager(e.config.ShortURLRepository, clock.NewFromSystem(), shortenerOptions(e.config)...)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		shortURL, err := manager.Enable(request.Context(), e.variableExtractor.Extract(request, "hash"))
+		if err != nil {
+			writeLinkManagementError(writer, err)
+			return
+		}
+		e.writeLink(writer, shortURL)
+	}
+}
+
+func (e *HandlerRepository) linkTargetChanger() http.HandlerFunc {
+	manager := url.NewShortURLManager(e.config.ShortURLRepository, clock.NewFromSystem(), shortenerOptions(e.config)...)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var dataIn linkTargetDataIn
+		err := json.NewDecoder(request.Body).Decode(&dataIn)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		shortURL, err := manager.ChangeTarget(request.Context(), e.variableExtractor.Extract(request, "hash"), dataIn.URL)
+		if err != nil {
+			writeLinkManagementError(writer, err)
+			return
+		}
+		e.writeLink(writer, shortURL)
+	}
+}
+
+func (e *HandlerRepository) linkDeleter() http.HandlerFunc {
+	manager := url.NewShortURLManager(e.config.ShortURLRepository, clock.NewFromSystem(), shortenerOptions(e.config)...)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		err := manager.Delete(request.Context(), e.variableExtractor.Extract(request, "hash"))
+		if err != nil {
+			writeLinkManagementError(writer, err)
+			return
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (e *HandlerRepository) loadBalancerTargetAdder() http.HandlerFunc {
+	manager := url.NewLoadBalancedURLManager(e.config.LoadBalancedURLsRepository, clock.NewFromSystem(), shortenerOptions(e.config)...)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var dataIn loadBalancerTargetDataIn
+		err := json.NewDecoder(request.Body).Decode(&dataIn)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		loadBalancedURL, err := manager.AddTarget(request.Context(), e.variableExtractor.Extract(request, "hash"), dataIn.URL, dataIn.Weight)
+		if err != nil {
+			writeLoadBalancerManagementError(writer, err)
+			return
+		}
+		e.writeLoadBalancedLink(writer, loadBalancedURL)
+	}
+}
+
+func (e *HandlerRepository) loadBalancerTargetsReplacer() http.HandlerFunc {
+	manager := url.NewLoadBalancedURLManager(e.config.LoadBalancedURLsRepository, clock.NewFromSystem(), shortenerOptions(e.config)...)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var dataIn loadBalancerTargetsDataIn
+		err := json.NewDecoder(request.Body).Decode(&dataIn)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		loadBalancedURL, err := manager.ReplaceTargets(request.Context(), e.variableExtractor.Extract(request, "hash"), dataIn.URLs, dataIn.Weights)
+		if err != nil {
+			writeLoadBalancerManagementError(writer, err)
+			return
+		}
+		e.writeLoadBalancedLink(writer, loadBalancedURL)
+	}
+}
+
+// loadBalancerTargetRemover removes the target given in the 'url' parameter
+func (e *HandlerRepository) loadBalancerTargetRemover() http.HandlerFunc {
+	manager := url.NewLoadBalancedURLManager(e.config.LoadBalancedURLsRepository, clock.NewFromSystem(), shortenerOptions(e.config)...)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		loadBalancedURL, err := manager.RemoveTarget(request.Context(), e.variableExtractor.Extract(request, "hash"), request.URL.Query().Get("url"))
+		if err != nil {
+			writeLoadBalancerManagementError(writer, err)
+			return
+		}
+		e.writeLoadBalancedLink(writer, loadBalancedURL)
+	}
+}
+
+// defaultStatsRange is the range of the stats when its start is not specified
+const defaultStatsRange = 7 * 24 * time.Hour
+
+func (e *HandlerRepository) linkStats() http.HandlerFunc {
+	analytics := click.NewAnalytics(e.config.ClickerRepository, e.config.ShortURLRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		query, err := e.statsQuery(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		stats, err := analytics.Stats(request.Context(), query)
+		if errors.Is(err, click.ErrInvalidStatsQuery) {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, url.ErrShortURLNotFound) {
+			http.Error(writer, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(writer, "internal server error", http.StatusInternalServerError)
+			log.Printf("error retrieving the stats of the short URL: %s", err)
+			return
+		}
+
+		err = json.NewEncoder(writer).Encode(newStatsDataOut(query, stats))
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			log.Printf("error marshaling the response: %s", err)
+			return
+		}
+	}
+}
+
+// statsQuery reads the range from the RFC 3339 'from' and 'to' parameters, and the 'granularity' parameter.
+// By default, it's the last week grouped by day. The 'exclude_bots' parameter overrides whether the
+// clicks of bots are left out, which by default depends on the configuration.
+func (e *HandlerRepository) statsQuery(request *http.Request) (click.StatsQuery, error) {
+	parameters := request.URL.Query()
+	query := click.StatsQuery{
+		Hash:        e.variableExtractor.Extract(request, "hash"),
+		To:          e.clock.Now().UTC(),
+		Granularity: click.Day,
+		ExcludeBots: e.config.ExcludeBotClicks,
+	}
+
+	if to := parameters.Get("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return click.StatsQuery{}, fmt.Errorf("invalid 'to' parameter: %w", err)
+		}
+		query.To = parsed
+	}
+	query.From = query.To.Add(-defaultStatsRange)
+	if from := parameters.Get("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return click.StatsQuery{}, fmt.Errorf("invalid 'from' parameter: %w", err)
+		}
+		query.From = parsed
+	}
+	if granularity := parameters.Get("granularity"); granularity != "" {
+		query.Granularity = click.Granularity(granularity)
+	}
+	if excludeBots := parameters.Get("exclude_bots"); excludeBots != "" {
+		parsed, err := strconv.ParseBool(excludeBots)
+		if err != nil {
+			return click.StatsQuery{}, fmt.Errorf("invalid 'exclude_bots' parameter: %w", err)
+		}
+		query.ExcludeBots = parsed
+	}
+	return query, nil
+}
+
+func writeLinkManagementError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, url.ErrShortURLNotFound):
+		http.Error(writer, err.Error(), http.StatusNotFound)
+	case errors.Is(err, url.ErrInvalidLongURLSpecified):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(writer, "internal server error", http.StatusInternalServerError)
+		log.Printf("error updating the short URL: %s", err)
+	}
+}
+
+func (e *HandlerRepository) writeLink(writer http.ResponseWriter, shortURL *url.ShortURL) {
+	dataOut := e.linkDataOutFrom(shortURL)
+	err := json.NewEncoder(writer).Encode(&dataOut)
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		log.Printf("error marshaling the response: %s", err)
+		return
+	}
+}
+
+func (e *HandlerRepository) linkDataOutFrom(shortURL *url.ShortURL) linkDataOut {
+	return linkDataOut{
+		URL:         fmt.Sprintf("%s/r/%s", e.baseDomain(), shortURL.Hash),
+		OriginalURL: shortURL.OriginalURL.URL,
+		IsValid:     shortURL.OriginalURL.IsValid,
+		Disabled:    shortURL.Disabled,
+	}
+}
+
+func writeLoadBalancerManagementError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, url.ErrLoadBalancedURLNotFound), errors.Is(err, url.ErrTargetNotFound):
+		http.Error(writer, err.Error(), http.StatusNotFound)
+	case errors.Is(err, url.ErrInvalidLongURLSpecified), errors.Is(err, url.ErrNoURLsSpecified),
+		errors.Is(err, url.ErrTooMuchMultipleURLs), errors.Is(err, url.ErrInvalidStrategy),
+		errors.Is(err, url.ErrDuplicatedTarget):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(writer, "internal server error", http.StatusInternalServerError)
+		log.Printf("error updating the load balanced URL: %s", err)
+	}
+}
+
+func (e *HandlerRepository) writeLoadBalancedLink(writer http.ResponseWriter, loadBalancedURL *url.LoadBalancedURL) {
+	dataOut := loadBalancerLinkDataOut{
+		URL:      fmt.Sprintf("%s/lb/%s", e.baseDomain(), loadBalancedURL.Hash),
+		Strategy: string(loadBalancedURL.Strategy.OrDefault()),
+		Targets:  []loadBalancerTargetDataOut{},
+	}
+	for _, target := range loadBalancedURL.LongURLs {
+		dataOut.Targets = append(dataOut.Targets, loadBalancerTargetDataOut{
+			URL:     target.URL,
+			IsValid: target.IsValid,
+			Weight:  target.Weight,
+		})
+	}
+	err := json.NewEncoder(writer).Encode(&dataOut)
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		log.Printf("error marshaling the response: %s", err)
+		return
+	}
+}
+
+// logClick records the details of the request redirected by the hash. The redirection is performed
+// even if they can't be recorded.
+func (e *HandlerRepository) logClick(request *http.Request, hash string) {
+	err := e.clicker.LogClick(request.Context(), &click.Details{
+		Hash:           hash,
+		IP:             remoteIP(request),
+		UserAgent:      request.UserAgent(),
+		Referer:        request.Referer(),
+		AcceptLanguage: request.Header.Get("Accept-Language"),
+		ClickedAt:      e.clock.Now(),
+	})
+	if err != nil {
+		log.Printf("error logging click: %s", err)
+	}
+}
+
+func remoteIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
+
 func (e *HandlerRepository) notFound() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		http.NotFound(writer, request)
@@ -155,7 +675,7 @@ func (e *HandlerRepository) notFound() http.HandlerFunc {
 }
 
 func (e *HandlerRepository) csvShortener() http.HandlerFunc {
-	csvShortener := url.NewFileURLShortener(e.config.ShortURLRepository, e.config.CustomMetrics, clock.NewFromSystem(), formatter.NewCSV())
+	csvShortener := url.NewFileURLShortener(e.config.ShortURLRepository, e.config.CustomMetrics, clock.NewFromSystem(), formatter.NewCSV(), url.NewURLSafeHashGenerator(), shortenerOptions(e.config)...)
 
 	return func(writer http.ResponseWriter, request *http.Request) {
 		data := []byte(request.FormValue("file"))
@@ -197,8 +717,31 @@ func (e *HandlerRepository) baseDomain() string {
 }
 
 func NewHandlerRepository(config Config, variableExtractor VariableExtractor) *HandlerRepository {
+	userAgents := useragent.NewClassifier()
 	return &HandlerRepository{
 		config:            config,
 		variableExtractor: variableExtractor,
+		clicker:           click.NewClicker(config.ClickerRepository, clickEnrichers(config, userAgents)...),
+		userAgents:        userAgents,
+		clock:             clock.NewFromSystem(),
+	}
+}
+
+func clickEnrichers(config Config, userAgents click.UserAgentClassifier) []click.Enricher {
+	enrichers := []click.Enricher{click.NewUserAgentEnricher(userAgents)}
+	if config.Geolocator != nil {
+		enrichers = append(enrichers, click.NewGeolocationEnricher(config.Geolocator))
+	}
+	// the anonymization goes last, so the IP is located before being truncated
+	if config.AnonymizeIPs {
+		enrichers = append(enrichers, click.NewIPAnonymizer())
+	}
+	return enrichers
+}
+
+func shortenerOptions(config Config) []url.ShortenerOption {
+	if config.Normalizer == nil {
+		return nil
 	}
+	return []url.ShortenerOption{url.WithNormalizer(config.Normalizer)}
 }
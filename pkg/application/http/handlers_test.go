@@ -3,10 +3,14 @@ package http_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image/png"
 	"io"
 	"log"
 	"math/rand"
 	gohttp "net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,9 +19,12 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/application/http"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	clickmocks "github.com/WebEngineeringGroupI/backend/pkg/domain/click/mocks"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
 	urlmocks "github.com/WebEngineeringGroupI/backend/pkg/domain/url/mocks"
+	databaseinmemory "github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/inmemory"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/eventstore/inmemory"
 )
 
@@ -28,6 +35,7 @@ var _ = Describe("Application / HTTP", func() {
 		r                          *testingRouter
 		shortURLRepository         event.Repository
 		loadBalancerURLsRepository event.Repository
+		clickerRepository          *databaseinmemory.ClickerRepository
 		ctx                        context.Context
 	)
 	BeforeEach(func() {
@@ -40,11 +48,14 @@ var _ = Describe("Application / HTTP", func() {
 
 		shortURLRepository = event.NewRepository(&url.ShortURL{}, inmemory.NewEventStore(), event.NewBroker())
 		loadBalancerURLsRepository = event.NewRepository(&url.LoadBalancedURL{}, inmemory.NewEventStore(), event.NewBroker())
+		clickerRepository = databaseinmemory.NewClickerRepository()
 		r = newTestingRouter(http.Config{
 			BaseDomain:                 "http://example.com",
 			ShortURLRepository:         shortURLRepository,
 			LoadBalancedURLsRepository: loadBalancerURLsRepository,
+			LoadBalancerCounters:       databaseinmemory.NewCounterStore(),
 			CustomMetrics:              metrics,
+			ClickerRepository:          clickerRepository,
 		})
 
 		metrics.EXPECT().RecordFileURLMetrics().AnyTimes()
@@ -69,6 +80,97 @@ var _ = Describe("Application / HTTP", func() {
 			Expect(shortURL.OriginalURL.URL).To(Equal("https://google.es"))
 		})
 
+		Context("with a URL that is not normalized", func() {
+			It("returns the short URL of the normalized URL", func() {
+				r = newTestingRouter(http.Config{
+					BaseDomain:                 "http://example.com",
+					ShortURLRepository:         shortURLRepository,
+					LoadBalancedURLsRepository: loadBalancerURLsRepository,
+					CustomMetrics:              metrics,
+					ClickerRepository:          clickerRepository,
+					Normalizer:                 url.NewNormalizer(url.WithoutTrackingParameters()),
+				})
+
+				response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "HTTPS://Google.es:443/?utm_source=newsletter"}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(MatchJSON(longURLResponse())))
+			})
+		})
+
+		Context("with a custom alias", func() {
+			It("returns the short URL with the alias", func() {
+				response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "https://google.es", "alias": "launch-2026"}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(MatchJSON(`{"url": "http://example.com/r/launch-2026"}`)))
+
+				entity, _, err := shortURLRepository.Load(ctx, "launch-2026")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(entity.(*url.ShortURL).OriginalURL.URL).To(Equal("https://google.es"))
+			})
+
+			Context("but the alias is not valid", func() {
+				It("returns StatusBadRequest code", func() {
+					response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "https://google.es", "alias": "api"}`))
+
+					Expect(response.StatusCode).To(Equal(gohttp.StatusBadRequest))
+					Expect(response).To(HaveHTTPBody(ContainSubstring("invalid alias")))
+				})
+			})
+
+			Context("but the alias is already used by another URL", func() {
+				It("returns StatusConflict code", func() {
+					r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "https://google.es", "alias": "launch-2026"}`))
+
+					response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "https://youtube.com", "alias": "launch-2026"}`))
+
+					Expect(response.StatusCode).To(Equal(gohttp.StatusConflict))
+					Expect(response).To(HaveHTTPBody(ContainSubstring("alias already in use")))
+				})
+			})
+		})
+
+		Context("with an expiration", func() {
+			It("returns the short URL that expires after the TTL", func() {
+				response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "https://google.es", "alias": "launch-2026", "ttl": 3600}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(MatchJSON(`{"url": "http://example.com/r/launch-2026"}`)))
+
+				entity, _, err := shortURLRepository.Load(ctx, "launch-2026")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(entity.(*url.ShortURL).ExpiresAt).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+			})
+
+			Context("but the expiration is in the past", func() {
+				It("returns StatusBadRequest code", func() {
+					response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "https://google.es", "expires_at": "2020-01-01T00:00:00Z"}`))
+
+					Expect(response.StatusCode).To(Equal(gohttp.StatusBadRequest))
+					Expect(response).To(HaveHTTPBody(ContainSubstring("invalid expiration")))
+				})
+			})
+		})
+
+		Context("but the URL is not valid", func() {
+			It("returns StatusBadRequest code", func() {
+				response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "foo"}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusBadRequest))
+				Expect(response).To(HaveHTTPBody(ContainSubstring("unsupported_scheme")))
+			})
+		})
+
+		Context("but the URL points to a private host", func() {
+			It("returns StatusBadRequest code", func() {
+				response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "http://127.0.0.1/admin"}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusBadRequest))
+				Expect(response).To(HaveHTTPBody(ContainSubstring("private_host")))
+			})
+		})
+
 		Context("but the JSON is malformed", func() {
 			It("returns StatusBadRequest code", func() {
 				response := r.doPOSTRequest("/api/v1/link", badURLRequestWithMalformedJSON())
@@ -106,6 +208,74 @@ var _ = Describe("Application / HTTP", func() {
 			})
 		})
 
+		Context("but one of the URLs is not valid", func() {
+			It("returns StatusBadRequest code", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer", strings.NewReader(`{"urls": ["https://google.es", "ftp://example.com"]}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusBadRequest))
+				Expect(response).To(HaveHTTPBody(ContainSubstring("unsupported_scheme")))
+			})
+		})
+
+		Context("with an invalid expiration", func() {
+			It("returns StatusBadRequest code", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer", strings.NewReader(`{"urls": ["https://google.es"], "ttl": -1}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusBadRequest))
+				Expect(response).To(HaveHTTPBody(ContainSubstring("invalid expiration")))
+			})
+		})
+
+		Context("with a weighted strategy", func() {
+			It("stores the strategy and the weight of each URL", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer", strings.NewReader(`{
+	"urls": ["https://google.es", "https://youtube.com"],
+	"strategy": "weighted",
+	"weights": [3, 1]
+}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusOK))
+				hash := loadBalancedURLHashFrom(response)
+				Expect(hash).ToNot(Equal("5XEOqhb0"))
+
+				entity, _, err := loadBalancerURLsRepository.Load(ctx, hash)
+				Expect(err).ToNot(HaveOccurred())
+				loadBalancedURL := entity.(*url.LoadBalancedURL)
+				Expect(loadBalancedURL.Strategy).To(Equal(url.WeightedStrategy))
+				Expect(loadBalancedURL.LongURLs).To(ConsistOf(
+					url.OriginalURL{URL: "https://google.es", Weight: 3},
+					url.OriginalURL{URL: "https://youtube.com", Weight: 1},
+				))
+			})
+		})
+
+		Context("with an invalid strategy", func() {
+			It("returns StatusBadRequest code", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer", strings.NewReader(`{"urls": ["https://google.es"], "strategy": "fastest"}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusBadRequest))
+				Expect(response).To(HaveHTTPBody(ContainSubstring("invalid load balancing strategy")))
+			})
+		})
+
+		Context("with the same URL twice once normalized", func() {
+			It("returns StatusBadRequest code", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer", strings.NewReader(`{"urls": ["https://google.es", "HTTPS://Google.es/"]}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusBadRequest))
+				Expect(response).To(HaveHTTPBody(ContainSubstring("duplicated target")))
+			})
+		})
+
+		Context("with weights that don't match the URLs", func() {
+			It("returns StatusBadRequest code", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer", strings.NewReader(`{"urls": ["https://google.es"], "strategy": "weighted", "weights": [1, 2]}`))
+
+				Expect(response.StatusCode).To(Equal(gohttp.StatusBadRequest))
+				Expect(response).To(HaveHTTPBody(ContainSubstring("invalid load balancing strategy")))
+			})
+		})
+
 		Context("but the list of URLs is empty", func() {
 			It("returns StatusBadRequest code", func() {
 				response := r.doPOSTRequest("/api/v1/loadbalancer", badLoadBalancerEmptyURLList())
@@ -122,7 +292,7 @@ var _ = Describe("Application / HTTP", func() {
 				// create short url
 				r.doPOSTRequest("/api/v1/link", longURLRequest())
 				// and verify it TODO(fede): This should be verified through domain event from the message broker
-				err := shortURLRepository.Save(ctx, &url.ShortURLVerified{
+				err := shortURLRepository.Save(ctx, 0, &url.ShortURLVerified{
 					Base: event.Base{
 						ID:      "lxqrJ9xF",
 						Version: 1,
@@ -135,8 +305,9 @@ var _ = Describe("Application / HTTP", func() {
 				response := r.doGETRequest("/r/lxqrJ9xF")
 
 				// and the redirection is performed
-				Expect(response.StatusCode).To(Equal(gohttp.StatusPermanentRedirect))
+				Expect(response.StatusCode).To(Equal(gohttp.StatusTemporaryRedirect))
 				Expect(response.Header.Get("Location")).To(Equal("https://google.es"))
+				Expect(response.Header.Get("Cache-Control")).To(Equal("no-store"))
 
 				// the entity saved in the Event Sourcing repository should have 1 click, with entity version 2
 				entity, version, err := shortURLRepository.Load(ctx, "lxqrJ9xF")
@@ -149,6 +320,150 @@ var _ = Describe("Application / HTTP", func() {
 			})
 		})
 
+		Context("and the request has details of the client", func() {
+			It("logs the details of the click", func() {
+				r.doPOSTRequest("/api/v1/link", longURLRequest())
+				err := shortURLRepository.Save(ctx, 0, &url.ShortURLVerified{
+					Base: event.Base{ID: "lxqrJ9xF", Version: 1, At: time.Now()},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				response := r.doGETRequestFrom("/r/lxqrJ9xF", "[2001:db8::1]:52000", gohttp.Header{
+					"User-Agent":      {"Mozilla/5.0"},
+					"Referer":         {"https://twitter.com"},
+					"Accept-Language": {"es-ES,es;q=0.9"},
+				})
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusTemporaryRedirect))
+				clicks, err := clickerRepository.FindClicksByHash(ctx, "lxqrJ9xF")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(clicks).To(HaveLen(1))
+				Expect(clicks[0].IP).To(Equal("2001:db8::1"))
+				Expect(clicks[0].UserAgent).To(Equal("Mozilla/5.0"))
+				Expect(clicks[0].Referer).To(Equal("https://twitter.com"))
+				Expect(clicks[0].AcceptLanguage).To(Equal("es-ES,es;q=0.9"))
+				Expect(clicks[0].ClickedAt).To(BeTemporally("~", time.Now(), time.Minute))
+			})
+
+			It("logs the classification of the user agent", func() {
+				r.doPOSTRequest("/api/v1/link", longURLRequest())
+				err := shortURLRepository.Save(ctx, 0, &url.ShortURLVerified{
+					Base: event.Base{ID: "lxqrJ9xF", Version: 1, At: time.Now()},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				r.doGETRequestFrom("/r/lxqrJ9xF", "192.168.1.1:52000", gohttp.Header{
+					"User-Agent": {"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:94.0) Gecko/20100101 Firefox/94.0"},
+				})
+				r.doGETRequestFrom("/r/lxqrJ9xF", "192.168.1.2:52000", gohttp.Header{
+					"User-Agent": {"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"},
+				})
+
+				clicks, err := clickerRepository.FindClicksByHash(ctx, "lxqrJ9xF")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(clicks).To(HaveLen(2))
+				Expect(clicks[0].Browser).To(Equal("Firefox"))
+				Expect(clicks[0].OS).To(Equal("Linux"))
+				Expect(clicks[0].Device).To(Equal(click.Desktop))
+				Expect(clicks[0].IsBot).To(BeFalse())
+				Expect(clicks[1].Device).To(Equal(click.BotDevice))
+				Expect(clicks[1].IsBot).To(BeTrue())
+			})
+		})
+
+		Context("and the clicks are located and anonymized", func() {
+			var geolocator *clickmocks.MockGeolocator
+
+			BeforeEach(func() {
+				geolocator = clickmocks.NewMockGeolocator(ctrl)
+				r = newTestingRouter(http.Config{
+					BaseDomain:                 "http://example.com",
+					ShortURLRepository:         shortURLRepository,
+					LoadBalancedURLsRepository: loadBalancerURLsRepository,
+					LoadBalancerCounters:       databaseinmemory.NewCounterStore(),
+					CustomMetrics:              metrics,
+					ClickerRepository:          clickerRepository,
+					Geolocator:                 geolocator,
+					AnonymizeIPs:               true,
+				})
+			})
+
+			It("logs the location of the full IP but only the anonymized IP", func() {
+				r.doPOSTRequest("/api/v1/link", longURLRequest())
+				err := shortURLRepository.Save(ctx, 0, &url.ShortURLVerified{
+					Base: event.Base{ID: "lxqrJ9xF", Version: 1, At: time.Now()},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				geolocator.EXPECT().Locate("192.0.2.10").Return(click.Geolocation{Country: "ES", City: "Zaragoza"}, nil)
+
+				response := r.doGETRequestFrom("/r/lxqrJ9xF", "192.0.2.10:52000", gohttp.Header{"User-Agent": {"Mozilla/5.0"}})
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusTemporaryRedirect))
+				clicks, err := clickerRepository.FindClicksByHash(ctx, "lxqrJ9xF")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(clicks).To(HaveLen(1))
+				Expect(clicks[0].IP).To(Equal("192.0.2.0"))
+				Expect(clicks[0].Country).To(Equal("ES"))
+				Expect(clicks[0].City).To(Equal("Zaragoza"))
+			})
+		})
+
+		Context("and the clicks of bots are excluded", func() {
+			BeforeEach(func() {
+				r = newTestingRouter(http.Config{
+					BaseDomain:                 "http://example.com",
+					ShortURLRepository:         shortURLRepository,
+					LoadBalancedURLsRepository: loadBalancerURLsRepository,
+					LoadBalancerCounters:       databaseinmemory.NewCounterStore(),
+					CustomMetrics:              metrics,
+					ClickerRepository:          clickerRepository,
+					ExcludeBotClicks:           true,
+				})
+			})
+
+			It("redirects the bots without counting their clicks", func() {
+				r.doPOSTRequest("/api/v1/link", longURLRequest())
+				err := shortURLRepository.Save(ctx, 0, &url.ShortURLVerified{
+					Base: event.Base{ID: "lxqrJ9xF", Version: 1, At: time.Now()},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				response := r.doGETRequestFrom("/r/lxqrJ9xF", "192.168.1.2:52000", gohttp.Header{"User-Agent": {"Twitterbot/1.0"}})
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusTemporaryRedirect))
+				response = r.doGETRequestFrom("/r/lxqrJ9xF", "192.168.1.1:52000", gohttp.Header{"User-Agent": {"Mozilla/5.0"}})
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusTemporaryRedirect))
+
+				entity, _, err := shortURLRepository.Load(ctx, "lxqrJ9xF")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(entity.(*url.ShortURL).Clicks).To(Equal(1))
+			})
+		})
+
+		Context("but the URL is not valid", func() {
+			It("doesn't log any click", func() {
+				r.doPOSTRequest("/api/v1/link", longURLRequest())
+
+				r.doGETRequest("/r/lxqrJ9xF")
+
+				Expect(clickerRepository.FindClicksByHash(ctx, "lxqrJ9xF")).To(BeEmpty())
+			})
+		})
+
+		Context("but the URL is expired", func() {
+			It("returns a 410 error", func() {
+				r.doPOSTRequest("/api/v1/link", longURLRequest())
+				err := shortURLRepository.Save(ctx, 0,
+					&url.ShortURLVerified{Base: event.Base{ID: "lxqrJ9xF", Version: 1, At: time.Now()}},
+					&url.ShortURLExpired{Base: event.Base{ID: "lxqrJ9xF", Version: 2, At: time.Now()}},
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				response := r.doGETRequest("/r/lxqrJ9xF")
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusGone))
+			})
+		})
+
 		Context("but the URL is not present in the repository", func() {
 			It("returns a 404 error", func() {
 				response := r.doGETRequest("/r/123456")
@@ -163,7 +478,7 @@ var _ = Describe("Application / HTTP", func() {
 			It("responds with a URL redirect", func() {
 				r.doPOSTRequest("/api/v1/loadbalancer", loadBalancerURLRequest())
 
-				err := loadBalancerURLsRepository.Save(ctx, &url.LoadBalancedURLVerified{
+				err := loadBalancerURLsRepository.Save(ctx, 0, &url.LoadBalancedURLVerified{
 					Base: event.Base{
 						ID:      "5XEOqhb0",
 						Version: 1,
@@ -173,17 +488,24 @@ var _ = Describe("Application / HTTP", func() {
 				})
 				Expect(err).ToNot(HaveOccurred())
 
-				response := r.doGETRequest("/lb/5XEOqhb0")
+				response := r.doGETRequestFrom("/lb/5XEOqhb0", "192.168.1.1:52000", gohttp.Header{"User-Agent": {"Mozilla/5.0"}})
 
 				Expect(response).To(HaveHTTPStatus(gohttp.StatusTemporaryRedirect))
 				Expect(response).To(HaveHTTPHeaderWithValue("Location", "https://google.es"))
+				Expect(response).To(HaveHTTPHeaderWithValue("Cache-Control", "no-store"))
+
+				clicks, err := clickerRepository.FindClicksByHash(ctx, "5XEOqhb0")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(clicks).To(HaveLen(1))
+				Expect(clicks[0].IP).To(Equal("192.168.1.1"))
+				Expect(clicks[0].UserAgent).To(Equal("Mozilla/5.0"))
 			})
 		})
 		Context("and there are multiple valid URLs", func() {
 			It("responds with a different URL each time", func() {
 				r.doPOSTRequest("/api/v1/loadbalancer", loadBalancerURLRequest())
 
-				err := loadBalancerURLsRepository.Save(ctx,
+				err := loadBalancerURLsRepository.Save(ctx, 0,
 					&url.LoadBalancedURLVerified{
 						Base: event.Base{
 							ID:      "5XEOqhb0",
@@ -195,7 +517,7 @@ var _ = Describe("Application / HTTP", func() {
 					&url.LoadBalancedURLVerified{
 						Base: event.Base{
 							ID:      "5XEOqhb0",
-							Version: 1,
+							Version: 2,
 							At:      time.Now(),
 						},
 						VerifiedURL: "https://youtube.com",
@@ -212,6 +534,46 @@ var _ = Describe("Application / HTTP", func() {
 			})
 		})
 
+		Context("and the URL balances the visitors in turns", func() {
+			It("redirects to each valid URL in turns", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer", strings.NewReader(`{
+	"urls": ["https://google.es", "https://youtube.com"],
+	"strategy": "round_robin"
+}`))
+				hash := loadBalancedURLHashFrom(response)
+				err := loadBalancerURLsRepository.Save(ctx, 0,
+					&url.LoadBalancedURLVerified{Base: event.Base{ID: hash, Version: 1, At: time.Now()}, VerifiedURL: "https://google.es"},
+					&url.LoadBalancedURLVerified{Base: event.Base{ID: hash, Version: 2, At: time.Now()}, VerifiedURL: "https://youtube.com"},
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(r.doGETRequest("/lb/" + hash)).To(HaveHTTPHeaderWithValue("Location", "https://google.es"))
+				Expect(r.doGETRequest("/lb/" + hash)).To(HaveHTTPHeaderWithValue("Location", "https://youtube.com"))
+				Expect(r.doGETRequest("/lb/" + hash)).To(HaveHTTPHeaderWithValue("Location", "https://google.es"))
+			})
+		})
+
+		Context("and the URL sticks each visitor to the same URL", func() {
+			It("redirects the same client IP to the same URL", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer", strings.NewReader(`{
+	"urls": ["https://google.es", "https://youtube.com"],
+	"strategy": "sticky"
+}`))
+				hash := loadBalancedURLHashFrom(response)
+				err := loadBalancerURLsRepository.Save(ctx, 0,
+					&url.LoadBalancedURLVerified{Base: event.Base{ID: hash, Version: 1, At: time.Now()}, VerifiedURL: "https://google.es"},
+					&url.LoadBalancedURLVerified{Base: event.Base{ID: hash, Version: 2, At: time.Now()}, VerifiedURL: "https://youtube.com"},
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				first := r.doGETRequestFrom("/lb/"+hash, "192.168.1.1:52000", nil).Header.Get("Location")
+				for i := 0; i < 10; i++ {
+					response := r.doGETRequestFrom("/lb/"+hash, "192.168.1.1:"+strconv.Itoa(52001+i), nil)
+					Expect(response).To(HaveHTTPHeaderWithValue("Location", first))
+				}
+			})
+		})
+
 		Context("but the URL does not have any original valid URL", func() {
 			It("returns a 404 error", func() {
 				r.doPOSTRequest("/api/v1/loadbalancer", loadBalancerURLRequest())
@@ -221,6 +583,20 @@ var _ = Describe("Application / HTTP", func() {
 				Expect(response).To(HaveHTTPStatus(gohttp.StatusNotFound))
 			})
 		})
+		Context("but the URL is expired", func() {
+			It("returns a 410 error", func() {
+				r.doPOSTRequest("/api/v1/loadbalancer", loadBalancerURLRequest())
+				err := loadBalancerURLsRepository.Save(ctx, 0,
+					&url.LoadBalancedURLVerified{Base: event.Base{ID: "5XEOqhb0", Version: 1, At: time.Now()}, VerifiedURL: "https://google.es"},
+					&url.LoadBalancedURLExpired{Base: event.Base{ID: "5XEOqhb0", Version: 2, At: time.Now()}},
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				response := r.doGETRequest("/lb/5XEOqhb0")
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusGone))
+			})
+		})
 		Context("but the URL is not present in the repository", func() {
 			It("returns a 404 error", func() {
 				response := r.doGETRequest("/lb/123456")
@@ -230,29 +606,439 @@ var _ = Describe("Application / HTTP", func() {
 		})
 	})
 
+	Context("when it receives an HTTP request for the status of a short URL", func() {
+		var createdAt time.Time
+
+		BeforeEach(func() {
+			createdAt = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+			err := shortURLRepository.Save(ctx, event.NewStreamVersion, &url.ShortURLCreated{
+				Base:        event.Base{ID: "lxqrJ9xF", Version: 0, At: createdAt},
+				OriginalURL: "https://google.es",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns that the validation is pending", func() {
+			response := r.doGETRequest("/api/v1/link/lxqrJ9xF")
+
+			Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+			Expect(response).To(HaveHTTPBody(MatchJSON(`{
+				"url": "http://example.com/r/lxqrJ9xF",
+				"original_url": "https://google.es",
+				"is_valid": false,
+				"disabled": false,
+				"status": "pending",
+				"created_at": "2026-01-01T12:00:00Z"
+			}`)))
+		})
+
+		When("the original URL is verified", func() {
+			It("returns that it's valid", func() {
+				err := shortURLRepository.Save(ctx, 0, &url.ShortURLVerified{
+					Base:        event.Base{ID: "lxqrJ9xF", Version: 1, At: createdAt.Add(time.Minute)},
+					VerifiedURL: "https://google.es",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				response := r.doGETRequest("/api/v1/link/lxqrJ9xF")
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(MatchJSON(`{
+					"url": "http://example.com/r/lxqrJ9xF",
+					"original_url": "https://google.es",
+					"is_valid": true,
+					"disabled": false,
+					"status": "valid",
+					"created_at": "2026-01-01T12:00:00Z",
+					"validated_at": "2026-01-01T12:01:00Z"
+				}`)))
+			})
+		})
+
+		When("the original URL is rejected", func() {
+			It("returns the validator that rejected it and why", func() {
+				err := shortURLRepository.Save(ctx, 0, &url.ShortURLRejected{
+					Base:        event.Base{ID: "lxqrJ9xF", Version: 1, At: createdAt.Add(time.Minute)},
+					RejectedURL: "https://google.es",
+					Validator:   "reachable",
+					Reason:      "connection refused",
+					Verdicts: []url.Verdict{
+						{Validator: "schema", Result: url.VerdictPass},
+						{Validator: "reachable", Result: url.VerdictError, Reason: "connection refused", Latency: 1500 * time.Millisecond},
+						{Validator: "reachable", Result: url.VerdictFail, Reason: "404 Not Found", URL: "https://google.es", FinalURL: "https://www.google.es/", StatusCode: 404},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				response := r.doGETRequest("/api/v1/link/lxqrJ9xF")
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(MatchJSON(`{
+					"url": "http://example.com/r/lxqrJ9xF",
+					"original_url": "https://google.es",
+					"is_valid": false,
+					"disabled": false,
+					"status": "rejected",
+					"validator": "reachable",
+					"reason": "connection refused",
+					"created_at": "2026-01-01T12:00:00Z",
+					"validated_at": "2026-01-01T12:01:00Z",
+					"verdicts": [
+						{"validator": "schema", "result": "pass", "latency_ms": 0},
+						{"validator": "reachable", "result": "error", "reason": "connection refused", "latency_ms": 1500},
+						{"validator": "reachable", "result": "fail", "reason": "404 Not Found", "final_url": "https://www.google.es/", "status_code": 404, "latency_ms": 0}
+					]
+				}`)))
+			})
+		})
+
+		When("the short URL doesn't exist", func() {
+			It("returns a 404 error", func() {
+				response := r.doGETRequest("/api/v1/link/123456")
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusNotFound))
+			})
+		})
+	})
+
+	Context("when it receives an HTTP request to manage a short URL", func() {
+		BeforeEach(func() {
+			r.doPOSTRequest("/api/v1/link", longURLRequest())
+			err := shortURLRepository.Save(ctx, 0, &url.ShortURLVerified{
+				Base:        event.Base{ID: "lxqrJ9xF", Version: 1, At: time.Now()},
+				VerifiedURL: "https://google.es",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("to disable it", func() {
+			It("stops redirecting until it's enabled again", func() {
+				response := r.doPOSTRequest("/api/v1/link/lxqrJ9xF/disable", nil)
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(MatchJSON(`{"url": "http://example.com/r/lxqrJ9xF", "original_url": "https://google.es", "is_valid": true, "disabled": true}`)))
+				Expect(r.doGETRequest("/r/lxqrJ9xF")).To(HaveHTTPStatus(gohttp.StatusForbidden))
+
+				response = r.doPOSTRequest("/api/v1/link/lxqrJ9xF/enable", nil)
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				Expect(r.doGETRequest("/r/lxqrJ9xF")).To(HaveHTTPStatus(gohttp.StatusTemporaryRedirect))
+			})
+		})
+
+		Context("to delete it", func() {
+			It("is not found anymore", func() {
+				response := r.doRequest(gohttp.MethodDelete, "/api/v1/link/lxqrJ9xF", nil)
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusNoContent))
+				Expect(r.doGETRequest("/r/lxqrJ9xF")).To(HaveHTTPStatus(gohttp.StatusNotFound))
+				Expect(r.doPOSTRequest("/api/v1/link/lxqrJ9xF/enable", nil)).To(HaveHTTPStatus(gohttp.StatusNotFound))
+			})
+		})
+
+		Context("to change its target", func() {
+			It("redirects to the new URL once it's verified", func() {
+				response := r.doRequest(gohttp.MethodPatch, "/api/v1/link/lxqrJ9xF", strings.NewReader(`{"url": "https://unizar.es"}`))
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(MatchJSON(`{"url": "http://example.com/r/lxqrJ9xF", "original_url": "https://unizar.es", "is_valid": false, "disabled": false}`)))
+
+				err := shortURLRepository.Save(ctx, 2, &url.ShortURLVerified{
+					Base:        event.Base{ID: "lxqrJ9xF", Version: 3, At: time.Now()},
+					VerifiedURL: "https://unizar.es",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(r.doGETRequest("/r/lxqrJ9xF")).To(HaveHTTPHeaderWithValue("Location", "https://unizar.es"))
+			})
+
+			Context("but the URL is empty", func() {
+				It("returns StatusBadRequest code", func() {
+					response := r.doRequest(gohttp.MethodPatch, "/api/v1/link/lxqrJ9xF", strings.NewReader(`{"url": ""}`))
+
+					Expect(response).To(HaveHTTPStatus(gohttp.StatusBadRequest))
+				})
+			})
+		})
+
+		Context("but the URL is not present in the repository", func() {
+			It("returns a 404 error", func() {
+				response := r.doPOSTRequest("/api/v1/link/123456/disable", nil)
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusNotFound))
+			})
+		})
+	})
+
+	Context("when it receives an HTTP request to manage the targets of a load balanced URL", func() {
+		BeforeEach(func() {
+			r.doPOSTRequest("/api/v1/loadbalancer", loadBalancerURLRequest())
+			err := loadBalancerURLsRepository.Save(ctx, 0,
+				&url.LoadBalancedURLVerified{Base: event.Base{ID: "5XEOqhb0", Version: 1, At: time.Now()}, VerifiedURL: "https://google.es"},
+				&url.LoadBalancedURLVerified{Base: event.Base{ID: "5XEOqhb0", Version: 2, At: time.Now()}, VerifiedURL: "https://youtube.com"},
+			)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("to add a target", func() {
+			It("keeps the same link, and the new target has to be verified", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer/5XEOqhb0/targets", strings.NewReader(`{"url": "https://unizar.es"}`))
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(MatchJSON(`{
+					"url": "http://example.com/lb/5XEOqhb0",
+					"strategy": "random",
+					"targets": [
+						{"url": "https://google.es", "is_valid": true},
+						{"url": "https://youtube.com", "is_valid": true},
+						{"url": "https://unizar.es", "is_valid": false}
+					]
+				}`)))
+			})
+		})
+
+		Context("to remove a target", func() {
+			It("stops redirecting to it", func() {
+				response := r.doRequest(gohttp.MethodDelete, "/api/v1/loadbalancer/5XEOqhb0/targets?url=https://google.es", nil)
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				for i := 0; i < 10; i++ {
+					Expect(r.doGETRequest("/lb/5XEOqhb0")).To(HaveHTTPHeaderWithValue("Location", "https://youtube.com"))
+				}
+			})
+
+			Context("but it's not a target", func() {
+				It("returns a 404 error", func() {
+					response := r.doRequest(gohttp.MethodDelete, "/api/v1/loadbalancer/5XEOqhb0/targets?url=https://unizar.es", nil)
+
+					Expect(response).To(HaveHTTPStatus(gohttp.StatusNotFound))
+				})
+			})
+		})
+
+		Context("to replace the targets", func() {
+			It("keeps the validation of the targets that were already there", func() {
+				response := r.doRequest(gohttp.MethodPut, "/api/v1/loadbalancer/5XEOqhb0/targets", strings.NewReader(`{"urls": ["https://unizar.es", "https://youtube.com"]}`))
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(MatchJSON(`{
+					"url": "http://example.com/lb/5XEOqhb0",
+					"strategy": "random",
+					"targets": [
+						{"url": "https://unizar.es", "is_valid": false},
+						{"url": "https://youtube.com", "is_valid": true}
+					]
+				}`)))
+			})
+
+			Context("but there are too many targets", func() {
+				It("returns StatusBadRequest code", func() {
+					urls := make([]string, 11)
+					for i := range urls {
+						urls[i] = `"https://example.com/` + strconv.Itoa(i) + `"`
+					}
+					response := r.doRequest(gohttp.MethodPut, "/api/v1/loadbalancer/5XEOqhb0/targets", strings.NewReader(`{"urls": [`+strings.Join(urls, ",")+`]}`))
+
+					Expect(response).To(HaveHTTPStatus(gohttp.StatusBadRequest))
+				})
+			})
+		})
+
+		Context("but the load balanced URL is not present in the repository", func() {
+			It("returns a 404 error", func() {
+				response := r.doPOSTRequest("/api/v1/loadbalancer/123456/targets", strings.NewReader(`{"url": "https://unizar.es"}`))
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusNotFound))
+			})
+		})
+	})
+
+	Context("when it receives an HTTP request for the stats of a short URL", func() {
+		BeforeEach(func() {
+			r.doPOSTRequest("/api/v1/link", longURLRequest())
+			monday := time.Date(2021, 11, 29, 0, 0, 0, 0, time.UTC)
+			for _, details := range []*click.Details{
+				{Hash: "lxqrJ9xF", IP: "1.1.1.1", Referer: "https://twitter.com", UserAgent: "Firefox", ClickedAt: monday.Add(time.Hour)},
+				{Hash: "lxqrJ9xF", IP: "2.2.2.2", UserAgent: "Firefox", ClickedAt: monday.Add(25 * time.Hour)},
+			} {
+				Expect(clickerRepository.SaveClick(ctx, details)).To(Succeed())
+			}
+		})
+
+		It("returns the clicks aggregated", func() {
+			response := r.doGETRequest("/api/v1/link/lxqrJ9xF/stats?from=2021-11-29T00:00:00Z&to=2021-12-01T00:00:00Z&granularity=day")
+
+			Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+			Expect(response).To(HaveHTTPBody(MatchJSON(`{
+				"hash": "lxqrJ9xF",
+				"from": "2021-11-29T00:00:00Z",
+				"to": "2021-12-01T00:00:00Z",
+				"granularity": "day",
+				"exclude_bots": false,
+				"all_time_clicks": 2,
+				"clicks": 2,
+				"unique_visitors": 2,
+				"buckets": [
+					{"start": "2021-11-29T00:00:00Z", "clicks": 1},
+					{"start": "2021-11-30T00:00:00Z", "clicks": 1}
+				],
+				"top_referrers": [{"value": "https://twitter.com", "clicks": 1}],
+				"top_user_agents": [{"value": "Firefox", "clicks": 2}],
+				"top_countries": [],
+				"top_cities": [],
+				"top_browsers": [],
+				"top_operating_systems": [],
+				"top_devices": []
+			}`)))
+		})
+
+		Context("and the clicks of bots are excluded", func() {
+			It("leaves them out of the aggregations", func() {
+				Expect(clickerRepository.SaveClick(ctx, &click.Details{
+					Hash:      "lxqrJ9xF",
+					IP:        "3.3.3.3",
+					UserAgent: "Twitterbot/1.0",
+					IsBot:     true,
+					ClickedAt: time.Date(2021, 11, 29, 2, 0, 0, 0, time.UTC),
+				})).To(Succeed())
+
+				response := r.doGETRequest("/api/v1/link/lxqrJ9xF/stats?from=2021-11-29T00:00:00Z&to=2021-12-01T00:00:00Z&exclude_bots=true")
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				Expect(response).To(HaveHTTPBody(And(
+					MatchRegexp(`"exclude_bots":true`),
+					MatchRegexp(`"clicks":2,"unique_visitors":2`),
+				)))
+			})
+		})
+
+		Context("but the query is not valid", func() {
+			It("returns StatusBadRequest code", func() {
+				Expect(r.doGETRequest("/api/v1/link/lxqrJ9xF/stats?from=yesterday")).To(HaveHTTPStatus(gohttp.StatusBadRequest))
+				Expect(r.doGETRequest("/api/v1/link/lxqrJ9xF/stats?granularity=minute")).To(HaveHTTPStatus(gohttp.StatusBadRequest))
+			})
+		})
+
+		Context("but the URL is not present in the repository", func() {
+			It("returns a 404 error", func() {
+				Expect(r.doGETRequest("/api/v1/link/123456/stats")).To(HaveHTTPStatus(gohttp.StatusNotFound))
+			})
+		})
+	})
+
+	Context("when it receives an HTTP request for the QR code of a link", func() {
+		It("renders the short URL as a PNG by default", func() {
+			r.doPOSTRequest("/api/v1/link", longURLRequest())
+
+			response := r.doGETRequest("/r/lxqrJ9xF/qr")
+
+			Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+			Expect(response).To(HaveHTTPHeaderWithValue("Content-Type", "image/png"))
+			Expect(response).To(HaveHTTPHeaderWithValue("Cache-Control", "public, max-age=86400"))
+			Expect(response.Header.Get("ETag")).ToNot(BeEmpty())
+			decoded, err := png.Decode(response.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded.Bounds().Dx()).To(Equal(256))
+		})
+
+		It("renders the load balanced URL", func() {
+			r.doPOSTRequest("/api/v1/loadbalancer", loadBalancerURLRequest())
+
+			response := r.doGETRequest("/lb/5XEOqhb0/qr?format=svg&size=512&level=h")
+
+			Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+			Expect(response).To(HaveHTTPHeaderWithValue("Content-Type", "image/svg+xml"))
+			Expect(response).To(HaveHTTPBody(ContainSubstring(`width="512" height="512"`)))
+		})
+
+		It("replies not modified when the client has the same QR code cached", func() {
+			r.doPOSTRequest("/api/v1/link", longURLRequest())
+			etag := r.doGETRequest("/r/lxqrJ9xF/qr?format=svg").Header.Get("ETag")
+
+			response := r.doGETRequestFrom("/r/lxqrJ9xF/qr?format=svg", "192.168.1.1:52000", gohttp.Header{"If-None-Match": {etag}})
+			Expect(response).To(HaveHTTPStatus(gohttp.StatusNotModified))
+
+			response = r.doGETRequestFrom("/r/lxqrJ9xF/qr?format=png", "192.168.1.1:52000", gohttp.Header{"If-None-Match": {etag}})
+			Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+		})
+
+		Context("and the link expires", func() {
+			It("is cached until it expires", func() {
+				response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "https://google.es", "ttl": 3600}`))
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				var dataOut struct {
+					URL string `json:"url"`
+				}
+				Expect(json.NewDecoder(response.Body).Decode(&dataOut)).To(Succeed())
+
+				response = r.doGETRequest(strings.TrimPrefix(dataOut.URL, "http://example.com") + "/qr")
+
+				Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+				Expect(response.Header.Get("Cache-Control")).To(MatchRegexp(`^public, max-age=3[56]\d\d$`))
+			})
+		})
+
+		Context("but the options are not valid", func() {
+			It("returns StatusBadRequest code", func() {
+				r.doPOSTRequest("/api/v1/link", longURLRequest())
+
+				Expect(r.doGETRequest("/r/lxqrJ9xF/qr?format=gif")).To(HaveHTTPStatus(gohttp.StatusBadRequest))
+				Expect(r.doGETRequest("/r/lxqrJ9xF/qr?size=big")).To(HaveHTTPStatus(gohttp.StatusBadRequest))
+				Expect(r.doGETRequest("/r/lxqrJ9xF/qr?size=10000")).To(HaveHTTPStatus(gohttp.StatusBadRequest))
+				Expect(r.doGETRequest("/r/lxqrJ9xF/qr?level=X")).To(HaveHTTPStatus(gohttp.StatusBadRequest))
+			})
+		})
+
+		Context("but the link is not present in the repository", func() {
+			It("returns a 404 error", func() {
+				Expect(r.doGETRequest("/r/123456/qr")).To(HaveHTTPStatus(gohttp.StatusNotFound))
+				Expect(r.doGETRequest("/lb/123456/qr")).To(HaveHTTPStatus(gohttp.StatusNotFound))
+			})
+		})
+	})
+
+	Context("when it receives an HTTP request for a short URL with its QR code", func() {
+		It("returns the QR code as a PNG data URI", func() {
+			response := r.doPOSTRequest("/api/v1/link", strings.NewReader(`{"url": "https://google.es", "qr": true}`))
+
+			Expect(response).To(HaveHTTPStatus(gohttp.StatusOK))
+			var dataOut struct {
+				URL    string `json:"url"`
+				QRCode string `json:"qr_code"`
+			}
+			Expect(json.NewDecoder(response.Body).Decode(&dataOut)).To(Succeed())
+			Expect(dataOut.URL).To(Equal("http://example.com/r/lxqrJ9xF"))
+			Expect(dataOut.QRCode).To(HavePrefix("data:image/png;base64,"))
+			data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(dataOut.QRCode, "data:image/png;base64,"))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = png.Decode(bytes.NewReader(data))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
 	Context("when it receives an HTTP request to shorten a CSV file", func() {
 		It("returns a CSV with the URLs shortened", func() {
 			response := r.doPOSTFormRequest("/csv", csvFileRequest())
 
 			Expect(response.StatusCode).To(Equal(gohttp.StatusCreated))
 			Expect(response.Header.Get("Content-type")).To(Equal("text/csv"))
-			Expect(response.Header.Get("Location")).To(Equal("google.com"))
+			Expect(response.Header.Get("Location")).To(Equal("https://google.com"))
 			Expect(response).To(HaveHTTPBody(Equal(csvFileResponse())))
 
-			entity, version, err := shortURLRepository.Load(ctx, "uuqVS5Vz")
+			entity, version, err := shortURLRepository.Load(ctx, "cv6VxVdu")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(version).To(Equal(0))
 			firstURL, ok := entity.(*url.ShortURL)
 			Expect(ok).To(BeTrue())
 
-			entity, version, err = shortURLRepository.Load(ctx, "1+IiyNe6")
+			entity, version, err = shortURLRepository.Load(ctx, "unW6a4Dd")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(version).To(Equal(0))
 			secondURL, ok := entity.(*url.ShortURL)
 			Expect(ok).To(BeTrue())
 
-			Expect(firstURL.OriginalURL.URL).To(Equal("google.com"))
-			Expect(secondURL.OriginalURL.URL).To(Equal("youtube.com"))
+			Expect(firstURL.OriginalURL.URL).To(Equal("https://google.com"))
+			Expect(secondURL.OriginalURL.URL).To(Equal("https://youtube.com"))
 		})
 
 		Context("but the CSV is empty", func() {
@@ -287,8 +1073,8 @@ func csvFileRequest() io.Reader {
 Content-Disposition: form-data; name="file"
 Content-Type: text/csv
 
-google.com
-youtube.com
+https://google.com
+https://youtube.com
 --unaCadenaDelimitadora--`)
 }
 
@@ -297,14 +1083,14 @@ func badCsvFileRequest() io.Reader {
 Content-Disposition: form-data; name="badName"
 Content-Type: text/csv
 
-google.com
-youtube.com
+https://google.com
+https://youtube.com
 --unaCadenaDelimitadora--`)
 }
 
 func csvFileResponse() []byte {
-	return []byte(`google.com,http://example.com/r/uuqVS5Vz,
-youtube.com,http://example.com/r/1+IiyNe6,
+	return []byte(`https://google.com,http://example.com/r/cv6VxVdu,
+https://youtube.com,http://example.com/r/unW6a4Dd,
 `)
 }
 
@@ -320,6 +1106,15 @@ func loadBalancerURLRequest() io.Reader {
 }`)
 }
 
+// loadBalancedURLHashFrom returns the hash of the load balanced URL created by the response
+func loadBalancedURLHashFrom(response *gohttp.Response) string {
+	var dataOut struct {
+		URL string `json:"url"`
+	}
+	ExpectWithOffset(1, json.NewDecoder(response.Body).Decode(&dataOut)).To(Succeed())
+	return strings.TrimPrefix(dataOut.URL, "http://example.com/lb/")
+}
+
 func loadBalancerURLResponse() string {
 	return `{
 	"url": "http://example.com/lb/5XEOqhb0"
@@ -48,6 +48,30 @@ func (t *testingRouter) doGETRequest(path string) *gohttp.Response {
 	return recorder.Result()
 }
 
+func (t *testingRouter) doGETRequestFrom(path string, remoteAddr string, header gohttp.Header) *gohttp.Response {
+	request, err := gohttp.NewRequest(gohttp.MethodGet, path, nil)
+	ExpectWithOffset(1, err).To(Succeed())
+	request.RemoteAddr = remoteAddr
+	request.Header = header
+
+	recorder := httptest.NewRecorder()
+	router := http.NewRouter(t.config)
+	router.ServeHTTP(recorder, request)
+
+	return recorder.Result()
+}
+
+func (t *testingRouter) doRequest(method string, path string, body io.Reader) *gohttp.Response {
+	request, err := gohttp.NewRequest(method, path, body)
+	ExpectWithOffset(1, err).To(Succeed())
+
+	recorder := httptest.NewRecorder()
+	router := http.NewRouter(t.config)
+	router.ServeHTTP(recorder, request)
+
+	return recorder.Result()
+}
+
 func newTestingRouter(config http.Config) *testingRouter {
 	return &testingRouter{
 		config: config,
@@ -1,19 +1,171 @@
 package http
 
+import (
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+// expirationDataIn is the optional expiration of a link, an absolute time or a TTL in seconds
+type expirationDataIn struct {
+	ExpiresAt  time.Time `json:"expires_at"`
+	TTLSeconds int64     `json:"ttl"`
+}
+
+func (e expirationDataIn) expiration() url.Expiration {
+	return url.Expiration{
+		At:  e.ExpiresAt,
+		TTL: time.Duration(e.TTLSeconds) * time.Second,
+	}
+}
+
 type shortURLDataIn struct {
-	URL string `json:"url"`
+	expirationDataIn
+	URL   string `json:"url"`
+	Alias string `json:"alias"`
+	// QRCode includes the QR code of the short URL in the response
+	QRCode bool `json:"qr"`
 }
 
 type shortURLDataOut struct {
 	URL string `json:"url"`
+	// QRCode is a PNG data URI, only set if requested
+	QRCode string `json:"qr_code,omitempty"`
 }
 
 type csvDataOut [][]string
 
+type linkTargetDataIn struct {
+	URL string `json:"url"`
+}
+
+type linkDataOut struct {
+	URL         string `json:"url"`
+	OriginalURL string `json:"original_url"`
+	IsValid     bool   `json:"is_valid"`
+	Disabled    bool   `json:"disabled"`
+}
+
+// linkStatusDataOut is the link with the status of the validation of its original URL
+type linkStatusDataOut struct {
+	linkDataOut
+	// Status is pending, valid or rejected
+	Status string `json:"status"`
+	// Validator and Reason tell why the original URL was rejected, only if it was
+	Validator   string     `json:"validator,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ValidatedAt *time.Time `json:"validated_at,omitempty"`
+	// Verdicts are the verdicts of each validator in the last validation, only if the validator reports them
+	Verdicts []verdictDataOut `json:"verdicts,omitempty"`
+}
+
+type verdictDataOut struct {
+	Validator string `json:"validator"`
+	Result    string `json:"result"`
+	Reason    string `json:"reason,omitempty"`
+	// FinalURL and StatusCode are what the URL answered after following its redirects, only for the validators
+	// that request it
+	FinalURL   string `json:"final_url,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	LatencyMs  int64  `json:"latency_ms"`
+}
+
 type loadBalancerURLDataIn struct {
+	expirationDataIn
 	URLs []string `json:"urls"`
+	// Strategy is how the visitors are balanced between the URLs, random if it's empty
+	Strategy string `json:"strategy"`
+	// Weights are the weights of each of the URLs, only for the weighted strategy
+	Weights []int `json:"weights"`
 }
 
 type loadBalancerURLDataOut struct {
 	URL string `json:"url"`
 }
+
+type loadBalancerTargetDataIn struct {
+	URL string `json:"url"`
+	// Weight is only given to the targets of the weighted strategy
+	Weight int `json:"weight"`
+}
+
+type loadBalancerTargetsDataIn struct {
+	URLs    []string `json:"urls"`
+	Weights []int    `json:"weights"`
+}
+
+type loadBalancerLinkDataOut struct {
+	URL      string                      `json:"url"`
+	Strategy string                      `json:"strategy"`
+	Targets  []loadBalancerTargetDataOut `json:"targets"`
+}
+
+type loadBalancerTargetDataOut struct {
+	URL     string `json:"url"`
+	IsValid bool   `json:"is_valid"`
+	Weight  int    `json:"weight,omitempty"`
+}
+
+type statsDataOut struct {
+	Hash                string          `json:"hash"`
+	From                time.Time       `json:"from"`
+	To                  time.Time       `json:"to"`
+	Granularity         string          `json:"granularity"`
+	ExcludeBots         bool            `json:"exclude_bots"`
+	AllTimeClicks       int             `json:"all_time_clicks"`
+	Clicks              int             `json:"clicks"`
+	UniqueVisitors      int             `json:"unique_visitors"`
+	Buckets             []bucketDataOut `json:"buckets"`
+	TopReferrers        []countDataOut  `json:"top_referrers"`
+	TopUserAgents       []countDataOut  `json:"top_user_agents"`
+	TopCountries        []countDataOut  `json:"top_countries"`
+	TopCities           []countDataOut  `json:"top_cities"`
+	TopBrowsers         []countDataOut  `json:"top_browsers"`
+	TopOperatingSystems []countDataOut  `json:"top_operating_systems"`
+	TopDevices          []countDataOut  `json:"top_devices"`
+}
+
+type bucketDataOut struct {
+	Start  time.Time `json:"start"`
+	Clicks int       `json:"clicks"`
+}
+
+type countDataOut struct {
+	Value  string `json:"value"`
+	Clicks int    `json:"clicks"`
+}
+
+func newStatsDataOut(query click.StatsQuery, stats *click.Stats) statsDataOut {
+	dataOut := statsDataOut{
+		Hash:                stats.Hash,
+		From:                query.From,
+		To:                  query.To,
+		Granularity:         string(query.Granularity),
+		ExcludeBots:         query.ExcludeBots,
+		AllTimeClicks:       stats.AllTimeClicks,
+		Clicks:              stats.Clicks,
+		UniqueVisitors:      stats.UniqueVisitors,
+		Buckets:             make([]bucketDataOut, 0, len(stats.Buckets)),
+		TopReferrers:        newCountsDataOut(stats.TopReferrers),
+		TopUserAgents:       newCountsDataOut(stats.TopUserAgents),
+		TopCountries:        newCountsDataOut(stats.TopCountries),
+		TopCities:           newCountsDataOut(stats.TopCities),
+		TopBrowsers:         newCountsDataOut(stats.TopBrowsers),
+		TopOperatingSystems: newCountsDataOut(stats.TopOperatingSystems),
+		TopDevices:          newCountsDataOut(stats.TopDevices),
+	}
+	for _, bucket := range stats.Buckets {
+		dataOut.Buckets = append(dataOut.Buckets, bucketDataOut{Start: bucket.Start, Clicks: bucket.Clicks})
+	}
+	return dataOut
+}
+
+func newCountsDataOut(counts []*click.Count) []countDataOut {
+	dataOut := make([]countDataOut, 0, len(counts))
+	for _, count := range counts {
+		dataOut = append(dataOut, countDataOut{Value: count.Value, Clicks: count.Clicks})
+	}
+	return dataOut
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/redirect"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
 )
 
@@ -16,13 +18,26 @@ type Config struct {
 	ShortURLRepository         event.Repository
 	CustomMetrics              url.Metrics
 	LoadBalancedURLsRepository event.Repository
+	// LoadBalancerCounters keeps the turns of the load balanced URLs with the round robin strategy
+	LoadBalancerCounters redirect.CounterStore
+	ClickerRepository    click.ClickerRepository
+	// ExcludeBotClicks leaves the visits of bots out of the clicks of the short URLs and of their stats
+	ExcludeBotClicks bool
+	// Geolocator locates the IPs of the clicks, which are not located if it's nil
+	Geolocator click.Geolocator
+	// AnonymizeIPs saves only the anonymized IPs of the clicks
+	AnonymizeIPs bool
+	// Normalizer normalizes the URLs before they are shortened, the default one if it's nil
+	Normalizer *url.Normalizer
 }
 
 func NewRouter(config Config) http.Handler {
 	router := httprouter.New()
 	registerPaths(router, config)
 
-	return cors.Default().Handler(router)
+	return cors.New(cors.Options{
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead},
+	}).Handler(router)
 }
 
 type variableExtractorFunc func(request *http.Request, key string) string
@@ -41,10 +56,21 @@ func registerPaths(router *httprouter.Router, config Config) {
 	h := NewHandlerRepository(config, httprouterVariableExtractor())
 
 	router.Handler(http.MethodPost, "/api/v1/link", h.shortener())
+	router.Handler(http.MethodGet, "/api/v1/link/:hash", h.linkStatus())
+	router.Handler(http.MethodPatch, "/api/v1/link/:hash", h.linkTargetChanger())
+	router.Handler(http.MethodDelete, "/api/v1/link/:hash", h.linkDeleter())
+	router.Handler(http.MethodGet, "/api/v1/link/:hash/stats", h.linkStats())
+	router.Handler(http.MethodPost, "/api/v1/link/:hash/disable", h.linkDisabler())
+	router.Handler(http.MethodPost, "/api/v1/link/:hash/enable", h.linkEnabler())
 	router.Handler(http.MethodPost, "/api/v1/loadbalancer", h.loadBalancingURLCreator())
+	router.Handler(http.MethodPost, "/api/v1/loadbalancer/:hash/targets", h.loadBalancerTargetAdder())
+	router.Handler(http.MethodPut, "/api/v1/loadbalancer/:hash/targets", h.loadBalancerTargetsReplacer())
+	router.Handler(http.MethodDelete, "/api/v1/loadbalancer/:hash/targets", h.loadBalancerTargetRemover())
 	router.Handler(http.MethodPost, "/csv", h.csvShortener())
 	router.Handler(http.MethodGet, "/r/:hash", h.redirector())
+	router.Handler(http.MethodGet, "/r/:hash/qr", h.shortURLQRCode())
 	router.Handler(http.MethodGet, "/lb/:hash", h.loadBalancingRedirector())
+	router.Handler(http.MethodGet, "/lb/:hash/qr", h.loadBalancedURLQRCode())
 	router.Handler(http.MethodGet, "/metrics", promhttp.Handler())
 
 	router.NotFound = h.notFound()
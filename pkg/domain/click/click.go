@@ -1,18 +1,55 @@
 package click
 
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
 type Clicker struct {
 	repository ClickerRepository
+	enrichers  []Enricher
 }
 
+// Details are the information of a request redirected by a short URL
 type Details struct {
-	Hash string
-	IP   string
+	Hash           string
+	IP             string
+	UserAgent      string
+	Referer        string
+	AcceptLanguage string
+	// Country is the ISO 3166-1 alpha-2 code of the country of the IP, empty if it's unknown
+	Country   string
+	City      string
+	Browser   string
+	OS        string
+	Device    DeviceClass
+	IsBot     bool
+	ClickedAt time.Time
 }
 
-func NewClicker(repository ClickerRepository) *Clicker {
-	return &Clicker{repository: repository}
+// NewClicker creates a Clicker that completes the details of the clicks with the enrichers, in order
+func NewClicker(repository ClickerRepository, enrichers ...Enricher) *Clicker {
+	return &Clicker{
+		repository: repository,
+		enrichers:  enrichers,
+	}
 }
 
-func (l *Clicker) LogClick(clickDetails *Details) {
-	l.repository.SaveClick(clickDetails)
+// LogClick saves the details of the click once they are enriched. The click is saved even if
+// some enricher fails, without the details it would have added.
+func (l *Clicker) LogClick(ctx context.Context, clickDetails *Details) error {
+	for _, enricher := range l.enrichers {
+		err := enricher.Enrich(ctx, clickDetails)
+		if err != nil {
+			log.Printf("unable to enrich the click of %s: %s", clickDetails.Hash, err)
+		}
+	}
+
+	err := l.repository.SaveClick(ctx, clickDetails)
+	if err != nil {
+		return fmt.Errorf("unable to log the click of %s: %w", clickDetails.Hash, err)
+	}
+	return nil
 }
@@ -1,6 +1,22 @@
 package click
 
+import (
+	"context"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
 type ClickerRepository interface {
-	FindClicksByHash(hash string) []*Details
-	SaveClick(click *Details)
+	FindClicksByHash(ctx context.Context, hash string) ([]*Details, error)
+	SaveClick(ctx context.Context, click *Details) error
+	// CountClicks returns the number of clicks and of unique visitors, by IP, selected by the query: the ones of
+	// its hash clicked in [From, To), without the clicks of bots if they are excluded
+	CountClicks(ctx context.Context, query StatsQuery) (int, int, error)
+	// CountAllClicks returns the number of clicks of the hash ever received, without the clicks of bots if they are excluded
+	CountAllClicks(ctx context.Context, hash string, excludeBots bool) (int, error)
+	// CountClicksByBucket returns the clicks selected by the query grouped by the buckets of its granularity,
+	// only the buckets with clicks
+	CountClicksByBucket(ctx context.Context, query StatsQuery) ([]*Bucket, error)
+	// TopValues returns up to limit values of the dimension with more clicks selected by the query, ignoring
+	// the empty ones. The values with the same clicks are sorted by value.
+	TopValues(ctx context.Context, query StatsQuery, dimension Dimension, limit int) ([]*Count, error)
 }
@@ -1,23 +1,34 @@
 package click_test
 
 import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click/mocks"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
 )
 
 var _ = Describe("Click logger", func() {
 	var (
+		ctx        context.Context
 		clicker    *click.Clicker
 		repository click.ClickerRepository
+		classifier *mocks.MockUserAgentClassifier
 		aShortURL  *url.ShortURL
 	)
 
 	BeforeEach(func() {
+		ctx = context.Background()
+		classifier = mocks.NewMockUserAgentClassifier(gomock.NewController(GinkgoT()))
+		classifier.EXPECT().Classify(gomock.Any()).AnyTimes().Return(click.UserAgent{Device: click.UnknownDevice})
 		repository = &FakeClickerRepository{clicks: map[string][]*click.Details{}}
-		clicker = click.NewClicker(repository)
+		clicker = click.NewClicker(repository, click.NewUserAgentEnricher(classifier))
 		aShortURL = &url.ShortURL{Hash: "12345678", OriginalURL: url.OriginalURL{
 			URL:     "https://google.com",
 			IsValid: true,
@@ -28,31 +39,90 @@ var _ = Describe("Click logger", func() {
 		It("logs click details in a repository", func() {
 
 			click := &click.Details{
-				Hash: aShortURL.Hash,
-				IP:   "192.168.1.1",
+				Hash:           aShortURL.Hash,
+				IP:             "192.168.1.1",
+				UserAgent:      "Mozilla/5.0",
+				Referer:        "https://twitter.com",
+				AcceptLanguage: "es-ES,es;q=0.9",
+				ClickedAt:      time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC),
 			}
 
-			clicker.LogClick(click)
-			clicks := repository.FindClicksByHash(aShortURL.Hash)
+			err := clicker.LogClick(ctx, click)
+			Expect(err).ToNot(HaveOccurred())
+
+			clicks, err := repository.FindClicksByHash(ctx, aShortURL.Hash)
+			Expect(err).ToNot(HaveOccurred())
 			Expect(clicks).To(ContainElement(click))
 		})
 	})
 
+	Context("when the click comes from a known user agent", func() {
+		It("logs the classification of the user agent", func() {
+			classifier = mocks.NewMockUserAgentClassifier(gomock.NewController(GinkgoT()))
+			classifier.EXPECT().Classify("Slackbot-LinkExpanding 1.0").Return(click.UserAgent{Device: click.BotDevice, IsBot: true})
+			clicker = click.NewClicker(repository, click.NewUserAgentEnricher(classifier))
+
+			err := clicker.LogClick(ctx, &click.Details{Hash: aShortURL.Hash, UserAgent: "Slackbot-LinkExpanding 1.0"})
+			Expect(err).ToNot(HaveOccurred())
+
+			clicks, err := repository.FindClicksByHash(ctx, aShortURL.Hash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clicks).To(ConsistOf(&click.Details{
+				Hash:      aShortURL.Hash,
+				UserAgent: "Slackbot-LinkExpanding 1.0",
+				Device:    click.BotDevice,
+				IsBot:     true,
+			}))
+		})
+	})
+
+	Context("when the repository fails", func() {
+		It("returns the error", func() {
+			repository = &FakeClickerRepository{err: errors.New("unexpected error")}
+			clicker = click.NewClicker(repository, click.NewUserAgentEnricher(classifier))
+
+			err := clicker.LogClick(ctx, &click.Details{Hash: aShortURL.Hash})
+
+			Expect(err).To(MatchError(ContainSubstring("unexpected error")))
+		})
+	})
+
 })
 
 type FakeClickerRepository struct {
 	clicks map[string][]*click.Details
+	err    error
 }
 
-func (f *FakeClickerRepository) SaveClick(click *click.Details) {
+func (f *FakeClickerRepository) SaveClick(ctx context.Context, click *click.Details) error {
+	if f.err != nil {
+		return f.err
+	}
 	f.clicks[click.Hash] = append(f.clicks[click.Hash], click)
+	return nil
 }
 
-func (f *FakeClickerRepository) FindClicksByHash(hash string) []*click.Details {
+func (f *FakeClickerRepository) FindClicksByHash(ctx context.Context, hash string) ([]*click.Details, error) {
 	clicks, ok := f.clicks[hash]
 	if !ok {
-		return nil
+		return nil, nil
 	}
 
-	return clicks
+	return clicks, nil
+}
+
+func (f *FakeClickerRepository) CountClicks(ctx context.Context, query click.StatsQuery) (int, int, error) {
+	return 0, 0, nil
+}
+
+func (f *FakeClickerRepository) CountAllClicks(ctx context.Context, hash string, excludeBots bool) (int, error) {
+	return 0, nil
+}
+
+func (f *FakeClickerRepository) CountClicksByBucket(ctx context.Context, query click.StatsQuery) ([]*click.Bucket, error) {
+	return nil, nil
+}
+
+func (f *FakeClickerRepository) TopValues(ctx context.Context, query click.StatsQuery, dimension click.Dimension, limit int) ([]*click.Count, error) {
+	return nil, nil
 }
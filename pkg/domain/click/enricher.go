@@ -0,0 +1,72 @@
+package click
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// Enricher completes the details of a click before they are saved
+type Enricher interface {
+	Enrich(ctx context.Context, details *Details) error
+}
+
+// EnricherFunc allows to use a function as an Enricher
+type EnricherFunc func(ctx context.Context, details *Details) error
+
+func (f EnricherFunc) Enrich(ctx context.Context, details *Details) error {
+	return f(ctx, details)
+}
+
+// NewUserAgentEnricher returns an Enricher that records the classification of the user agent of the clicks
+func NewUserAgentEnricher(classifier UserAgentClassifier) Enricher {
+	return EnricherFunc(func(ctx context.Context, details *Details) error {
+		userAgent := classifier.Classify(details.UserAgent)
+		details.Browser = userAgent.Browser
+		details.OS = userAgent.OS
+		details.Device = userAgent.Device
+		details.IsBot = userAgent.IsBot
+		return nil
+	})
+}
+
+// NewGeolocationEnricher returns an Enricher that records the country and the city of the IP of the clicks
+func NewGeolocationEnricher(geolocator Geolocator) Enricher {
+	return EnricherFunc(func(ctx context.Context, details *Details) error {
+		location, err := geolocator.Locate(details.IP)
+		if err != nil {
+			return fmt.Errorf("unable to locate the ip %s: %w", details.IP, err)
+		}
+		details.Country = location.Country
+		details.City = location.City
+		return nil
+	})
+}
+
+var (
+	// anonymizedIPv4Mask keeps the first 3 octets of the IPv4 addresses
+	anonymizedIPv4Mask = net.CIDRMask(24, 32)
+	// anonymizedIPv6Mask keeps the first 48 bits of the IPv6 addresses, truncating the last 80
+	anonymizedIPv6Mask = net.CIDRMask(48, 128)
+)
+
+// NewIPAnonymizer returns an Enricher that truncates the last octet of the IPv4 addresses and the last 80 bits
+// of the IPv6 ones, so only the anonymized IP is saved. The IPs that can't be parsed are removed.
+// It must be the last Enricher of the Clicker, so the other ones can still use the full IP.
+func NewIPAnonymizer() Enricher {
+	return EnricherFunc(func(ctx context.Context, details *Details) error {
+		details.IP = anonymizeIP(details.IP)
+		return nil
+	})
+}
+
+func anonymizeIP(rawIP string) string {
+	ip := net.ParseIP(rawIP)
+	if ip == nil {
+		return ""
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return ipv4.Mask(anonymizedIPv4Mask).String()
+	}
+	return ip.Mask(anonymizedIPv6Mask).String()
+}
@@ -0,0 +1,92 @@
+package click_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click/mocks"
+)
+
+var _ = Describe("Click enrichers", func() {
+	var (
+		ctx        context.Context
+		geolocator *mocks.MockGeolocator
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		geolocator = mocks.NewMockGeolocator(gomock.NewController(GinkgoT()))
+	})
+
+	Context("when the IP of the click is located", func() {
+		It("records its country and city", func() {
+			geolocator.EXPECT().Locate("192.0.2.10").Return(click.Geolocation{Country: "ES", City: "Zaragoza"}, nil)
+			details := &click.Details{IP: "192.0.2.10"}
+
+			err := click.NewGeolocationEnricher(geolocator).Enrich(ctx, details)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(details).To(Equal(&click.Details{IP: "192.0.2.10", Country: "ES", City: "Zaragoza"}))
+		})
+	})
+
+	Context("when the geolocator fails", func() {
+		It("returns the error", func() {
+			geolocator.EXPECT().Locate("192.0.2.10").Return(click.Geolocation{}, errors.New("unexpected error"))
+
+			err := click.NewGeolocationEnricher(geolocator).Enrich(ctx, &click.Details{IP: "192.0.2.10"})
+
+			Expect(err).To(MatchError(ContainSubstring("unexpected error")))
+		})
+
+		It("the clicker still logs the click", func() {
+			geolocator.EXPECT().Locate("192.0.2.10").Return(click.Geolocation{}, errors.New("unexpected error"))
+			repository := &FakeClickerRepository{clicks: map[string][]*click.Details{}}
+			clicker := click.NewClicker(repository, click.NewGeolocationEnricher(geolocator), click.NewIPAnonymizer())
+
+			err := clicker.LogClick(ctx, &click.Details{Hash: "12345678", IP: "192.0.2.10"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository.FindClicksByHash(ctx, "12345678")).To(ConsistOf(&click.Details{Hash: "12345678", IP: "192.0.2.0"}))
+		})
+	})
+
+	DescribeTable("anonymizes the IPs",
+		func(ip string, expected string) {
+			details := &click.Details{IP: ip}
+
+			err := click.NewIPAnonymizer().Enrich(ctx, details)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(details.IP).To(Equal(expected))
+		},
+		Entry("truncating the last octet of an IPv4", "192.0.2.10", "192.0.2.0"),
+		Entry("truncating the last octet of an IPv4-mapped IPv6", "::ffff:192.0.2.10", "192.0.2.0"),
+		Entry("truncating the last 80 bits of an IPv6", "2001:db8:85a3:8d3:1319:8a2e:370:7348", "2001:db8:85a3::"),
+		Entry("removing what isn't an IP", "unknown", ""),
+	)
+
+	Context("when the geolocation runs before the anonymization", func() {
+		It("locates the full IP but only the anonymized one is saved", func() {
+			geolocator.EXPECT().Locate("2001:db8::1").Return(click.Geolocation{Country: "DE", City: "Berlin"}, nil)
+			repository := &FakeClickerRepository{clicks: map[string][]*click.Details{}}
+			clicker := click.NewClicker(repository, click.NewGeolocationEnricher(geolocator), click.NewIPAnonymizer())
+
+			err := clicker.LogClick(ctx, &click.Details{Hash: "12345678", IP: "2001:db8::1"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository.FindClicksByHash(ctx, "12345678")).To(ConsistOf(&click.Details{
+				Hash:    "12345678",
+				IP:      "2001:db8::",
+				Country: "DE",
+				City:    "Berlin",
+			}))
+		})
+	})
+})
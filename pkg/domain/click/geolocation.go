@@ -0,0 +1,15 @@
+package click
+
+// Geolocation is the place an IP is located in. Its fields are empty when they are unknown.
+type Geolocation struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country
+	Country string
+	City    string
+}
+
+// Geolocator finds the location of the IPs. It returns an empty Geolocation for the IPs it doesn't know.
+//
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+type Geolocator interface {
+	Locate(ip string) (Geolocation, error)
+}
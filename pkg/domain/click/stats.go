@@ -0,0 +1,216 @@
+package click
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+var (
+	ErrInvalidStatsQuery = errors.New("invalid stats query")
+)
+
+// Granularity is the size of the buckets the clicks are grouped by
+type Granularity string
+
+const (
+	Hour Granularity = "hour"
+	Day  Granularity = "day"
+	Week Granularity = "week"
+)
+
+const (
+	// maxBuckets limits the range of a query, so a small granularity can't be asked for a long range
+	maxBuckets = 1000
+	// topSize is the number of entries of the rankings of the stats
+	topSize = 10
+)
+
+// Dimension is a detail of the clicks that they are ranked by in the Stats
+type Dimension string
+
+const (
+	ByReferrer        Dimension = "referrer"
+	ByUserAgent       Dimension = "user agent"
+	ByCountry         Dimension = "country"
+	ByCity            Dimension = "city"
+	ByBrowser         Dimension = "browser"
+	ByOperatingSystem Dimension = "operating system"
+	ByDevice          Dimension = "device"
+)
+
+// StatsQuery selects the clicks of a short URL aggregated in the Stats, the ones in [From, To)
+type StatsQuery struct {
+	Hash        string
+	From        time.Time
+	To          time.Time
+	Granularity Granularity
+	// ExcludeBots leaves the clicks of bots out of the aggregations of the range
+	ExcludeBots bool
+}
+
+// Stats are the aggregations of the clicks of a short URL
+type Stats struct {
+	Hash string
+	// AllTimeClicks are all the clicks received by the short URL, not only the ones in the range.
+	// The clicks of bots are left out as in the range if the query excludes them.
+	AllTimeClicks       int
+	Clicks              int
+	UniqueVisitors      int
+	Buckets             []*Bucket
+	TopReferrers        []*Count
+	TopUserAgents       []*Count
+	TopCountries        []*Count
+	TopCities           []*Count
+	TopBrowsers         []*Count
+	TopOperatingSystems []*Count
+	TopDevices          []*Count
+}
+
+// Bucket are the clicks received since Start, for the granularity of the query
+type Bucket struct {
+	Start  time.Time
+	Clicks int
+}
+
+// Count are the clicks received with the same Value
+type Count struct {
+	Value  string
+	Clicks int
+}
+
+// Analytics aggregates the click details of the short URLs
+type Analytics struct {
+	clicks             ClickerRepository
+	shortURLRepository event.Repository
+}
+
+func (a *Analytics) Stats(ctx context.Context, query StatsQuery) (*Stats, error) {
+	err := validateStatsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	entity, _, err := a.shortURLRepository.Load(ctx, query.Hash)
+	if errors.Is(err, event.ErrEntityNotFound) {
+		return nil, url.ErrShortURLNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the short url: %w", err)
+	}
+	shortURL, ok := entity.(*url.ShortURL)
+	if !ok || shortURL.Deleted {
+		return nil, url.ErrShortURLNotFound
+	}
+
+	clicks, uniqueVisitors, err := a.clicks.CountClicks(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to count the clicks of the short url: %w", err)
+	}
+	allTimeClicks, err := a.clicks.CountAllClicks(ctx, query.Hash, query.ExcludeBots)
+	if err != nil {
+		return nil, fmt.Errorf("unable to count all the clicks of the short url: %w", err)
+	}
+	bucketsWithClicks, err := a.clicks.CountClicksByBucket(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to count the clicks of the short url by %s: %w", query.Granularity, err)
+	}
+
+	stats := &Stats{
+		Hash:           query.Hash,
+		AllTimeClicks:  allTimeClicks,
+		Clicks:         clicks,
+		UniqueVisitors: uniqueVisitors,
+		Buckets:        buckets(bucketsWithClicks, query),
+	}
+	rankings := []struct {
+		dimension Dimension
+		top       *[]*Count
+	}{
+		{ByReferrer, &stats.TopReferrers},
+		{ByUserAgent, &stats.TopUserAgents},
+		{ByCountry, &stats.TopCountries},
+		{ByCity, &stats.TopCities},
+		{ByBrowser, &stats.TopBrowsers},
+		{ByOperatingSystem, &stats.TopOperatingSystems},
+		{ByDevice, &stats.TopDevices},
+	}
+	for _, ranking := range rankings {
+		*ranking.top, err = a.clicks.TopValues(ctx, query, ranking.dimension, topSize)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find the top %s of the short url: %w", ranking.dimension, err)
+		}
+	}
+	return stats, nil
+}
+
+func validateStatsQuery(query StatsQuery) error {
+	if !query.From.Before(query.To) {
+		return fmt.Errorf("%w: the start of the range must be before its end", ErrInvalidStatsQuery)
+	}
+	size, ok := bucketSizes[query.Granularity]
+	if !ok {
+		return fmt.Errorf("%w: unknown granularity %q", ErrInvalidStatsQuery, query.Granularity)
+	}
+	if query.To.Sub(query.From)/size > maxBuckets {
+		return fmt.Errorf("%w: the range can't have more than %d buckets of one %s", ErrInvalidStatsQuery, maxBuckets, query.Granularity)
+	}
+	return nil
+}
+
+var bucketSizes = map[Granularity]time.Duration{
+	Hour: time.Hour,
+	Day:  24 * time.Hour,
+	Week: 7 * 24 * time.Hour,
+}
+
+// BucketStart returns the start of the bucket of the time, in UTC. The weeks start on Monday.
+func BucketStart(t time.Time, granularity Granularity) time.Time {
+	t = t.UTC()
+	switch granularity {
+	case Hour:
+		return t.Truncate(time.Hour)
+	case Week:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -daysSinceMonday)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func nextBucketStart(start time.Time, granularity Granularity) time.Time {
+	switch granularity {
+	case Hour:
+		return start.Add(time.Hour)
+	case Week:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// buckets returns every bucket of the range of the query, including the ones without clicks
+func buckets(bucketsWithClicks []*Bucket, query StatsQuery) []*Bucket {
+	clicksByStart := map[time.Time]int{}
+	for _, bucket := range bucketsWithClicks {
+		clicksByStart[BucketStart(bucket.Start, query.Granularity)] += bucket.Clicks
+	}
+
+	result := []*Bucket{}
+	for start := BucketStart(query.From, query.Granularity); start.Before(query.To); start = nextBucketStart(start, query.Granularity) {
+		result = append(result, &Bucket{Start: start, Clicks: clicksByStart[start]})
+	}
+	return result
+}
+
+func NewAnalytics(clicks ClickerRepository, shortURLRepository event.Repository) *Analytics {
+	return &Analytics{
+		clicks:             clicks,
+		shortURLRepository: shortURLRepository,
+	}
+}
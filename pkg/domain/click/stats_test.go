@@ -0,0 +1,147 @@
+package click_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	databaseinmemory "github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/inmemory"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/eventstore/inmemory"
+)
+
+var _ = Describe("Click analytics", func() {
+	var (
+		ctx                context.Context
+		clicks             *databaseinmemory.ClickerRepository
+		shortURLRepository event.Repository
+		analytics          *click.Analytics
+		monday             time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clicks = databaseinmemory.NewClickerRepository()
+		shortURLRepository = event.NewRepository(&url.ShortURL{}, inmemory.NewEventStore(), event.NewBroker())
+		analytics = click.NewAnalytics(clicks, shortURLRepository)
+		monday = time.Date(2021, 11, 29, 0, 0, 0, 0, time.UTC)
+
+		err := shortURLRepository.Save(ctx, event.NewStreamVersion,
+			&url.ShortURLCreated{Base: event.Base{ID: "cv6VxVdu", Version: 0}, OriginalURL: "https://google.com"},
+			&url.ShortURLClicked{Base: event.Base{ID: "cv6VxVdu", Version: 1}},
+			&url.ShortURLClicked{Base: event.Base{ID: "cv6VxVdu", Version: 2}},
+			&url.ShortURLClicked{Base: event.Base{ID: "cv6VxVdu", Version: 3}},
+			&url.ShortURLClicked{Base: event.Base{ID: "cv6VxVdu", Version: 4}},
+		)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	logClicks := func(clicked ...*click.Details) {
+		for _, details := range clicked {
+			details.Hash = "cv6VxVdu"
+			Expect(clicks.SaveClick(ctx, details)).To(Succeed())
+		}
+	}
+
+	It("aggregates the clicks of the range", func() {
+		logClicks(
+			&click.Details{IP: "1.1.1.1", Referer: "https://twitter.com", UserAgent: "Firefox", Country: "ES", City: "Zaragoza", Browser: "Firefox", OS: "Linux", Device: click.Desktop, ClickedAt: monday.Add(time.Hour)},
+			&click.Details{IP: "1.1.1.1", Referer: "https://twitter.com", UserAgent: "Firefox", Country: "ES", City: "Zaragoza", Browser: "Firefox", OS: "Linux", Device: click.Desktop, ClickedAt: monday.Add(2 * time.Hour)},
+			&click.Details{IP: "2.2.2.2", Referer: "https://facebook.com", UserAgent: "Chrome", Country: "FR", City: "Paris", Browser: "Chrome", OS: "Android", Device: click.Mobile, ClickedAt: monday.Add(50 * time.Hour)},
+			&click.Details{IP: "3.3.3.3", UserAgent: "Chrome", ClickedAt: monday.Add(-time.Hour)},
+		)
+
+		stats, err := analytics.Stats(ctx, click.StatsQuery{Hash: "cv6VxVdu", From: monday, To: monday.AddDate(0, 0, 3), Granularity: click.Day})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats).To(Equal(&click.Stats{
+			Hash:           "cv6VxVdu",
+			AllTimeClicks:  4,
+			Clicks:         3,
+			UniqueVisitors: 2,
+			Buckets: []*click.Bucket{
+				{Start: monday, Clicks: 2},
+				{Start: monday.AddDate(0, 0, 1), Clicks: 0},
+				{Start: monday.AddDate(0, 0, 2), Clicks: 1},
+			},
+			TopReferrers:        []*click.Count{{Value: "https://twitter.com", Clicks: 2}, {Value: "https://facebook.com", Clicks: 1}},
+			TopUserAgents:       []*click.Count{{Value: "Firefox", Clicks: 2}, {Value: "Chrome", Clicks: 1}},
+			TopCountries:        []*click.Count{{Value: "ES", Clicks: 2}, {Value: "FR", Clicks: 1}},
+			TopCities:           []*click.Count{{Value: "Zaragoza", Clicks: 2}, {Value: "Paris", Clicks: 1}},
+			TopBrowsers:         []*click.Count{{Value: "Firefox", Clicks: 2}, {Value: "Chrome", Clicks: 1}},
+			TopOperatingSystems: []*click.Count{{Value: "Linux", Clicks: 2}, {Value: "Android", Clicks: 1}},
+			TopDevices:          []*click.Count{{Value: "desktop", Clicks: 2}, {Value: "mobile", Clicks: 1}},
+		}))
+	})
+
+	It("leaves the clicks of bots out when they are excluded", func() {
+		logClicks(
+			&click.Details{IP: "1.1.1.1", Browser: "Firefox", Device: click.Desktop, ClickedAt: monday.Add(time.Hour)},
+			&click.Details{IP: "2.2.2.2", UserAgent: "Slackbot-LinkExpanding 1.0", Device: click.BotDevice, IsBot: true, ClickedAt: monday.Add(2 * time.Hour)},
+		)
+		query := click.StatsQuery{Hash: "cv6VxVdu", From: monday, To: monday.AddDate(0, 0, 1), Granularity: click.Day}
+
+		stats, err := analytics.Stats(ctx, query)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.AllTimeClicks).To(Equal(2))
+		Expect(stats.Clicks).To(Equal(2))
+
+		query.ExcludeBots = true
+		stats, err = analytics.Stats(ctx, query)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.AllTimeClicks).To(Equal(1))
+		Expect(stats.Clicks).To(Equal(1))
+		Expect(stats.UniqueVisitors).To(Equal(1))
+		Expect(stats.Buckets).To(Equal([]*click.Bucket{{Start: monday, Clicks: 1}}))
+		Expect(stats.TopUserAgents).To(BeEmpty())
+		Expect(stats.TopDevices).To(Equal([]*click.Count{{Value: "desktop", Clicks: 1}}))
+	})
+
+	DescribeTable("groups the clicks by the granularity",
+		func(granularity click.Granularity, days int, expectedBuckets int) {
+			logClicks(&click.Details{IP: "1.1.1.1", ClickedAt: monday.Add(36 * time.Hour)})
+
+			stats, err := analytics.Stats(ctx, click.StatsQuery{Hash: "cv6VxVdu", From: monday, To: monday.AddDate(0, 0, days), Granularity: granularity})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Buckets).To(HaveLen(expectedBuckets))
+		},
+		Entry("by hour", click.Hour, 2, 48),
+		Entry("by day", click.Day, 2, 2),
+		Entry("by week", click.Week, 14, 2),
+	)
+
+	It("starts the weeks on Monday", func() {
+		wednesday := monday.AddDate(0, 0, 2)
+		logClicks(&click.Details{IP: "1.1.1.1", ClickedAt: wednesday})
+
+		stats, err := analytics.Stats(ctx, click.StatsQuery{Hash: "cv6VxVdu", From: wednesday, To: wednesday.AddDate(0, 0, 1), Granularity: click.Week})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Buckets).To(Equal([]*click.Bucket{{Start: monday, Clicks: 1}}))
+	})
+
+	DescribeTable("rejects the invalid queries",
+		func(query click.StatsQuery) {
+			query.Hash = "cv6VxVdu"
+
+			_, err := analytics.Stats(ctx, query)
+
+			Expect(err).To(MatchError(click.ErrInvalidStatsQuery))
+		},
+		Entry("with an empty range", click.StatsQuery{From: time.Date(2021, 11, 29, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 11, 29, 0, 0, 0, 0, time.UTC), Granularity: click.Day}),
+		Entry("with an unknown granularity", click.StatsQuery{From: time.Date(2021, 11, 29, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 11, 30, 0, 0, 0, 0, time.UTC), Granularity: "minute"}),
+		Entry("with too many buckets", click.StatsQuery{From: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Granularity: click.Hour}),
+	)
+
+	It("returns an error for an unknown short URL", func() {
+		_, err := analytics.Stats(ctx, click.StatsQuery{Hash: "unknown", From: monday, To: monday.AddDate(0, 0, 1), Granularity: click.Day})
+
+		Expect(err).To(MatchError(url.ErrShortURLNotFound))
+	})
+})
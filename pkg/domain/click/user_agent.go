@@ -0,0 +1,27 @@
+package click
+
+// DeviceClass is the kind of device a click comes from
+type DeviceClass string
+
+const (
+	UnknownDevice DeviceClass = "unknown"
+	Desktop       DeviceClass = "desktop"
+	Mobile        DeviceClass = "mobile"
+	Tablet        DeviceClass = "tablet"
+	BotDevice     DeviceClass = "bot"
+)
+
+// UserAgent is the classification of the User-Agent header of a click.
+// Browser and OS are empty when they can't be recognised.
+type UserAgent struct {
+	Browser string
+	OS      string
+	Device  DeviceClass
+	// IsBot is set for crawlers, link previewers and HTTP libraries
+	IsBot bool
+}
+
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+type UserAgentClassifier interface {
+	Classify(userAgent string) UserAgent
+}
@@ -1,6 +1,8 @@
 package event
 
 import (
+	"context"
+	"log"
 	"reflect"
 	"sync"
 )
@@ -12,6 +14,29 @@ type Subscriber interface {
 	HandleEvent(event Event)
 }
 
+//FallibleSubscriber is a component that handles an Event, reporting if it was unable to do it.
+//It can be subscribed to a Broker through AsSubscriber, and the QueuedBroker will retry the events
+//it fails to handle.
+type FallibleSubscriber interface {
+	TryHandleEvent(ctx context.Context, event Event) error
+}
+
+//AsSubscriber adapts a FallibleSubscriber so it can be subscribed to a Broker.
+//Brokers that don't support retries just log the errors.
+func AsSubscriber(subscriber FallibleSubscriber) Subscriber {
+	return &fallibleSubscriber{FallibleSubscriber: subscriber}
+}
+
+type fallibleSubscriber struct {
+	FallibleSubscriber
+}
+
+func (f *fallibleSubscriber) HandleEvent(event Event) {
+	if err := f.TryHandleEvent(context.Background(), event); err != nil {
+		log.Printf("error handling event %s of entity %s: %s", TypeOf(event), event.EntityID(), err)
+	}
+}
+
 //Broker has the behavior of a Message Broker (https://en.wikipedia.org/wiki/Message_broker)
 //for a publish-subscribe pattern (https://en.wikipedia.org/wiki/Publish%E2%80%93subscribe_pattern)
 //where multiple subscribers can subscribe to all events, or single events and will only receive the
@@ -39,25 +64,34 @@ type Broker interface {
 }
 
 type broker struct {
+	*subscriptions
+}
+
+//Publish publishes an event to all subscribers that are subscribed for this event type, or all event types.
+func (b *broker) Publish(event Event) {
+	for _, subscriber := range b.subscribersOf(event) {
+		go subscriber.HandleEvent(event)
+	}
+}
+
+//subscriptions keeps track of the event types each Subscriber is subscribed to,
+//so it can be shared by the Broker implementations.
+type subscriptions struct {
 	mutex              sync.RWMutex
 	eventSubscriberMap map[string][]Subscriber
 }
 
 const allEventsID = "all_events"
 
-//Publish publishes an event to all subscribers that are subscribed for this event type, or all event types.
-func (b *broker) Publish(event Event) {
+//subscribersOf returns the subscribers that are subscribed for the event type, or all event types.
+func (b *subscriptions) subscribersOf(event Event) []Subscriber {
 	b.mutex.RLock()
 	defer b.mutex.RUnlock()
 
-	if subscribers, ok := b.eventSubscriberMap[TypeOf(event)]; ok {
-		for _, subscriber := range subscribers {
-			go subscriber.HandleEvent(event)
-		}
-	}
-	for _, subscriber := range b.eventSubscriberMap[allEventsID] {
-		go subscriber.HandleEvent(event)
-	}
+	subscribers := make([]Subscriber, 0, len(b.eventSubscriberMap[TypeOf(event)])+len(b.eventSubscriberMap[allEventsID]))
+	subscribers = append(subscribers, b.eventSubscriberMap[TypeOf(event)]...)
+	subscribers = append(subscribers, b.eventSubscriberMap[allEventsID]...)
+	return subscribers
 }
 
 // TypeOf is a helper func that extracts the event type of the event along with the reflect. TypeOf of the event.
@@ -74,7 +108,7 @@ func TypeOf(event Event) string {
 //keep in mind that once a subscriber is subscribed to all event types, it cannot be unsubscribed to
 //a single event type.
 //Duplicated subscriptions to the same event type will be ignored.
-func (b *broker) Subscribe(subscriber Subscriber, eventsToSubscribe ...Event) {
+func (b *subscriptions) Subscribe(subscriber Subscriber, eventsToSubscribe ...Event) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
@@ -96,7 +130,7 @@ func (b *broker) Subscribe(subscriber Subscriber, eventsToSubscribe ...Event) {
 //or all of them if no event type is specified.
 //Keep in mind that a Subscriber that's subscribed to all event types, cannot be
 //unsubscribed to a single event types.
-func (b *broker) Unsubscribe(subscriberToUnsubscribe Subscriber, events ...Event) {
+func (b *subscriptions) Unsubscribe(subscriberToUnsubscribe Subscriber, events ...Event) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
@@ -109,7 +143,7 @@ func (b *broker) Unsubscribe(subscriberToUnsubscribe Subscriber, events ...Event
 	}
 }
 
-func (b *broker) isSubscriberAlreadySubscribedToEventType(subscriber Subscriber, eventType string) bool {
+func (b *subscriptions) isSubscriberAlreadySubscribedToEventType(subscriber Subscriber, eventType string) bool {
 	for _, subscriberForAllEventTypes := range b.eventSubscriberMap[allEventsID] {
 		if subscriberForAllEventTypes == subscriber {
 			return true
@@ -129,7 +163,7 @@ func (b *broker) isSubscriberAlreadySubscribedToEventType(subscriber Subscriber,
 	return false
 }
 
-func (b *broker) unsubscribeFromAllEvents(subscriberToUnsubscribe Subscriber) {
+func (b *subscriptions) unsubscribeFromAllEvents(subscriberToUnsubscribe Subscriber) {
 	for eventType, subscribersForAType := range b.eventSubscriberMap {
 		newSubscribersListForAType := make([]Subscriber, 0, len(subscribersForAType))
 		for _, singleSubscriberForAType := range subscribersForAType {
@@ -141,7 +175,7 @@ func (b *broker) unsubscribeFromAllEvents(subscriberToUnsubscribe Subscriber) {
 	}
 }
 
-func (b *broker) unsubscribeForSingleEventType(subscriberToUnsubscribe Subscriber, eventType string) {
+func (b *subscriptions) unsubscribeForSingleEventType(subscriberToUnsubscribe Subscriber, eventType string) {
 	if subscribers, ok := b.eventSubscriberMap[eventType]; ok {
 		newSubscribersList := make([]Subscriber, 0, len(subscribers))
 		for _, subscriberInList := range subscribers {
@@ -156,6 +190,12 @@ func (b *broker) unsubscribeForSingleEventType(subscriberToUnsubscribe Subscribe
 //NewBroker creates a new broker that will handle subscriptions and event sending.
 func NewBroker() Broker {
 	return &broker{
+		subscriptions: newSubscriptions(),
+	}
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
 		eventSubscriberMap: map[string][]Subscriber{
 			allEventsID: {},
 		},
@@ -0,0 +1,101 @@
+package event
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// CatchUpSubscriber is a component that handles the events delivered by a CatchUpSubscription,
+// in the same order they were appended to the Store.
+// If an error is returned, the event will be delivered again.
+//
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+type CatchUpSubscriber interface {
+	HandleRecordedEvent(ctx context.Context, event *RecordedEvent) error
+}
+
+// CatchUpSubscription delivers all the events of the Store after a position to a CatchUpSubscriber.
+// It first replays the history of the Store and, once it's caught up, it keeps delivering the new events
+// as they are appended. The Broker is only used to be notified about new events, the events are always
+// read from the Store, so they are delivered in order even if they were appended by another process.
+type CatchUpSubscription struct {
+	store           Store
+	broker          Broker
+	subscriber      CatchUpSubscriber
+	position        int64
+	batchSize       int
+	pollingInterval time.Duration
+	newEvents       chan struct{}
+}
+
+// Start starts delivering the events to the subscriber, blocking until the context is cancelled.
+func (s *CatchUpSubscription) Start(ctx context.Context) {
+	s.broker.Subscribe(s)
+	defer s.broker.Unsubscribe(s)
+
+	ticker := time.NewTicker(s.pollingInterval)
+	defer ticker.Stop()
+	for {
+		s.catchUp(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.newEvents:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Position returns the position of the last event handled by the subscriber.
+func (s *CatchUpSubscription) Position() int64 {
+	return atomic.LoadInt64(&s.position)
+}
+
+// HandleEvent implements the Subscriber interface, to be notified when new events are published.
+func (s *CatchUpSubscription) HandleEvent(Event) {
+	select {
+	case s.newEvents <- struct{}{}:
+	default:
+	}
+}
+
+func (s *CatchUpSubscription) catchUp(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := s.store.LoadAll(ctx, s.Position(), s.batchSize)
+		if err != nil {
+			log.Printf("error loading events from position %d: %s", s.Position(), err)
+			return
+		}
+
+		for _, event := range events {
+			err := s.subscriber.HandleRecordedEvent(ctx, event)
+			if err != nil {
+				log.Printf("error handling event at position %d: %s", event.Position, err)
+				return
+			}
+			atomic.StoreInt64(&s.position, event.Position)
+		}
+
+		if len(events) < s.batchSize {
+			return
+		}
+	}
+}
+
+// NewCatchUpSubscription creates a subscription that delivers the events of the store appended after fromPosition
+// in batches of batchSize events. The store is polled every pollingInterval to find the events appended by other
+// processes, that are not published in the broker.
+func NewCatchUpSubscription(store Store, broker Broker, subscriber CatchUpSubscriber, fromPosition int64, batchSize int, pollingInterval time.Duration) *CatchUpSubscription {
+	return &CatchUpSubscription{
+		store:           store,
+		broker:          broker,
+		subscriber:      subscriber,
+		position:        fromPosition,
+		batchSize:       batchSize,
+		pollingInterval: pollingInterval,
+		newEvents:       make(chan struct{}, 1),
+	}
+}
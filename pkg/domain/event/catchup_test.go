@@ -0,0 +1,116 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/eventstore/inmemory"
+)
+
+var _ = Describe("Domain / Catch-up Subscription", func() {
+	var (
+		ctx            context.Context
+		cancel         context.CancelFunc
+		store          *inmemory.EventStore
+		broker         event.Broker
+		fakeSubscriber *FakeCatchUpSubscriber
+	)
+
+	BeforeEach(func() {
+		log.Default().SetOutput(GinkgoWriter)
+		ctx, cancel = context.WithCancel(context.Background())
+		store = inmemory.NewEventStore()
+		broker = event.NewBroker()
+		fakeSubscriber = &FakeCatchUpSubscriber{}
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	startSubscription := func(fromPosition int64) *event.CatchUpSubscription {
+		subscription := event.NewCatchUpSubscription(store, broker, fakeSubscriber, fromPosition, 2, time.Hour)
+		go subscription.Start(ctx)
+		return subscription
+	}
+
+	It("replays the history of the store in order", func() {
+		appendCounterEvents(ctx, store, "1", 0, 3)
+		appendCounterEvents(ctx, store, "2", 0, 2)
+
+		subscription := startSubscription(event.BeginningPosition)
+
+		Eventually(fakeSubscriber.PositionsHandled).Should(Equal([]int64{1, 2, 3, 4, 5}))
+		Eventually(subscription.Position).Should(Equal(int64(5)))
+	})
+
+	It("starts replaying the history after the provided position", func() {
+		appendCounterEvents(ctx, store, "1", 0, 3)
+
+		startSubscription(1)
+
+		Eventually(fakeSubscriber.PositionsHandled).Should(Equal([]int64{2, 3}))
+	})
+
+	It("delivers the new events once it is caught up", func() {
+		appendCounterEvents(ctx, store, "1", 0, 1)
+		startSubscription(event.BeginningPosition)
+		Eventually(fakeSubscriber.PositionsHandled).Should(Equal([]int64{1}))
+
+		events := appendCounterEvents(ctx, store, "1", 1, 2)
+		for _, evt := range events {
+			broker.Publish(evt)
+		}
+
+		Eventually(fakeSubscriber.PositionsHandled).Should(Equal([]int64{1, 2, 3}))
+	})
+
+	When("the subscriber fails handling an event", func() {
+		It("delivers the event again", func() {
+			fakeSubscriber.failures = 1
+			appendCounterEvents(ctx, store, "1", 0, 2)
+
+			subscription := event.NewCatchUpSubscription(store, broker, fakeSubscriber, event.BeginningPosition, 2, 10*time.Millisecond)
+			go subscription.Start(ctx)
+
+			Eventually(fakeSubscriber.PositionsHandled).Should(Equal([]int64{1, 2}))
+		})
+	})
+})
+
+func appendCounterEvents(ctx context.Context, store event.Store, id string, fromVersion, count int) []event.Event {
+	events := counterIncrementedEvents(id, fromVersion, count)
+	err := store.Append(ctx, id, fromVersion-1, events...)
+	Expect(err).ToNot(HaveOccurred())
+	return events
+}
+
+type FakeCatchUpSubscriber struct {
+	mutex     sync.Mutex
+	positions []int64
+	failures  int
+}
+
+func (f *FakeCatchUpSubscriber) HandleRecordedEvent(ctx context.Context, event *event.RecordedEvent) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("unexpected error")
+	}
+	f.positions = append(f.positions, event.Position)
+	return nil
+}
+
+func (f *FakeCatchUpSubscriber) PositionsHandled() []int64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]int64{}, f.positions...)
+}
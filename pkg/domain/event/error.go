@@ -5,9 +5,11 @@ import (
 )
 
 var (
-	ErrEntityNotFound   = errors.New("entity not found")
-	ErrUnhandledEvent   = errors.New("unhandled event")
-	ErrUnableToEncode   = errors.New("unable to encode event")
-	ErrUnableToDecode   = errors.New("unable to decode event")
-	ErrUnknownEventType = errors.New("unknown event type")
+	ErrEntityNotFound      = errors.New("entity not found")
+	ErrUnhandledEvent      = errors.New("unhandled event")
+	ErrUnableToEncode      = errors.New("unable to encode event")
+	ErrUnableToDecode      = errors.New("unable to decode event")
+	ErrUnknownEventType    = errors.New("unknown event type")
+	ErrConcurrencyConflict = errors.New("concurrency conflict")
+	ErrSnapshotNotFound    = errors.New("snapshot not found")
 )
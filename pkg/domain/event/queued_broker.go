@@ -0,0 +1,229 @@
+package event
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+)
+
+//RetryPolicy defines how many times the QueuedBroker tries to deliver an event to a FallibleSubscriber,
+//and how long it waits between the attempts. The backoff doubles after each attempt, up to MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+//DeadLetter is an event that couldn't be delivered to a subscriber.
+type DeadLetter struct {
+	Event      Event
+	Subscriber Subscriber
+	Err        error
+	Attempts   int
+}
+
+//DeadLetterSink receives the events the QueuedBroker was unable to deliver, so they are not lost.
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+type DeadLetterSink interface {
+	SendDeadLetter(ctx context.Context, deadLetter *DeadLetter) error
+}
+
+//lanesPerSubscriber is the number of queues of each subscriber. The events of an entity always go
+//to the same queue, so they are delivered in order while the events of different entities are delivered
+//concurrently.
+const lanesPerSubscriber = 8
+
+//QueuedBroker is a Broker that queues the events of each subscriber, delivering them in order per entity ID.
+//Publish blocks while the queue of a subscriber is full, until the broker is closed, so keep in mind that
+//a subscriber publishing events while handling one can block itself if its queue is full.
+//
+//The events a FallibleSubscriber fails to handle are retried according to the RetryPolicy, and sent
+//to the DeadLetterSink if they keep failing. Once the context is cancelled, the broker stops accepting
+//events, dropping them, and delivers the events already queued without retrying them.
+type QueuedBroker struct {
+	*subscriptions
+	ctx         context.Context
+	queueSize   int
+	retryPolicy RetryPolicy
+	deadLetters DeadLetterSink
+
+	mutex   sync.RWMutex
+	lanes   map[Subscriber][]chan Event
+	closed  chan struct{}
+	workers sync.WaitGroup
+	// publishing are the Publish calls sending events to the lanes, which are not closed until they finish
+	publishing sync.WaitGroup
+}
+
+//Publish queues an event for all subscribers that are subscribed for this event type, or all event types.
+func (b *QueuedBroker) Publish(event Event) {
+	lanes, ok := b.lanesOf(event)
+	if !ok {
+		log.Printf("the broker is closed, dropping event %s of entity %s", TypeOf(event), event.EntityID())
+		return
+	}
+	defer b.publishing.Done()
+
+	// the events are sent without the lock, so a full lane doesn't block the subscriptions nor the closing
+	for _, lane := range lanes {
+		select {
+		case lane <- event:
+		case <-b.closed:
+			log.Printf("the broker was closed while queueing event %s of entity %s, dropping it", TypeOf(event), event.EntityID())
+			return
+		}
+	}
+}
+
+//lanesOf returns the lanes the event is queued in, one per subscriber, or false if the broker is closed.
+//Otherwise, the lanes are not closed until b.publishing is done.
+func (b *QueuedBroker) lanesOf(event Event) ([]chan Event, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.isClosed() {
+		return nil, false
+	}
+	b.publishing.Add(1)
+
+	var lanes []chan Event
+	for _, subscriber := range b.subscribersOf(event) {
+		subscriberLanes, ok := b.lanes[subscriber]
+		if !ok {
+			continue
+		}
+		lanes = append(lanes, subscriberLanes[laneOf(event, len(subscriberLanes))])
+	}
+	return lanes, true
+}
+
+//Subscribe subscribes a Subscriber for the specified event types passed as parameter, as Broker.Subscribe does.
+//The queues of the subscriber are kept until the broker is closed, even if it unsubscribes.
+func (b *QueuedBroker) Subscribe(subscriber Subscriber, events ...Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.subscriptions.Subscribe(subscriber, events...)
+	if _, ok := b.lanes[subscriber]; ok || b.isClosed() {
+		return
+	}
+
+	lanes := make([]chan Event, 0, lanesPerSubscriber)
+	for i := 0; i < lanesPerSubscriber; i++ {
+		lane := make(chan Event, b.queueSize)
+		lanes = append(lanes, lane)
+		b.workers.Add(1)
+		go b.deliverQueuedEvents(subscriber, lane)
+	}
+	b.lanes[subscriber] = lanes
+}
+
+//Wait blocks until the context of the broker is cancelled and all the queued events are delivered.
+func (b *QueuedBroker) Wait() {
+	<-b.closed
+	b.workers.Wait()
+}
+
+func (b *QueuedBroker) closeOnDone() {
+	<-b.ctx.Done()
+
+	// closed is closed first, so the Publish calls blocked on a full lane return
+	b.mutex.Lock()
+	close(b.closed)
+	b.mutex.Unlock()
+	b.publishing.Wait()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for subscriber, lanes := range b.lanes {
+		for _, lane := range lanes {
+			close(lane)
+		}
+		delete(b.lanes, subscriber)
+	}
+}
+
+func (b *QueuedBroker) isClosed() bool {
+	select {
+	case <-b.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *QueuedBroker) deliverQueuedEvents(subscriber Subscriber, lane <-chan Event) {
+	defer b.workers.Done()
+	for event := range lane {
+		b.deliver(subscriber, event)
+	}
+}
+
+func (b *QueuedBroker) deliver(subscriber Subscriber, event Event) {
+	fallible, ok := subscriber.(FallibleSubscriber)
+	if !ok {
+		subscriber.HandleEvent(event)
+		return
+	}
+
+	backoff := b.retryPolicy.InitialBackoff
+	attempts := 0
+	for {
+		// The handlers don't receive the context of the broker, so they can handle the events drained on shutdown
+		err := fallible.TryHandleEvent(context.Background(), event)
+		attempts++
+		if err == nil {
+			return
+		}
+		if attempts >= b.retryPolicy.MaxAttempts || !b.waitBeforeRetrying(backoff) {
+			b.sendToDeadLetters(&DeadLetter{Event: event, Subscriber: subscriber, Err: err, Attempts: attempts})
+			return
+		}
+
+		backoff *= 2
+		if backoff > b.retryPolicy.MaxBackoff {
+			backoff = b.retryPolicy.MaxBackoff
+		}
+	}
+}
+
+func (b *QueuedBroker) waitBeforeRetrying(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-b.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (b *QueuedBroker) sendToDeadLetters(deadLetter *DeadLetter) {
+	err := b.deadLetters.SendDeadLetter(context.Background(), deadLetter)
+	if err != nil {
+		log.Printf("error sending event %s of entity %s to dead letters, the event is lost: %s", TypeOf(deadLetter.Event), deadLetter.Event.EntityID(), err)
+	}
+}
+
+func laneOf(event Event, lanes int) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(event.EntityID()))
+	return int(hash.Sum32() % uint32(lanes))
+}
+
+//NewQueuedBroker creates a QueuedBroker with queues of queueSize events, that is closed once the context is cancelled.
+func NewQueuedBroker(ctx context.Context, queueSize int, retryPolicy RetryPolicy, deadLetters DeadLetterSink) *QueuedBroker {
+	broker := &QueuedBroker{
+		subscriptions: newSubscriptions(),
+		ctx:           ctx,
+		queueSize:     queueSize,
+		retryPolicy:   retryPolicy,
+		deadLetters:   deadLetters,
+		lanes:         map[Subscriber][]chan Event{},
+		closed:        make(chan struct{}),
+	}
+	go broker.closeOnDone()
+	return broker
+}
@@ -0,0 +1,225 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/eventstore/inmemory"
+)
+
+var _ = Describe("Domain / Queued Broker", func() {
+	var (
+		ctx          context.Context
+		cancel       context.CancelFunc
+		deadLetters  *inmemory.DeadLetterSink
+		broker       *event.QueuedBroker
+		retryPolicy  event.RetryPolicy
+		fallibleSubs *FakeFallibleSubscriber
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		deadLetters = inmemory.NewDeadLetterSink()
+		retryPolicy = event.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+		broker = event.NewQueuedBroker(ctx, 10, retryPolicy, deadLetters)
+		fallibleSubs = &FakeFallibleSubscriber{}
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("delivers the events to the subscribers subscribed to their type", func() {
+		fakeSubscriber := &FakeSubscriber{}
+		broker.Subscribe(fakeSubscriber, &FakeEvent1{})
+
+		broker.Publish(&FakeEvent1{})
+		broker.Publish(&FakeEvent2{})
+
+		Eventually(fakeSubscriber.EventsHandled).Should(ConsistOf(BeAssignableToTypeOf(&FakeEvent1{})))
+		Consistently(fakeSubscriber.EventsHandled).Should(HaveLen(1))
+	})
+
+	It("delivers the events of an entity in order", func() {
+		broker.Subscribe(event.AsSubscriber(fallibleSubs))
+
+		for version := 0; version < 50; version++ {
+			broker.Publish(&FakeEvent1{Base: event.Base{ID: "entity1", Version: version}})
+			broker.Publish(&FakeEvent1{Base: event.Base{ID: "entity2", Version: version}})
+		}
+
+		Eventually(fallibleSubs.EventsHandled).Should(HaveLen(100))
+		Expect(sort.IntsAreSorted(versionsOf("entity1", fallibleSubs.EventsHandled()))).To(BeTrue())
+		Expect(sort.IntsAreSorted(versionsOf("entity2", fallibleSubs.EventsHandled()))).To(BeTrue())
+	})
+
+	It("retries the events a subscriber fails to handle", func() {
+		fallibleSubs.failures = 2
+		broker.Subscribe(event.AsSubscriber(fallibleSubs))
+
+		broker.Publish(&FakeEvent1{Base: event.Base{ID: "entity1"}})
+
+		Eventually(fallibleSubs.EventsHandled).Should(HaveLen(1))
+		Expect(deadLetters.DeadLetters()).To(BeEmpty())
+	})
+
+	It("sends the events to the dead letters when they keep failing", func() {
+		fallibleSubs.failures = 3
+		subscriber := event.AsSubscriber(fallibleSubs)
+		broker.Subscribe(subscriber)
+
+		broker.Publish(&FakeEvent1{Base: event.Base{ID: "entity1"}})
+
+		Eventually(deadLetters.DeadLetters).Should(HaveLen(1))
+		deadLetter := deadLetters.DeadLetters()[0]
+		Expect(deadLetter.Event).To(Equal(&FakeEvent1{Base: event.Base{ID: "entity1"}}))
+		Expect(deadLetter.Subscriber).To(Equal(subscriber))
+		Expect(deadLetter.Err).To(MatchError("unexpected error"))
+		Expect(deadLetter.Attempts).To(Equal(3))
+		Expect(fallibleSubs.EventsHandled()).To(BeEmpty())
+	})
+
+	It("stops delivering events to unsubscribed subscribers", func() {
+		fakeSubscriber := &FakeSubscriber{}
+		broker.Subscribe(fakeSubscriber)
+		broker.Unsubscribe(fakeSubscriber)
+
+		broker.Publish(&FakeEvent1{})
+
+		Consistently(fakeSubscriber.EventsHandled).Should(BeEmpty())
+	})
+
+	When("the context is cancelled", func() {
+		It("delivers the queued events before finishing", func() {
+			fallibleSubs.delay = 10 * time.Millisecond
+			broker.Subscribe(event.AsSubscriber(fallibleSubs))
+			for version := 0; version < 5; version++ {
+				broker.Publish(&FakeEvent1{Base: event.Base{ID: "entity1", Version: version}})
+			}
+
+			cancel()
+			broker.Wait()
+
+			Expect(fallibleSubs.EventsHandled()).To(HaveLen(5))
+		})
+
+		It("sends the events failing while draining to the dead letters without retrying them", func() {
+			fallibleSubs.failures = 1
+			fallibleSubs.delay = 10 * time.Millisecond
+			broker.Subscribe(event.AsSubscriber(fallibleSubs))
+			broker.Publish(&FakeEvent1{Base: event.Base{ID: "entity1"}})
+
+			cancel()
+			broker.Wait()
+
+			Expect(deadLetters.DeadLetters()).To(HaveLen(1))
+			Expect(deadLetters.DeadLetters()[0].Attempts).To(Equal(1))
+		})
+
+		It("drops the events published afterwards", func() {
+			broker.Subscribe(event.AsSubscriber(fallibleSubs))
+
+			cancel()
+			broker.Wait()
+			broker.Publish(&FakeEvent1{Base: event.Base{ID: "entity1"}})
+
+			Expect(fallibleSubs.EventsHandled()).To(BeEmpty())
+			Expect(deadLetters.DeadLetters()).To(BeEmpty())
+		})
+
+		It("stops blocking the events published while the queue is full", func() {
+			blockingSubs := &BlockingSubscriber{release: make(chan struct{})}
+			defer close(blockingSubs.release)
+			broker = event.NewQueuedBroker(ctx, 1, retryPolicy, deadLetters)
+			broker.Subscribe(blockingSubs)
+			published := publishInBackground(broker, 3)
+
+			Consistently(published).ShouldNot(BeClosed())
+			cancel()
+
+			Eventually(published).Should(BeClosed())
+		})
+	})
+
+	When("the queue of a subscriber is full", func() {
+		It("doesn't block the subscriptions", func() {
+			blockingSubs := &BlockingSubscriber{release: make(chan struct{})}
+			broker = event.NewQueuedBroker(ctx, 1, retryPolicy, deadLetters)
+			broker.Subscribe(blockingSubs)
+			published := publishInBackground(broker, 3)
+			Consistently(published).ShouldNot(BeClosed())
+
+			subscribed := make(chan struct{})
+			go func() {
+				defer close(subscribed)
+				broker.Subscribe(&FakeSubscriber{})
+			}()
+
+			Eventually(subscribed).Should(BeClosed())
+			close(blockingSubs.release)
+			Eventually(published).Should(BeClosed())
+		})
+	})
+})
+
+//publishInBackground publishes the events of an entity, closing the returned channel once they are published
+func publishInBackground(broker *event.QueuedBroker, events int) <-chan struct{} {
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for version := 0; version < events; version++ {
+			broker.Publish(&FakeEvent1{Base: event.Base{ID: "entity1", Version: version}})
+		}
+	}()
+	return published
+}
+
+type BlockingSubscriber struct {
+	release chan struct{}
+}
+
+func (b *BlockingSubscriber) HandleEvent(evt event.Event) {
+	<-b.release
+}
+
+type FakeFallibleSubscriber struct {
+	mutex         sync.Mutex
+	eventsHandled []event.Event
+	failures      int
+	delay         time.Duration
+}
+
+func (f *FakeFallibleSubscriber) TryHandleEvent(ctx context.Context, evt event.Event) error {
+	time.Sleep(f.delay)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("unexpected error")
+	}
+	f.eventsHandled = append(f.eventsHandled, evt)
+	return nil
+}
+
+func (f *FakeFallibleSubscriber) EventsHandled() []event.Event {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]event.Event{}, f.eventsHandled...)
+}
+
+func versionsOf(entityID string, events []event.Event) []int {
+	var versions []int
+	for _, evt := range events {
+		if evt.EntityID() == entityID {
+			versions = append(versions, evt.EventVersion())
+		}
+	}
+	return versions
+}
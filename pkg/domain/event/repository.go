@@ -2,9 +2,14 @@ package event
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"strings"
 )
 
 //go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
@@ -20,15 +25,36 @@ type Entity interface {
 }
 
 type Repository interface {
-	Save(ctx context.Context, events ...Event) error
+	// Save persists the events of an entity, only if the current version of the entity
+	// is the expectedVersion; otherwise ErrConcurrencyConflict is returned and nothing is saved.
+	// Use NewStreamVersion as the expectedVersion for entities that don't exist yet.
+	Save(ctx context.Context, expectedVersion int, events ...Event) error
 	Load(ctx context.Context, entityID string) (Entity, int, error)
 }
 
 // repository provides the primary abstraction to saving and loading events from a specific aggregate
 type repository struct {
-	broker    Broker
-	prototype reflect.Type
-	store     Store
+	broker        Broker
+	prototype     reflect.Type
+	schemaVersion string
+	store         Store
+	snapshotStore SnapshotStore
+	snapshotEvery int
+}
+
+// RepositoryOption configures the optional behaviour of a Repository created through NewRepository
+type RepositoryOption func(r *repository)
+
+// WithSnapshots makes the Repository load the entities starting from their latest snapshot,
+// replaying only the events after it. A new snapshot is saved each time an entity is loaded
+// replaying at least `every` events.
+// The entities are snapshotted as JSON, so all their state must be in exported fields.
+// The snapshots taken with another structure of the entity are ignored, so they don't have to be removed.
+func WithSnapshots(snapshotStore SnapshotStore, every int) RepositoryOption {
+	return func(r *repository) {
+		r.snapshotStore = snapshotStore
+		r.snapshotEvery = every
+	}
 }
 
 // New returns a new instance of the aggregate
@@ -37,33 +63,37 @@ func (r *repository) New() Entity {
 }
 
 // Save persists the events into the underlying Store
-func (r *repository) Save(ctx context.Context, events ...Event) error {
+func (r *repository) Save(ctx context.Context, expectedVersion int, events ...Event) error {
 	if len(events) == 0 {
 		return nil
 	}
 	aggregateID := events[0].EntityID()
-	err := r.store.Append(ctx, aggregateID, events...)
+	err := r.store.Append(ctx, aggregateID, expectedVersion, events...)
+	if err != nil {
+		return err
+	}
 
 	for _, event := range events {
 		r.broker.Publish(event)
 	}
-	return err
+	return nil
 }
 
 // Load retrieves the specified aggregate from the underlying store
 func (r *repository) Load(ctx context.Context, entityID string) (Entity, int, error) {
-	eventStream, err := r.store.Load(ctx, entityID)
+	aggregate, snapshotVersion := r.loadSnapshot(ctx, entityID)
+
+	eventStream, err := r.loadEventsAfter(ctx, entityID, snapshotVersion)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	eventCount := eventStream.Len()
-	if eventCount == 0 {
+	if eventCount == 0 && snapshotVersion == NewStreamVersion {
 		return nil, 0, fmt.Errorf("%w: unable to load entity [type=%v, id=%v]", ErrEntityNotFound, r.prototype.String(), entityID)
 	}
 	log.Printf("loaded %v event(s) for entity id, %v", eventCount, entityID)
 
-	aggregate := r.New()
 	for _, event := range eventStream.Events() {
 		err = aggregate.On(event)
 		if err != nil {
@@ -71,19 +101,130 @@ func (r *repository) Load(ctx context.Context, entityID string) (Entity, int, er
 		}
 	}
 
-	return aggregate, eventStream.Version(), nil
+	version := snapshotVersion
+	if eventCount > 0 {
+		version = eventStream.Version()
+	}
+
+	if r.snapshotStore != nil && eventCount > 0 && eventCount >= r.snapshotEvery {
+		r.saveSnapshot(ctx, entityID, version, aggregate)
+	}
+
+	return aggregate, version, nil
+}
+
+func (r *repository) loadEventsAfter(ctx context.Context, entityID string, version int) (*Stream, error) {
+	if version == NewStreamVersion {
+		return r.store.Load(ctx, entityID)
+	}
+	return r.store.LoadFromVersion(ctx, entityID, version)
+}
+
+// loadSnapshot returns the entity from its latest snapshot and the version of the snapshot.
+// If there's no snapshot available, a new entity is returned along with NewStreamVersion.
+func (r *repository) loadSnapshot(ctx context.Context, entityID string) (Entity, int) {
+	if r.snapshotStore == nil {
+		return r.New(), NewStreamVersion
+	}
+
+	snapshot, err := r.snapshotStore.LoadSnapshot(ctx, r.prototype.String(), entityID)
+	if errors.Is(err, ErrSnapshotNotFound) {
+		return r.New(), NewStreamVersion
+	}
+	if err != nil {
+		log.Printf("unable to load snapshot for entity id %v, replaying all its events: %s", entityID, err)
+		return r.New(), NewStreamVersion
+	}
+	if snapshot.EntityType != r.prototype.String() || snapshot.SchemaVersion != r.schemaVersion {
+		log.Printf("ignoring snapshot of another type or schema for entity id %v, replaying all its events", entityID)
+		return r.New(), NewStreamVersion
+	}
+
+	aggregate := r.New()
+	err = json.Unmarshal(snapshot.Payload, aggregate)
+	if err != nil {
+		log.Printf("unable to decode snapshot for entity id %v, replaying all its events: %s", entityID, err)
+		return r.New(), NewStreamVersion
+	}
+	return aggregate, snapshot.Version
+}
+
+func (r *repository) saveSnapshot(ctx context.Context, entityID string, version int, aggregate Entity) {
+	payload, err := json.Marshal(aggregate)
+	if err != nil {
+		log.Printf("unable to encode snapshot for entity id %v: %s", entityID, err)
+		return
+	}
+
+	err = r.snapshotStore.SaveSnapshot(ctx, &Snapshot{
+		EntityType:    r.prototype.String(),
+		EntityID:      entityID,
+		SchemaVersion: r.schemaVersion,
+		Version:       version,
+		Payload:       payload,
+	})
+	if err != nil {
+		log.Printf("unable to save snapshot for entity id %v: %s", entityID, err)
+	}
 }
 
 // NewRepository creates a new repository
-func NewRepository(prototype Entity, store Store, broker Broker) Repository {
+func NewRepository(prototype Entity, store Store, broker Broker, options ...RepositoryOption) Repository {
 	eventType := reflect.TypeOf(prototype)
 	if eventType.Kind() == reflect.Ptr {
 		eventType = eventType.Elem()
 	}
 
-	return &repository{
-		broker:    broker,
-		prototype: eventType,
-		store:     store,
+	r := &repository{
+		broker:        broker,
+		prototype:     eventType,
+		schemaVersion: schemaVersionOf(eventType),
+		store:         store,
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// schemaVersionOf identifies the structure of the type as it is snapshotted as JSON,
+// so it changes whenever a snapshotted field of the type is added, removed or changed.
+func schemaVersionOf(t reflect.Type) string {
+	hash := sha256.Sum256([]byte(schemaOf(t, map[reflect.Type]bool{})))
+	return hex.EncodeToString(hash[:8])
+}
+
+var jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) string {
+	if t.Implements(jsonMarshaler) || reflect.PtrTo(t).Implements(jsonMarshaler) || seen[t] {
+		return t.String()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + schemaOf(t.Elem(), seen)
+	case reflect.Slice:
+		return "[]" + schemaOf(t.Elem(), seen)
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), schemaOf(t.Elem(), seen))
+	case reflect.Map:
+		return "map[" + schemaOf(t.Key(), seen) + "]" + schemaOf(t.Elem(), seen)
+	case reflect.Struct:
+		seen[t] = true
+		defer delete(seen, t)
+		var builder strings.Builder
+		builder.WriteString("struct{")
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" && !field.Anonymous {
+				continue
+			}
+			fmt.Fprintf(&builder, "%s %s %q;", field.Name, schemaOf(field.Type, seen), field.Tag.Get("json"))
+		}
+		builder.WriteString("}")
+		return builder.String()
+	default:
+		return t.Kind().String()
 	}
 }
@@ -2,6 +2,7 @@ package event_test
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/golang/mock/gomock"
@@ -32,19 +33,19 @@ var _ = Describe("Domain / Event repository", func() {
 	})
 
 	It("is able to save the events in the repository", func() {
-		event := &SomeEntityCreated{Base: event.Base{ID: "1"}}
-		broker.EXPECT().Publish(event)
+		entityCreated := &SomeEntityCreated{Base: event.Base{ID: "1"}}
+		broker.EXPECT().Publish(entityCreated)
 
-		err := repository.Save(ctx, event)
+		err := repository.Save(ctx, event.NewStreamVersion, entityCreated)
 
 		Expect(err).ToNot(HaveOccurred())
 	})
 
 	It("is able to retrieve the entity in the final state with all the events applied", func() {
-		event := &SomeEntityCreated{Base: event.Base{ID: "1", Version: 2}}
-		broker.EXPECT().Publish(event)
+		entityCreated := &SomeEntityCreated{Base: event.Base{ID: "1", Version: 2}}
+		broker.EXPECT().Publish(entityCreated)
 
-		err := repository.Save(ctx, event)
+		err := repository.Save(ctx, event.NewStreamVersion, entityCreated)
 		Expect(err).ToNot(HaveOccurred())
 
 		aggregate, version, err := repository.Load(ctx, "1")
@@ -52,6 +53,169 @@ var _ = Describe("Domain / Event repository", func() {
 		Expect(version).To(Equal(2))
 		Expect(aggregate).To(Equal(&SomeEntity{ID: "1", version: 2}))
 	})
+
+	When("the expected version is not the current version of the entity", func() {
+		It("returns a concurrency conflict and doesn't save nor publish the events", func() {
+			entityCreated := &SomeEntityCreated{Base: event.Base{ID: "1", Version: 0}}
+			broker.EXPECT().Publish(entityCreated)
+			err := repository.Save(ctx, event.NewStreamVersion, entityCreated)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = repository.Save(ctx, event.NewStreamVersion, &SomeEntityCreated{Base: event.Base{ID: "1", Version: 0}})
+
+			Expect(err).To(MatchError(event.ErrConcurrencyConflict))
+			_, version, err := repository.Load(ctx, "1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version).To(Equal(0))
+		})
+	})
+
+	Context("when snapshots are enabled", func() {
+		var (
+			store         *inmemory.EventStore
+			snapshotStore *inmemory.SnapshotStore
+		)
+
+		BeforeEach(func() {
+			store = inmemory.NewEventStore()
+			snapshotStore = inmemory.NewSnapshotStore()
+			repository = event.NewRepository(&CounterEntity{}, store, broker, event.WithSnapshots(snapshotStore, 3))
+			broker.EXPECT().Publish(gomock.Any()).AnyTimes()
+		})
+
+		// snapshotOf returns a snapshot with the type and the schema of the snapshots saved by the repository
+		snapshotOf := func(entityID string, version int, payload string) *event.Snapshot {
+			err := repository.Save(ctx, event.NewStreamVersion, counterIncrementedEvents("probe", 0, 3)...)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = repository.Load(ctx, "probe")
+			Expect(err).ToNot(HaveOccurred())
+			probe, err := snapshotStore.LoadSnapshot(ctx, "event_test.CounterEntity", "probe")
+			Expect(err).ToNot(HaveOccurred())
+
+			return &event.Snapshot{EntityType: probe.EntityType, EntityID: entityID, SchemaVersion: probe.SchemaVersion, Version: version, Payload: []byte(payload)}
+		}
+
+		It("saves a snapshot once the entity is loaded replaying enough events", func() {
+			err := repository.Save(ctx, event.NewStreamVersion, counterIncrementedEvents("1", 0, 3)...)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, version, err := repository.Load(ctx, "1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version).To(Equal(2))
+
+			snapshot, err := snapshotStore.LoadSnapshot(ctx, "event_test.CounterEntity", "1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot.EntityType).To(Equal("event_test.CounterEntity"))
+			Expect(snapshot.SchemaVersion).ToNot(BeEmpty())
+			Expect(snapshot.Version).To(Equal(2))
+			Expect(snapshot.Payload).To(MatchJSON(`{"ID":"1","Count":3}`))
+		})
+
+		It("doesn't save a snapshot if there are not enough new events", func() {
+			err := repository.Save(ctx, event.NewStreamVersion, counterIncrementedEvents("1", 0, 2)...)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, err = repository.Load(ctx, "1")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = snapshotStore.LoadSnapshot(ctx, "event_test.CounterEntity", "1")
+			Expect(err).To(MatchError(event.ErrSnapshotNotFound))
+		})
+
+		It("loads the entity from the latest snapshot, replaying only the newer events", func() {
+			err := repository.Save(ctx, event.NewStreamVersion, counterIncrementedEvents("1", 0, 3)...)
+			Expect(err).ToNot(HaveOccurred())
+			err = snapshotStore.SaveSnapshot(ctx, snapshotOf("1", 1, `{"ID":"1","Count":10}`))
+			Expect(err).ToNot(HaveOccurred())
+
+			aggregate, version, err := repository.Load(ctx, "1")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version).To(Equal(2))
+			Expect(aggregate).To(Equal(&CounterEntity{ID: "1", Count: 11}))
+		})
+
+		It("loads the entity from the snapshot even if there are no newer events", func() {
+			err := repository.Save(ctx, event.NewStreamVersion, counterIncrementedEvents("1", 0, 3)...)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = repository.Load(ctx, "1")
+			Expect(err).ToNot(HaveOccurred())
+
+			aggregate, version, err := repository.Load(ctx, "1")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version).To(Equal(2))
+			Expect(aggregate).To(Equal(&CounterEntity{ID: "1", Count: 3}))
+		})
+
+		It("ignores the snapshots of the entities of another type with the same id", func() {
+			err := repository.Save(ctx, event.NewStreamVersion, counterIncrementedEvents("1", 0, 3)...)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = repository.Load(ctx, "1")
+			Expect(err).ToNot(HaveOccurred())
+			otherRepository := event.NewRepository(&SomeEntity{}, store, broker, event.WithSnapshots(snapshotStore, 3))
+
+			aggregate, _, err := otherRepository.Load(ctx, "1")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aggregate).To(Equal(&SomeEntity{}))
+		})
+
+		It("ignores the snapshots taken with another schema of the entity, replacing them", func() {
+			err := repository.Save(ctx, event.NewStreamVersion, counterIncrementedEvents("1", 0, 3)...)
+			Expect(err).ToNot(HaveOccurred())
+			outdated := snapshotOf("1", 2, `{"ID":"1","Count":10}`)
+			outdated.SchemaVersion = "outdated"
+			Expect(snapshotStore.SaveSnapshot(ctx, outdated)).To(Succeed())
+
+			aggregate, _, err := repository.Load(ctx, "1")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aggregate).To(Equal(&CounterEntity{ID: "1", Count: 3}))
+			snapshot, err := snapshotStore.LoadSnapshot(ctx, "event_test.CounterEntity", "1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot.SchemaVersion).ToNot(Equal("outdated"))
+			Expect(snapshot.Payload).To(MatchJSON(`{"ID":"1","Count":3}`))
+		})
+	})
+
+	Context("retrying an operation on conflict", func() {
+		It("retries the operation until it doesn't conflict", func() {
+			attempts := 0
+			err := event.RetryOnConflict(ctx, 3, func(ctx context.Context) error {
+				attempts++
+				if attempts < 3 {
+					return event.ErrConcurrencyConflict
+				}
+				return nil
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(attempts).To(Equal(3))
+		})
+
+		It("gives up after the maximum number of attempts", func() {
+			attempts := 0
+			err := event.RetryOnConflict(ctx, 3, func(ctx context.Context) error {
+				attempts++
+				return event.ErrConcurrencyConflict
+			})
+
+			Expect(err).To(MatchError(event.ErrConcurrencyConflict))
+			Expect(attempts).To(Equal(3))
+		})
+
+		It("doesn't retry other errors", func() {
+			attempts := 0
+			err := event.RetryOnConflict(ctx, 3, func(ctx context.Context) error {
+				attempts++
+				return errors.New("unknown error")
+			})
+
+			Expect(err).To(MatchError("unknown error"))
+			Expect(attempts).To(Equal(1))
+		})
+	})
 })
 
 type SomeEntity struct {
@@ -71,3 +235,31 @@ func (s *SomeEntity) On(event event.Event) error {
 type SomeEntityCreated struct {
 	event.Base
 }
+
+type CounterEntity struct {
+	ID    string
+	Count int
+}
+
+func (c *CounterEntity) On(evt event.Event) error {
+	switch evt.(type) {
+	case *CounterIncremented:
+		c.ID = evt.EntityID()
+		c.Count++
+	default:
+		return event.ErrUnhandledEvent
+	}
+	return nil
+}
+
+type CounterIncremented struct {
+	event.Base
+}
+
+func counterIncrementedEvents(id string, fromVersion, count int) []event.Event {
+	events := make([]event.Event, 0, count)
+	for version := fromVersion; version < fromVersion+count; version++ {
+		events = append(events, &CounterIncremented{Base: event.Base{ID: id, Version: version}})
+	}
+	return events
+}
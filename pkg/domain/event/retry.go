@@ -0,0 +1,26 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// RetryOnConflict executes the operation until it succeeds, it fails with an error different from
+// ErrConcurrencyConflict, or the maximum number of attempts is reached.
+// The operation is expected to load the entity again on each attempt, so the events are
+// generated from its latest version.
+func RetryOnConflict(ctx context.Context, maxAttempts int, operation func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		err = operation(ctx)
+		if !errors.Is(err, ErrConcurrencyConflict) {
+			return err
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, err)
+}
@@ -0,0 +1,36 @@
+package event
+
+import (
+	"context"
+)
+
+// Snapshot contains the state of an Entity at a given version of its stream,
+// so the Entity can be loaded without replaying all of its events.
+type Snapshot struct {
+	// EntityType contains the type of the entity the snapshot belongs to, as the entities of
+	// different types may share the same store
+	EntityType string
+
+	// EntityID contains the ID of the entity the snapshot belongs to
+	EntityID string
+
+	// SchemaVersion identifies the structure of the entity when the snapshot was taken
+	SchemaVersion string
+
+	// Version contains the version of the last event applied to the entity
+	Version int
+
+	// Payload contains the serialized state of the entity
+	Payload []byte
+}
+
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+// SnapshotStore provides an abstraction for the repository to save and load snapshots
+type SnapshotStore interface {
+	// SaveSnapshot saves the snapshot of an entity, replacing the previous one if it's older
+	// or if it has another schema version
+	SaveSnapshot(ctx context.Context, snapshot *Snapshot) error
+
+	// LoadSnapshot returns the latest snapshot of an entity of the type, or ErrSnapshotNotFound if there's none
+	LoadSnapshot(ctx context.Context, entityType, entityID string) (*Snapshot, error)
+}
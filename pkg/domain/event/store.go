@@ -40,21 +40,51 @@ func (e *Stream) Append(events ...Event) {
 	e.version = e.events[len(e.events)-1].EventVersion()
 }
 
+// StreamFrom creates a Stream sorted by version from the events.
+// If there are no events, the version of the stream is NewStreamVersion.
 func StreamFrom(events []Event) *Stream {
 	stream := &Stream{
-		events: events,
+		events:  events,
+		version: NewStreamVersion,
+	}
+	if stream.Len() == 0 {
+		return stream
 	}
 	sort.Sort(stream)
 	stream.version = stream.events[stream.Len()-1].EventVersion()
 	return stream
 }
 
+// RecordedEvent is an Event along with its position in the global order of the Store,
+// which is the order in which all the events were appended, regardless of their entity.
+type RecordedEvent struct {
+	Event    Event
+	Position int64
+}
+
+// BeginningPosition is the position before the first event of the Store.
+// It must be used to read all the events of the Store from the beginning.
+const BeginningPosition int64 = 0
+
+// NewStreamVersion is the version of a stream that doesn't contain any event yet.
+// It must be used as the expected version when appending the first events of an entity.
+const NewStreamVersion = -1
+
 //go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
 // Store provides an abstraction for the repository to save data
 type Store interface {
-	// Save the provided events to the store
-	Append(ctx context.Context, identity string, events ...Event) error
+	// Append saves the provided events to the store, only if the current version of
+	// the stream is the expectedVersion. Otherwise, ErrConcurrencyConflict is returned.
+	Append(ctx context.Context, identity string, expectedVersion int, events ...Event) error
 
 	// Load the history of events.
 	Load(ctx context.Context, identity string) (*Stream, error)
+
+	// LoadFromVersion loads the events of the history newer than fromVersion.
+	// The returned Stream is empty if there are no newer events.
+	LoadFromVersion(ctx context.Context, identity string, fromVersion int) (*Stream, error)
+
+	// LoadAll loads at most batchSize events of any entity appended after fromPosition,
+	// sorted by their position in the Store.
+	LoadAll(ctx context.Context, fromPosition int64, batchSize int) ([]*RecordedEvent, error)
 }
@@ -0,0 +1,29 @@
+package projection
+
+import (
+	"context"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+// Projection builds a read model from the events of the store.
+// The events may be projected more than once, so projecting an event must be idempotent.
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+type Projection interface {
+	// Name identifies the projection, and it's used to save its checkpoint.
+	Name() string
+
+	// Project applies the event to the read model. Events not relevant for the projection must be ignored.
+	Project(ctx context.Context, evt event.Event) error
+}
+
+// CheckpointStore saves the position of the last event projected by each projection,
+// so they can continue from there when they are started again.
+type CheckpointStore interface {
+	// SaveCheckpoint saves the position of the last event projected.
+	SaveCheckpoint(ctx context.Context, name string, position int64) error
+
+	// LoadCheckpoint returns the position of the last event projected,
+	// or event.BeginningPosition if the projection has never been run.
+	LoadCheckpoint(ctx context.Context, name string) (int64, error)
+}
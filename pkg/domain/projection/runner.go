@@ -0,0 +1,70 @@
+package projection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+// Runner feeds the projections with the events of the store, saving their checkpoints as they go.
+type Runner struct {
+	store           event.Store
+	broker          event.Broker
+	checkpoints     CheckpointStore
+	batchSize       int
+	pollingInterval time.Duration
+}
+
+// Run projects the events appended after the last checkpoint of the projection,
+// and keeps projecting the new ones until the context is cancelled.
+func (r *Runner) Run(ctx context.Context, projection Projection) error {
+	position, err := r.checkpoints.LoadCheckpoint(ctx, projection.Name())
+	if err != nil {
+		return fmt.Errorf("unable to load the checkpoint of projection %s: %w", projection.Name(), err)
+	}
+	r.run(ctx, projection, position)
+	return nil
+}
+
+// Replay projects all the events of the store from the beginning, ignoring the last checkpoint of the projection,
+// and keeps projecting the new ones until the context is cancelled.
+func (r *Runner) Replay(ctx context.Context, projection Projection) {
+	r.run(ctx, projection, event.BeginningPosition)
+}
+
+func (r *Runner) run(ctx context.Context, projection Projection, fromPosition int64) {
+	subscriber := &checkpointingSubscriber{projection: projection, checkpoints: r.checkpoints}
+	event.NewCatchUpSubscription(r.store, r.broker, subscriber, fromPosition, r.batchSize, r.pollingInterval).Start(ctx)
+}
+
+type checkpointingSubscriber struct {
+	projection  Projection
+	checkpoints CheckpointStore
+}
+
+func (c *checkpointingSubscriber) HandleRecordedEvent(ctx context.Context, recordedEvent *event.RecordedEvent) error {
+	err := c.projection.Project(ctx, recordedEvent.Event)
+	if err != nil {
+		return fmt.Errorf("unable to project event in projection %s: %w", c.projection.Name(), err)
+	}
+
+	err = c.checkpoints.SaveCheckpoint(ctx, c.projection.Name(), recordedEvent.Position)
+	if err != nil {
+		return fmt.Errorf("unable to save the checkpoint of projection %s: %w", c.projection.Name(), err)
+	}
+	return nil
+}
+
+// NewRunner creates a Runner that reads the events from the store in batches of batchSize events,
+// polling it every pollingInterval to find the events appended by other processes.
+func NewRunner(store event.Store, broker event.Broker, checkpoints CheckpointStore, batchSize int, pollingInterval time.Duration) *Runner {
+	return &Runner{
+		store:           store,
+		broker:          broker,
+		checkpoints:     checkpoints,
+		batchSize:       batchSize,
+		pollingInterval: pollingInterval,
+	}
+}
@@ -0,0 +1,131 @@
+package projection_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/inmemory"
+	eventstore "github.com/WebEngineeringGroupI/backend/pkg/infrastructure/eventstore/inmemory"
+)
+
+var _ = Describe("Domain / Projection / Runner", func() {
+	var (
+		ctx            context.Context
+		cancel         context.CancelFunc
+		store          *eventstore.EventStore
+		broker         event.Broker
+		checkpoints    *inmemory.CheckpointStore
+		fakeProjection *FakeProjection
+		runner         *projection.Runner
+	)
+
+	BeforeEach(func() {
+		log.Default().SetOutput(GinkgoWriter)
+		ctx, cancel = context.WithCancel(context.Background())
+		store = eventstore.NewEventStore()
+		broker = event.NewBroker()
+		checkpoints = inmemory.NewCheckpointStore()
+		fakeProjection = &FakeProjection{}
+		runner = projection.NewRunner(store, broker, checkpoints, 10, 10*time.Millisecond)
+
+		err := store.Append(ctx, "hash1", event.NewStreamVersion,
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0}},
+			&url.ShortURLClicked{Base: event.Base{ID: "hash1", Version: 1}},
+		)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("projects the events of the store and saves the checkpoint", func() {
+		go func() { _ = runner.Run(ctx, fakeProjection) }()
+
+		Eventually(fakeProjection.EventsProjected).Should(Equal([]event.Event{
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0}},
+			&url.ShortURLClicked{Base: event.Base{ID: "hash1", Version: 1}},
+		}))
+		Eventually(func() (int64, error) { return checkpoints.LoadCheckpoint(ctx, "fake") }).Should(Equal(int64(2)))
+	})
+
+	It("keeps projecting the new events", func() {
+		go func() { _ = runner.Run(ctx, fakeProjection) }()
+		Eventually(fakeProjection.EventsProjected).Should(HaveLen(2))
+
+		clicked := &url.ShortURLClicked{Base: event.Base{ID: "hash1", Version: 2}}
+		err := store.Append(ctx, "hash1", 1, clicked)
+		Expect(err).ToNot(HaveOccurred())
+		broker.Publish(clicked)
+
+		Eventually(fakeProjection.EventsProjected).Should(HaveLen(3))
+		Eventually(func() (int64, error) { return checkpoints.LoadCheckpoint(ctx, "fake") }).Should(Equal(int64(3)))
+	})
+
+	It("continues from the last checkpoint of the projection", func() {
+		err := checkpoints.SaveCheckpoint(ctx, "fake", 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		go func() { _ = runner.Run(ctx, fakeProjection) }()
+
+		Eventually(fakeProjection.EventsProjected).Should(Equal([]event.Event{
+			&url.ShortURLClicked{Base: event.Base{ID: "hash1", Version: 1}},
+		}))
+	})
+
+	It("replays the events from the beginning ignoring the checkpoint", func() {
+		err := checkpoints.SaveCheckpoint(ctx, "fake", 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		go runner.Replay(ctx, fakeProjection)
+
+		Eventually(fakeProjection.EventsProjected).Should(HaveLen(2))
+	})
+
+	When("the projection fails", func() {
+		It("doesn't save the checkpoint and projects the event again", func() {
+			fakeProjection.failures = 1
+
+			go func() { _ = runner.Run(ctx, fakeProjection) }()
+
+			Eventually(fakeProjection.EventsProjected).Should(HaveLen(2))
+			Expect(checkpoints.LoadCheckpoint(ctx, "fake")).To(Equal(int64(2)))
+		})
+	})
+})
+
+type FakeProjection struct {
+	mutex    sync.Mutex
+	events   []event.Event
+	failures int
+}
+
+func (f *FakeProjection) Name() string {
+	return "fake"
+}
+
+func (f *FakeProjection) Project(ctx context.Context, evt event.Event) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("unexpected error")
+	}
+	f.events = append(f.events, evt)
+	return nil
+}
+
+func (f *FakeProjection) EventsProjected() []event.Event {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]event.Event{}, f.events...)
+}
@@ -0,0 +1,122 @@
+package projection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+var (
+	ErrShortURLViewNotFound = errors.New("short url view not found")
+)
+
+// ShortURLView is the queryable read model of a url.ShortURL
+type ShortURLView struct {
+	Hash        string
+	OriginalURL string
+	IsValid     bool
+	// IsRejected tells if the original URL was rejected, with the reason of the validator that rejected it
+	IsRejected      bool
+	RejectionReason string
+	Clicks          int
+	CreatedAt       time.Time
+	// Deleted views are kept, so the events projected again are ignored, but they are not listed
+	Deleted bool
+	// Version is the version of the last event projected in the view
+	Version int
+}
+
+// ShortURLViewFilter restricts the views listed. Zero values don't restrict anything.
+type ShortURLViewFilter struct {
+	IsValid *bool
+	Limit   int
+	Offset  int
+}
+
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+type ShortURLViewRepository interface {
+	// SaveShortURLView saves the view, only if it's newer than the one already saved.
+	SaveShortURLView(ctx context.Context, view *ShortURLView) error
+
+	// FindShortURLView returns ErrShortURLViewNotFound if there is no view for the hash.
+	FindShortURLView(ctx context.Context, hash string) (*ShortURLView, error)
+
+	// ListShortURLViews returns the views matching the filter that are not deleted, the newest ones first.
+	ListShortURLViews(ctx context.Context, filter ShortURLViewFilter) ([]*ShortURLView, error)
+}
+
+const ShortURLViewProjectionName = "short_url_view"
+
+// ShortURLViewProjection keeps a ShortURLView for every url.ShortURL
+type ShortURLViewProjection struct {
+	repository ShortURLViewRepository
+}
+
+func (s *ShortURLViewProjection) Name() string {
+	return ShortURLViewProjectionName
+}
+
+func (s *ShortURLViewProjection) Project(ctx context.Context, evt event.Event) error {
+	switch e := evt.(type) {
+	case *url.ShortURLCreated:
+		return s.repository.SaveShortURLView(ctx, &ShortURLView{
+			Hash:        e.EntityID(),
+			OriginalURL: e.OriginalURL,
+			CreatedAt:   e.HappenedOn(),
+			Version:     e.EventVersion(),
+		})
+	case *url.ShortURLVerified:
+		return s.update(ctx, e, func(view *ShortURLView) {
+			if e.VerifiedURL == "" || e.VerifiedURL == view.OriginalURL {
+				view.IsValid = true
+				view.IsRejected = false
+				view.RejectionReason = ""
+			}
+		})
+	case *url.ShortURLRejected:
+		return s.update(ctx, e, func(view *ShortURLView) {
+			// the target may have been changed after it was sent to be verified
+			if e.RejectedURL == view.OriginalURL {
+				view.IsValid = false
+				view.IsRejected = true
+				view.RejectionReason = e.Reason
+			}
+		})
+	case *url.ShortURLTargetChanged:
+		return s.update(ctx, e, func(view *ShortURLView) {
+			view.OriginalURL = e.OriginalURL
+			view.IsValid = false
+			view.IsRejected = false
+			view.RejectionReason = ""
+		})
+	case *url.ShortURLClicked:
+		return s.update(ctx, e, func(view *ShortURLView) { view.Clicks++ })
+	case *url.ShortURLDeleted:
+		return s.update(ctx, e, func(view *ShortURLView) { view.Deleted = true })
+	}
+	return nil
+}
+
+func (s *ShortURLViewProjection) update(ctx context.Context, evt event.Event, apply func(view *ShortURLView)) error {
+	view, err := s.repository.FindShortURLView(ctx, evt.EntityID())
+	if err != nil {
+		return fmt.Errorf("unable to find the view to update: %w", err)
+	}
+	if view.Version >= evt.EventVersion() {
+		return nil
+	}
+
+	apply(view)
+	view.Version = evt.EventVersion()
+	return s.repository.SaveShortURLView(ctx, view)
+}
+
+func NewShortURLViewProjection(repository ShortURLViewRepository) *ShortURLViewProjection {
+	return &ShortURLViewProjection{
+		repository: repository,
+	}
+}
@@ -0,0 +1,187 @@
+package projection_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/inmemory"
+)
+
+var _ = Describe("Domain / Projection / Short URL View", func() {
+	var (
+		ctx        context.Context
+		repository *inmemory.ShortURLViewRepository
+		view       *projection.ShortURLViewProjection
+		createdAt  time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repository = inmemory.NewShortURLViewRepository()
+		view = projection.NewShortURLViewProjection(repository)
+		createdAt = time.Date(2021, 11, 20, 10, 0, 0, 0, time.UTC)
+	})
+
+	project := func(events ...event.Event) {
+		for _, evt := range events {
+			Expect(view.Project(ctx, evt)).To(Succeed())
+		}
+	}
+
+	It("creates the view of a new short url", func() {
+		project(&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"})
+
+		Expect(repository.FindShortURLView(ctx, "hash1")).To(Equal(&projection.ShortURLView{
+			Hash:        "hash1",
+			OriginalURL: "https://google.es",
+			CreatedAt:   createdAt,
+		}))
+	})
+
+	It("updates the view when the short url is verified and clicked", func() {
+		project(
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"},
+			&url.ShortURLVerified{Base: event.Base{ID: "hash1", Version: 1}},
+			&url.ShortURLClicked{Base: event.Base{ID: "hash1", Version: 2}},
+			&url.ShortURLClicked{Base: event.Base{ID: "hash1", Version: 3}},
+		)
+
+		Expect(repository.FindShortURLView(ctx, "hash1")).To(Equal(&projection.ShortURLView{
+			Hash:        "hash1",
+			OriginalURL: "https://google.es",
+			IsValid:     true,
+			Clicks:      2,
+			CreatedAt:   createdAt,
+			Version:     3,
+		}))
+	})
+
+	It("updates the view when the target of the short url is changed", func() {
+		project(
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"},
+			&url.ShortURLVerified{Base: event.Base{ID: "hash1", Version: 1}, VerifiedURL: "https://google.es"},
+			&url.ShortURLTargetChanged{Base: event.Base{ID: "hash1", Version: 2}, OriginalURL: "https://unizar.es"},
+			&url.ShortURLVerified{Base: event.Base{ID: "hash1", Version: 3}, VerifiedURL: "https://google.es"},
+		)
+
+		shortURLView, err := repository.FindShortURLView(ctx, "hash1")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURLView.OriginalURL).To(Equal("https://unizar.es"))
+		Expect(shortURLView.IsValid).To(BeFalse())
+	})
+
+	It("updates the view when the short url is rejected", func() {
+		project(
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"},
+			&url.ShortURLRejected{Base: event.Base{ID: "hash1", Version: 1}, RejectedURL: "https://google.es", Validator: "safebrowsing", Reason: "malware"},
+		)
+
+		Expect(repository.FindShortURLView(ctx, "hash1")).To(Equal(&projection.ShortURLView{
+			Hash:            "hash1",
+			OriginalURL:     "https://google.es",
+			IsRejected:      true,
+			RejectionReason: "malware",
+			CreatedAt:       createdAt,
+			Version:         1,
+		}))
+	})
+
+	It("ignores the rejection of a previous target of the short url", func() {
+		project(
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"},
+			&url.ShortURLTargetChanged{Base: event.Base{ID: "hash1", Version: 1}, OriginalURL: "https://unizar.es"},
+			&url.ShortURLRejected{Base: event.Base{ID: "hash1", Version: 2}, RejectedURL: "https://google.es", Reason: "malware"},
+		)
+
+		shortURLView, err := repository.FindShortURLView(ctx, "hash1")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURLView.IsRejected).To(BeFalse())
+		Expect(shortURLView.Version).To(Equal(2))
+	})
+
+	It("is not rejected anymore when the target of a rejected short url is changed", func() {
+		project(
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"},
+			&url.ShortURLRejected{Base: event.Base{ID: "hash1", Version: 1}, RejectedURL: "https://google.es", Reason: "malware"},
+			&url.ShortURLTargetChanged{Base: event.Base{ID: "hash1", Version: 2}, OriginalURL: "https://unizar.es"},
+		)
+
+		shortURLView, err := repository.FindShortURLView(ctx, "hash1")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURLView.IsRejected).To(BeFalse())
+		Expect(shortURLView.RejectionReason).To(BeEmpty())
+	})
+
+	It("marks the view as deleted when the short url is deleted", func() {
+		project(
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"},
+			&url.ShortURLDeleted{Base: event.Base{ID: "hash1", Version: 1}},
+		)
+
+		shortURLView, err := repository.FindShortURLView(ctx, "hash1")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURLView.Deleted).To(BeTrue())
+		Expect(shortURLView.Version).To(Equal(1))
+	})
+
+	It("doesn't list the views of the deleted short urls", func() {
+		project(
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"},
+			&url.ShortURLCreated{Base: event.Base{ID: "hash2", Version: 0, At: createdAt.Add(time.Minute)}, OriginalURL: "https://unizar.es"},
+			&url.ShortURLDeleted{Base: event.Base{ID: "hash2", Version: 1}},
+		)
+
+		views, err := repository.ListShortURLViews(ctx, projection.ShortURLViewFilter{})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(views).To(HaveLen(1))
+		Expect(views[0].Hash).To(Equal("hash1"))
+	})
+
+	It("ignores the events already projected", func() {
+		created := &url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"}
+		clicked := &url.ShortURLClicked{Base: event.Base{ID: "hash1", Version: 1}}
+		project(created, clicked, created, clicked)
+
+		shortURLView, err := repository.FindShortURLView(ctx, "hash1")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURLView.Clicks).To(Equal(1))
+	})
+
+	It("ignores the events of other entities", func() {
+		project(&url.LoadBalancedURLCreated{Base: event.Base{ID: "hash1", Version: 0}, OriginalURLs: []string{"https://google.es"}})
+
+		_, err := repository.FindShortURLView(ctx, "hash1")
+
+		Expect(err).To(MatchError(projection.ErrShortURLViewNotFound))
+	})
+
+	It("allows to list the views by their validity", func() {
+		project(
+			&url.ShortURLCreated{Base: event.Base{ID: "hash1", Version: 0, At: createdAt}, OriginalURL: "https://google.es"},
+			&url.ShortURLCreated{Base: event.Base{ID: "hash2", Version: 0, At: createdAt.Add(time.Minute)}, OriginalURL: "https://unizar.es"},
+			&url.ShortURLCreated{Base: event.Base{ID: "hash3", Version: 0, At: createdAt.Add(2 * time.Minute)}, OriginalURL: "https://youtube.com"},
+			&url.ShortURLVerified{Base: event.Base{ID: "hash1", Version: 1}},
+			&url.ShortURLVerified{Base: event.Base{ID: "hash3", Version: 1}},
+		)
+		isValid := true
+
+		views, err := repository.ListShortURLViews(ctx, projection.ShortURLViewFilter{IsValid: &isValid})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(views).To(HaveLen(2))
+		Expect(views[0].Hash).To(Equal("hash3"))
+		Expect(views[1].Hash).To(Equal("hash1"))
+	})
+})
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
@@ -12,11 +11,15 @@ import (
 
 type LoadBalancerRedirectorService struct {
 	repository event.Repository
+	clock      event.Clock
+	strategies Strategies
 }
 
-func (r *LoadBalancerRedirectorService) ReturnAValidOriginalURL(ctx context.Context, hash string) (string, error) {
+// ReturnAValidOriginalURL returns the valid URL the visitor is redirected to, chosen by the strategy of the link
+func (r *LoadBalancerRedirectorService) ReturnAValidOriginalURL(ctx context.Context, hash string, visitor Visitor) (string, error) {
 	loadBalancedURLsEntity, _, err := r.repository.Load(ctx, hash)
-	if errors.Is(err, event.ErrEntityNotFound) {
+	// the events of a short url can't be handled, as the hash is not of a load balanced url
+	if errors.Is(err, event.ErrEntityNotFound) || errors.Is(err, event.ErrUnhandledEvent) {
 		return "", url.ErrValidURLNotFound
 	}
 
@@ -28,25 +31,40 @@ func (r *LoadBalancerRedirectorService) ReturnAValidOriginalURL(ctx context.Cont
 		return "", fmt.Errorf("the entity loaded is not a LoadBalancedURL: %w", url.ErrValidURLNotFound)
 	}
 
+	if loadBalancedURLs.IsExpired(r.clock.Now()) {
+		return "", url.ErrShortURLExpired
+	}
+
 	validURLs := r.filterValidURLs(loadBalancedURLs.LongURLs)
 	if len(validURLs) == 0 {
 		return "", url.ErrValidURLNotFound
 	}
 
-	nextIndex := rand.Intn(len(validURLs)) // TODO(fede): Implement roundRobin with MemCached service
-	return validURLs[nextIndex], nil
+	strategy, ok := r.strategies[loadBalancedURLs.Strategy.OrDefault()]
+	if !ok {
+		return "", fmt.Errorf("no implementation of the %s strategy of %s", loadBalancedURLs.Strategy.OrDefault(), hash)
+	}
+	target, err := strategy.Select(ctx, loadBalancedURLs, validURLs, visitor)
+	if err != nil {
+		return "", err
+	}
+	return target.URL, nil
 }
 
-func (r *LoadBalancerRedirectorService) filterValidURLs(originalURLs []url.OriginalURL) []string {
-	validURLs := []string{}
+func (r *LoadBalancerRedirectorService) filterValidURLs(originalURLs []url.OriginalURL) []url.OriginalURL {
+	validURLs := []url.OriginalURL{}
 	for _, aURL := range originalURLs {
 		if aURL.IsValid {
-			validURLs = append(validURLs, aURL.URL)
+			validURLs = append(validURLs, aURL)
 		}
 	}
 	return validURLs
 }
 
-func NewLoadBalancerRedirectorService(repository event.Repository) *LoadBalancerRedirectorService {
-	return &LoadBalancerRedirectorService{repository: repository}
+func NewLoadBalancerRedirectorService(repository event.Repository, clock event.Clock, strategies Strategies) *LoadBalancerRedirectorService {
+	return &LoadBalancerRedirectorService{
+		repository: repository,
+		clock:      clock,
+		strategies: strategies,
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
@@ -11,14 +12,19 @@ import (
 
 	eventmocks "github.com/WebEngineeringGroupI/backend/pkg/domain/event/mocks"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/redirect"
+	redirectmocks "github.com/WebEngineeringGroupI/backend/pkg/domain/redirect/mocks"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/inmemory"
 )
 
+var aVisitor = redirect.Visitor{IP: "192.0.2.10"}
+
 var _ = Describe("Domain / Redirect / LoadBalancerService", func() {
 	var (
 		ctx                   context.Context
 		ctrl                  *gomock.Controller
 		repository            *eventmocks.MockRepository
+		clock                 *eventmocks.MockClock
 		multipleURLRedirector *redirect.LoadBalancerRedirectorService
 	)
 
@@ -26,7 +32,9 @@ var _ = Describe("Domain / Redirect / LoadBalancerService", func() {
 		ctx = context.Background()
 		ctrl = gomock.NewController(GinkgoT())
 		repository = eventmocks.NewMockRepository(ctrl)
-		multipleURLRedirector = redirect.NewLoadBalancerRedirectorService(repository)
+		clock = eventmocks.NewMockClock(ctrl)
+		multipleURLRedirector = redirect.NewLoadBalancerRedirectorService(repository, clock, redirect.NewStrategies(inmemory.NewCounterStore()))
+		clock.EXPECT().Now().AnyTimes().Return(time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC))
 		rand.Seed(GinkgoRandomSeed())
 	})
 
@@ -49,7 +57,7 @@ var _ = Describe("Domain / Redirect / LoadBalancerService", func() {
 					},
 				}}, 1, nil)
 
-			longURL, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash")
+			longURL, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(longURL).To(Equal("https://google.es"))
@@ -70,21 +78,53 @@ var _ = Describe("Domain / Redirect / LoadBalancerService", func() {
 							IsValid: true,
 						},
 					}}, 3, nil)
-				_, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash")
+				_, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
 
 				Expect(err).ToNot(HaveOccurred())
 				Eventually(func() string {
-					longURL, _ := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash")
+					longURL, _ := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
 					return longURL
 				}).Should(Equal("https://google.es"))
 				Eventually(func() string {
-					longURL, _ := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash")
+					longURL, _ := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
 					return longURL
 				}).Should(Equal("https://youtube.com"))
 			})
 		})
 	})
 
+	When("the load-balanced URL has a strategy", func() {
+		It("chooses among the valid URLs with its implementation", func() {
+			strategy := redirectmocks.NewMockSelectionStrategy(ctrl)
+			multipleURLRedirector = redirect.NewLoadBalancerRedirectorService(repository, clock, redirect.Strategies{url.StickyStrategy: strategy})
+			link := &url.LoadBalancedURL{Hash: "someHash", Strategy: url.StickyStrategy, LongURLs: []url.OriginalURL{
+				{URL: "https://google.es", IsValid: true},
+				{URL: "https://youtube.com", IsValid: false},
+				{URL: "https://unizar.es", IsValid: true},
+			}}
+			repository.EXPECT().Load(ctx, "someHash").Return(link, 1, nil)
+			strategy.EXPECT().
+				Select(ctx, link, []url.OriginalURL{{URL: "https://google.es", IsValid: true}, {URL: "https://unizar.es", IsValid: true}}, aVisitor).
+				Return(url.OriginalURL{URL: "https://unizar.es", IsValid: true}, nil)
+
+			longURL, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(longURL).To(Equal("https://unizar.es"))
+		})
+
+		It("fails if the strategy has no implementation", func() {
+			multipleURLRedirector = redirect.NewLoadBalancerRedirectorService(repository, clock, redirect.Strategies{})
+			repository.EXPECT().Load(ctx, "someHash").Return(&url.LoadBalancedURL{Hash: "someHash", Strategy: url.RoundRobinStrategy, LongURLs: []url.OriginalURL{
+				{URL: "https://google.es", IsValid: true},
+			}}, 1, nil)
+
+			_, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
+
+			Expect(err).To(MatchError(ContainSubstring("round_robin")))
+		})
+	})
+
 	When("there are no valid URLs", func() {
 		It("returns an error", func() {
 			repository.EXPECT().
@@ -99,19 +139,51 @@ var _ = Describe("Domain / Redirect / LoadBalancerService", func() {
 						IsValid: false,
 					},
 				}}, 1, nil)
-			longURL, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash")
+			longURL, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
 
 			Expect(err).To(MatchError(url.ErrValidURLNotFound))
 			Expect(longURL).To(BeEmpty())
 		})
 	})
 
+	When("the load-balanced URL is expired", func() {
+		It("returns an error saying it's expired", func() {
+			repository.EXPECT().
+				Load(ctx, "someHash").
+				Return(&url.LoadBalancedURL{
+					Hash:      "someHash",
+					LongURLs:  []url.OriginalURL{{URL: "https://google.es", IsValid: true}},
+					ExpiresAt: time.Date(2021, 12, 1, 9, 0, 0, 0, time.UTC),
+				}, 2, nil)
+			longURL, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
+
+			Expect(err).To(MatchError(url.ErrShortURLExpired))
+			Expect(longURL).To(BeEmpty())
+		})
+	})
+
+	When("the load-balanced URL expires in the future", func() {
+		It("returns a valid URL", func() {
+			repository.EXPECT().
+				Load(ctx, "someHash").
+				Return(&url.LoadBalancedURL{
+					Hash:      "someHash",
+					LongURLs:  []url.OriginalURL{{URL: "https://google.es", IsValid: true}},
+					ExpiresAt: time.Date(2021, 12, 1, 11, 0, 0, 0, time.UTC),
+				}, 2, nil)
+			longURL, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(longURL).To(Equal("https://google.es"))
+		})
+	})
+
 	When("the repository returns an error", func() {
 		It("returns the error from the repository", func() {
 			repository.EXPECT().
 				Load(ctx, "someHash").
 				Return(nil, 0, fmt.Errorf("unknown error"))
-			longURL, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash")
+			longURL, err := multipleURLRedirector.ReturnAValidOriginalURL(ctx, "someHash", aVisitor)
 
 			Expect(err).To(MatchError("unknown error"))
 			Expect(longURL).To(BeEmpty())
@@ -4,17 +4,78 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
 )
 
+// maxClickAttempts is the number of times a click is retried when another click
+// on the same short URL has been saved concurrently.
+const maxClickAttempts = 5
+
 type Redirector struct {
 	repository event.Repository
 	clock      event.Clock
+	// botClassifier is only set when the visits of bots must not be counted as clicks
+	botClassifier click.UserAgentClassifier
+	// lostClicks is only set when the clicks that couldn't be saved must be counted
+	lostClicks LostClickRecorder
+}
+
+// LostClickRecorder counts the clicks that couldn't be saved, whose visitors were redirected anyway
+type LostClickRecorder interface {
+	RecordLostClick()
+}
+
+// RedirectorOption configures the optional behaviour of a Redirector created through NewRedirector
+type RedirectorOption func(r *Redirector)
+
+// WithoutBotClicks makes the Redirector leave the visits of the user agents classified as bots,
+// like crawlers and link previewers, out of the clicks of the short URLs. They are still redirected.
+func WithoutBotClicks(classifier click.UserAgentClassifier) RedirectorOption {
+	return func(r *Redirector) {
+		r.botClassifier = classifier
+	}
+}
+
+// WithLostClickRecorder makes the Redirector count in the recorder the clicks that couldn't be saved
+func WithLostClickRecorder(recorder LostClickRecorder) RedirectorOption {
+	return func(r *Redirector) {
+		r.lostClicks = recorder
+	}
+}
+
+// ReturnOriginalURL returns the URL the hash redirects to, counting the visit of the userAgent as a click.
+// The visitor is redirected even if the click can't be saved, the click is only logged as lost.
+func (r *Redirector) ReturnOriginalURL(ctx context.Context, hash string, userAgent string) (string, error) {
+	countClick := r.botClassifier == nil || !r.botClassifier.Classify(userAgent).IsBot
+
+	var originalURL string
+	err := event.RetryOnConflict(ctx, maxClickAttempts, func(ctx context.Context) error {
+		var err error
+		originalURL, err = r.clickShortURL(ctx, hash, countClick)
+		return err
+	})
+	if err != nil && originalURL != "" {
+		log.Printf("the click on the short url %s is lost: %s", hash, err)
+		if r.lostClicks != nil {
+			r.lostClicks.RecordLostClick()
+		}
+		return originalURL, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return originalURL, nil
 }
 
-func (r *Redirector) ReturnOriginalURL(ctx context.Context, hash string) (string, error) {
+// clickShortURL returns the URL the hash redirects to, saving the click if countClick is set.
+// The URL is returned along with the error when only the click couldn't be saved.
+
+func (r *Redirector) clickShortURL(ctx context.Context, hash string, countClick bool) (string, error) {
 	shortURLEntity, version, err := r.repository.Load(ctx, hash)
 	if errors.Is(err, event.ErrEntityNotFound) {
 		return "", url.ErrShortURLNotFound
@@ -28,27 +89,50 @@ func (r *Redirector) ReturnOriginalURL(ctx context.Context, hash string) (string
 		return "", event.ErrEntityNotFound
 	}
 
+	if shortURL.Deleted {
+		return "", url.ErrShortURLNotFound
+	}
+
+	if shortURL.IsExpired(r.clock.Now()) {
+		return "", url.ErrShortURLExpired
+	}
+
+	if shortURL.Disabled {
+		return "", url.ErrShortURLDisabled
+	}
+
 	if !shortURL.OriginalURL.IsValid {
 		return "", fmt.Errorf("the url '%s' is marked as invalid", shortURL.OriginalURL.URL)
 	}
 
-	err = r.repository.Save(ctx, &url.ShortURLClicked{
+	if !countClick {
+		return shortURL.OriginalURL.URL, nil
+	}
+
+	err = r.repository.Save(ctx, version, &url.ShortURLClicked{
 		Base: event.Base{
 			ID:      shortURL.Hash,
 			Version: version + 1,
 			At:      r.clock.Now(),
 		},
 	})
+	if errors.Is(err, event.ErrConcurrencyConflict) {
+		return shortURL.OriginalURL.URL, err
+	}
 	if err != nil {
-		return "", fmt.Errorf("error saving url clicked event in the repository")
+		return shortURL.OriginalURL.URL, fmt.Errorf("error saving url clicked event in the repository: %w", err)
 	}
 
 	return shortURL.OriginalURL.URL, nil
 }
 
-func NewRedirector(repository event.Repository, clock event.Clock) *Redirector {
-	return &Redirector{
+func NewRedirector(repository event.Repository, clock event.Clock, options ...RedirectorOption) *Redirector {
+	r := &Redirector{
 		repository: repository,
 		clock:      clock,
 	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
 }
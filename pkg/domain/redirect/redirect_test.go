@@ -2,12 +2,15 @@ package redirect_test
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	clickmocks "github.com/WebEngineeringGroupI/backend/pkg/domain/click/mocks"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 	domainmocks "github.com/WebEngineeringGroupI/backend/pkg/domain/event/mocks"
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/redirect"
@@ -45,7 +48,7 @@ var _ = Describe("Redirect", func() {
 				},
 				Clicks: 2,
 			}, 3, nil)
-			repository.EXPECT().Save(ctx, &url.ShortURLClicked{
+			repository.EXPECT().Save(ctx, 3, &url.ShortURLClicked{
 				Base: event.Base{
 					ID:      "foobar",
 					Version: 4,
@@ -53,7 +56,7 @@ var _ = Describe("Redirect", func() {
 				},
 			})
 
-			originalURL, err := redirector.ReturnOriginalURL(ctx, "foobar")
+			originalURL, err := redirector.ReturnOriginalURL(ctx, "foobar", "Mozilla/5.0")
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(originalURL).To(Equal("http://google.com"))
@@ -68,7 +71,7 @@ var _ = Describe("Redirect", func() {
 				},
 				Clicks: 1,
 			}, 6, nil)
-			repository.EXPECT().Save(ctx, &url.ShortURLClicked{
+			repository.EXPECT().Save(ctx, 6, &url.ShortURLClicked{
 				Base: event.Base{
 					ID:      "foobar",
 					Version: 7,
@@ -76,7 +79,110 @@ var _ = Describe("Redirect", func() {
 				},
 			})
 
-			originalURL, err := redirector.ReturnOriginalURL(ctx, "foobar")
+			originalURL, err := redirector.ReturnOriginalURL(ctx, "foobar", "Mozilla/5.0")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(originalURL).To(Equal("https://google.com"))
+		})
+	})
+
+	Context("when another click is saved concurrently for the same hash", func() {
+		It("reloads the short URL and saves the click again", func() {
+			gomock.InOrder(
+				repository.EXPECT().Load(ctx, "foobar").Return(&url.ShortURL{
+					Hash:        "foobar",
+					OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+					Clicks:      1,
+				}, 2, nil),
+				repository.EXPECT().Save(ctx, 2, gomock.Any()).Return(event.ErrConcurrencyConflict),
+				repository.EXPECT().Load(ctx, "foobar").Return(&url.ShortURL{
+					Hash:        "foobar",
+					OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+					Clicks:      2,
+				}, 3, nil),
+				repository.EXPECT().Save(ctx, 3, &url.ShortURLClicked{
+					Base: event.Base{
+						ID:      "foobar",
+						Version: 4,
+						At:      time.Time{},
+					},
+				}),
+			)
+
+			originalURL, err := redirector.ReturnOriginalURL(ctx, "foobar", "Mozilla/5.0")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(originalURL).To(Equal("https://google.com"))
+		})
+
+		It("redirects anyway, counting the click as lost, if it can't be saved after every attempt", func() {
+			lostClicks := &FakeLostClickRecorder{}
+			redirector = redirect.NewRedirector(repository, clock, redirect.WithLostClickRecorder(lostClicks))
+			repository.EXPECT().Load(ctx, "foobar").Times(5).Return(&url.ShortURL{
+				Hash:        "foobar",
+				OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+			}, 2, nil)
+			repository.EXPECT().Save(ctx, 2, gomock.Any()).Times(5).Return(event.ErrConcurrencyConflict)
+
+			originalURL, err := redirector.ReturnOriginalURL(ctx, "foobar", "Mozilla/5.0")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(originalURL).To(Equal("https://google.com"))
+			Expect(lostClicks.lost).To(Equal(1))
+		})
+	})
+
+	Context("when the click can't be saved", func() {
+		It("redirects anyway, counting the click as lost", func() {
+			lostClicks := &FakeLostClickRecorder{}
+			redirector = redirect.NewRedirector(repository, clock, redirect.WithLostClickRecorder(lostClicks))
+			repository.EXPECT().Load(ctx, "foobar").Return(&url.ShortURL{
+				Hash:        "foobar",
+				OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+			}, 2, nil)
+			repository.EXPECT().Save(ctx, 2, gomock.Any()).Return(errors.New("connection refused"))
+
+			originalURL, err := redirector.ReturnOriginalURL(ctx, "foobar", "Mozilla/5.0")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(originalURL).To(Equal("https://google.com"))
+			Expect(lostClicks.lost).To(Equal(1))
+		})
+	})
+
+	Context("when bot clicks are excluded", func() {
+		var classifier *clickmocks.MockUserAgentClassifier
+
+		BeforeEach(func() {
+			classifier = clickmocks.NewMockUserAgentClassifier(ctrl)
+			redirector = redirect.NewRedirector(repository, clock, redirect.WithoutBotClicks(classifier))
+		})
+
+		It("redirects a bot without counting its click", func() {
+			classifier.EXPECT().Classify("Slackbot-LinkExpanding 1.0").Return(click.UserAgent{Device: click.BotDevice, IsBot: true})
+			repository.EXPECT().Load(ctx, "foobar").Return(&url.ShortURL{
+				Hash:        "foobar",
+				OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+			}, 1, nil)
+			repository.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			originalURL, err := redirector.ReturnOriginalURL(ctx, "foobar", "Slackbot-LinkExpanding 1.0")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(originalURL).To(Equal("https://google.com"))
+		})
+
+		It("counts the click of a person", func() {
+			classifier.EXPECT().Classify("Mozilla/5.0").Return(click.UserAgent{Browser: "Firefox", Device: click.Desktop})
+			repository.EXPECT().Load(ctx, "foobar").Return(&url.ShortURL{
+				Hash:        "foobar",
+				OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+			}, 1, nil)
+			repository.EXPECT().Save(ctx, 1, &url.ShortURLClicked{
+				Base: event.Base{ID: "foobar", Version: 2, At: time.Time{}},
+			})
+
+			originalURL, err := redirector.ReturnOriginalURL(ctx, "foobar", "Mozilla/5.0")
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(originalURL).To(Equal("https://google.com"))
@@ -94,19 +200,82 @@ var _ = Describe("Redirect", func() {
 				Clicks: 1,
 			}, 6, nil)
 
-			originalURL, err := redirector.ReturnOriginalURL(ctx, "12345")
+			originalURL, err := redirector.ReturnOriginalURL(ctx, "12345", "Mozilla/5.0")
 
 			Expect(err).To(MatchError("the url 'some-url' is marked as invalid"))
 			Expect(originalURL).To(BeEmpty())
 		})
 	})
 
+	Context("if the short URL is expired", func() {
+		It("returns an error saying it's expired", func() {
+			repository.EXPECT().Load(ctx, "12345").Return(&url.ShortURL{
+				Hash:        "12345",
+				OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+				ExpiresAt:   time.Time{}.Add(-time.Minute),
+			}, 2, nil)
+
+			originalURL, err := redirector.ReturnOriginalURL(ctx, "12345", "Mozilla/5.0")
+
+			Expect(err).To(MatchError(url.ErrShortURLExpired))
+			Expect(originalURL).To(BeEmpty())
+		})
+
+		It("returns an error once its expiration is recorded", func() {
+			repository.EXPECT().Load(ctx, "12345").Return(&url.ShortURL{
+				Hash:        "12345",
+				OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+				Expired:     true,
+			}, 2, nil)
+
+			_, err := redirector.ReturnOriginalURL(ctx, "12345", "Mozilla/5.0")
+
+			Expect(err).To(MatchError(url.ErrShortURLExpired))
+		})
+	})
+
+	Context("if the short URL is disabled", func() {
+		It("returns an error saying it's disabled", func() {
+			repository.EXPECT().Load(ctx, "12345").Return(&url.ShortURL{
+				Hash:        "12345",
+				OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+				Disabled:    true,
+			}, 2, nil)
+
+			_, err := redirector.ReturnOriginalURL(ctx, "12345", "Mozilla/5.0")
+
+			Expect(err).To(MatchError(url.ErrShortURLDisabled))
+		})
+	})
+
+	Context("if the short URL is deleted", func() {
+		It("returns an error saying it doesn't exist", func() {
+			repository.EXPECT().Load(ctx, "12345").Return(&url.ShortURL{
+				Hash:        "12345",
+				OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+				Deleted:     true,
+			}, 2, nil)
+
+			_, err := redirector.ReturnOriginalURL(ctx, "12345", "Mozilla/5.0")
+
+			Expect(err).To(MatchError(url.ErrShortURLNotFound))
+		})
+	})
+
 	Context("when providing a hash that doesn't exist", func() {
 		It("the return value is an error", func() {
 			repository.EXPECT().Load(ctx, "non-existing-hash").Return(nil, 0, url.ErrShortURLNotFound)
 
-			_, err := redirector.ReturnOriginalURL(ctx, "non-existing-hash")
+			_, err := redirector.ReturnOriginalURL(ctx, "non-existing-hash", "Mozilla/5.0")
 			Expect(err).To(MatchError(url.ErrShortURLNotFound))
 		})
 	})
 })
+
+type FakeLostClickRecorder struct {
+	lost int
+}
+
+func (f *FakeLostClickRecorder) RecordLostClick() {
+	f.lost++
+}
@@ -0,0 +1,130 @@
+package redirect
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+// Visitor is who is redirected by a LoadBalancedURL
+type Visitor struct {
+	IP string
+}
+
+// SelectionStrategy chooses the target a visitor of a LoadBalancedURL is redirected to.
+// The targets are the valid LongURLs of the link, there is always at least one.
+//
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+type SelectionStrategy interface {
+	Select(ctx context.Context, link *url.LoadBalancedURL, targets []url.OriginalURL, visitor Visitor) (url.OriginalURL, error)
+}
+
+// CounterStore keeps counters that can be shared by several replicas of the service
+type CounterStore interface {
+	// IncrementCounter increments the counter of the key, which starts at zero, and returns its new value
+	IncrementCounter(ctx context.Context, key string) (int64, error)
+}
+
+// Strategies are the SelectionStrategy used for each url.Strategy
+type Strategies map[url.Strategy]SelectionStrategy
+
+// NewStrategies returns an implementation of every url.Strategy. The round robin turns are kept in the counters.
+func NewStrategies(counters CounterStore) Strategies {
+	return Strategies{
+		url.RandomStrategy:     NewRandomStrategy(),
+		url.RoundRobinStrategy: NewRoundRobinStrategy(counters),
+		url.WeightedStrategy:   NewWeightedStrategy(),
+		url.StickyStrategy:     NewStickyStrategy(),
+	}
+}
+
+type randomStrategy struct{}
+
+func (s *randomStrategy) Select(ctx context.Context, link *url.LoadBalancedURL, targets []url.OriginalURL, visitor Visitor) (url.OriginalURL, error) {
+	return targets[rand.Intn(len(targets))], nil
+}
+
+// NewRandomStrategy chooses any target with the same probability
+func NewRandomStrategy() SelectionStrategy {
+	return &randomStrategy{}
+}
+
+type roundRobinStrategy struct {
+	counters CounterStore
+}
+
+func (s *roundRobinStrategy) Select(ctx context.Context, link *url.LoadBalancedURL, targets []url.OriginalURL, visitor Visitor) (url.OriginalURL, error) {
+	turn, err := s.counters.IncrementCounter(ctx, link.Hash)
+	if err != nil {
+		return url.OriginalURL{}, fmt.Errorf("unable to get the round robin turn of %s: %w", link.Hash, err)
+	}
+	return targets[(turn-1)%int64(len(targets))], nil
+}
+
+// NewRoundRobinStrategy chooses the targets in turns, counting the visits of each link in the counters
+func NewRoundRobinStrategy(counters CounterStore) SelectionStrategy {
+	return &roundRobinStrategy{counters: counters}
+}
+
+type weightedStrategy struct{}
+
+func (s *weightedStrategy) Select(ctx context.Context, link *url.LoadBalancedURL, targets []url.OriginalURL, visitor Visitor) (url.OriginalURL, error) {
+	total := 0
+	for _, target := range targets {
+		total += weightOf(target)
+	}
+
+	chosen := rand.Intn(total)
+	for _, target := range targets {
+		chosen -= weightOf(target)
+		if chosen < 0 {
+			return target, nil
+		}
+	}
+	return targets[len(targets)-1], nil
+}
+
+// weightOf returns the weight of a target, the targets without weight count as one
+func weightOf(target url.OriginalURL) int {
+	if target.Weight <= 0 {
+		return 1
+	}
+	return target.Weight
+}
+
+// NewWeightedStrategy chooses the targets randomly, proportionally to their weights
+func NewWeightedStrategy() SelectionStrategy {
+	return &weightedStrategy{}
+}
+
+type stickyStrategy struct{}
+
+// Select uses rendezvous hashing: the target with the highest score for the visitor is chosen, so a visitor
+// only moves to another target when theirs stops being valid, and only the visitors of that target move.
+func (s *stickyStrategy) Select(ctx context.Context, link *url.LoadBalancedURL, targets []url.OriginalURL, visitor Visitor) (url.OriginalURL, error) {
+	chosen := targets[0]
+	highestScore := score(visitor.IP, chosen.URL)
+	for _, target := range targets[1:] {
+		if targetScore := score(visitor.IP, target.URL); targetScore > highestScore {
+			chosen = target
+			highestScore = targetScore
+		}
+	}
+	return chosen, nil
+}
+
+func score(ip string, target string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(ip))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write([]byte(target))
+	return hash.Sum64()
+}
+
+// NewStickyStrategy always chooses the same target for the same client IP, as long as it's valid
+func NewStickyStrategy() SelectionStrategy {
+	return &stickyStrategy{}
+}
@@ -0,0 +1,141 @@
+package redirect_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/redirect"
+	redirectmocks "github.com/WebEngineeringGroupI/backend/pkg/domain/redirect/mocks"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/inmemory"
+)
+
+var _ = Describe("Domain / Redirect / Selection strategies", func() {
+	var (
+		ctx     context.Context
+		link    *url.LoadBalancedURL
+		targets []url.OriginalURL
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		rand.Seed(GinkgoRandomSeed())
+		targets = []url.OriginalURL{
+			{URL: "https://google.es", IsValid: true},
+			{URL: "https://youtube.com", IsValid: true},
+			{URL: "https://unizar.es", IsValid: true},
+		}
+		link = &url.LoadBalancedURL{Hash: "someHash", LongURLs: targets}
+	})
+
+	selectURLs := func(strategy redirect.SelectionStrategy, times int, visitor redirect.Visitor) map[string]int {
+		selected := map[string]int{}
+		for i := 0; i < times; i++ {
+			target, err := strategy.Select(ctx, link, targets, visitor)
+			Expect(err).ToNot(HaveOccurred())
+			selected[target.URL]++
+		}
+		return selected
+	}
+
+	Context("random", func() {
+		It("chooses every target", func() {
+			selected := selectURLs(redirect.NewRandomStrategy(), 300, aVisitor)
+
+			Expect(selected).To(HaveLen(3))
+		})
+	})
+
+	Context("round robin", func() {
+		It("chooses the targets in turns", func() {
+			strategy := redirect.NewRoundRobinStrategy(inmemory.NewCounterStore())
+
+			var selected []string
+			for i := 0; i < 4; i++ {
+				target, err := strategy.Select(ctx, link, targets, aVisitor)
+				Expect(err).ToNot(HaveOccurred())
+				selected = append(selected, target.URL)
+			}
+
+			Expect(selected).To(Equal([]string{"https://google.es", "https://youtube.com", "https://unizar.es", "https://google.es"}))
+		})
+
+		It("keeps a turn for each link", func() {
+			strategy := redirect.NewRoundRobinStrategy(inmemory.NewCounterStore())
+			anotherLink := &url.LoadBalancedURL{Hash: "anotherHash", LongURLs: targets}
+
+			first, err := strategy.Select(ctx, link, targets, aVisitor)
+			Expect(err).ToNot(HaveOccurred())
+			second, err := strategy.Select(ctx, anotherLink, targets, aVisitor)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(first).To(Equal(second))
+		})
+
+		It("fails if the counters fail", func() {
+			counters := redirectmocks.NewMockCounterStore(gomock.NewController(GinkgoT()))
+			counters.EXPECT().IncrementCounter(ctx, "someHash").Return(int64(0), errors.New("unexpected error"))
+
+			_, err := redirect.NewRoundRobinStrategy(counters).Select(ctx, link, targets, aVisitor)
+
+			Expect(err).To(MatchError(ContainSubstring("unexpected error")))
+		})
+	})
+
+	Context("weighted", func() {
+		It("chooses the targets proportionally to their weights", func() {
+			targets = []url.OriginalURL{
+				{URL: "https://google.es", IsValid: true, Weight: 1},
+				{URL: "https://youtube.com", IsValid: true, Weight: 9},
+			}
+
+			selected := selectURLs(redirect.NewWeightedStrategy(), 1000, aVisitor)
+
+			Expect(selected["https://youtube.com"]).To(BeNumerically("~", 900, 60))
+			Expect(selected["https://google.es"]).To(BeNumerically("~", 100, 60))
+		})
+	})
+
+	Context("sticky", func() {
+		It("always chooses the same target for the same visitor", func() {
+			selected := selectURLs(redirect.NewStickyStrategy(), 10, aVisitor)
+
+			Expect(selected).To(HaveLen(1))
+		})
+
+		It("spreads different visitors among the targets", func() {
+			strategy := redirect.NewStickyStrategy()
+
+			selected := map[string]bool{}
+			for i := 0; i < 100; i++ {
+				target, err := strategy.Select(ctx, link, targets, redirect.Visitor{IP: fmt.Sprintf("192.0.2.%d", i)})
+				Expect(err).ToNot(HaveOccurred())
+				selected[target.URL] = true
+			}
+
+			Expect(selected).To(HaveLen(3))
+		})
+
+		It("only moves the visitors of a target that stops being valid", func() {
+			strategy := redirect.NewStickyStrategy()
+			for i := 0; i < 100; i++ {
+				visitor := redirect.Visitor{IP: fmt.Sprintf("192.0.2.%d", i)}
+				before, err := strategy.Select(ctx, link, targets, visitor)
+				Expect(err).ToNot(HaveOccurred())
+
+				after, err := strategy.Select(ctx, link, targets[:2], visitor)
+				Expect(err).ToNot(HaveOccurred())
+
+				if before.URL != "https://unizar.es" {
+					Expect(after).To(Equal(before))
+				}
+			}
+		})
+	})
+})
@@ -0,0 +1,52 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidAlias      = errors.New("invalid alias")
+	ErrAliasAlreadyInUse = errors.New("alias already in use")
+)
+
+const (
+	minAliasLength = 3
+	maxAliasLength = 64
+)
+
+var aliasCharset = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// reservedAliases are the aliases that would shadow the routes of the API
+var reservedAliases = map[string]bool{
+	"api":          true,
+	"csv":          true,
+	"metrics":      true,
+	"r":            true,
+	"lb":           true,
+	"loadbalancer": true,
+	"link":         true,
+}
+
+// ShortURLOptions customizes how a ShortURL is created
+type ShortURLOptions struct {
+	// Alias is used as the hash of the ShortURL instead of deriving it from the original URL
+	Alias string
+	// Expiration is when the ShortURL stops redirecting
+	Expiration Expiration
+}
+
+func validateAlias(alias string) error {
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return fmt.Errorf("%w: the alias must have between %d and %d characters", ErrInvalidAlias, minAliasLength, maxAliasLength)
+	}
+	if !aliasCharset.MatchString(alias) {
+		return fmt.Errorf("%w: the alias can only contain letters, numbers, '-' and '_'", ErrInvalidAlias)
+	}
+	if reservedAliases[strings.ToLower(alias)] {
+		return fmt.Errorf("%w: the alias %s is reserved", ErrInvalidAlias, alias)
+	}
+	return nil
+}
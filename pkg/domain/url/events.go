@@ -1,18 +1,67 @@
 package url
 
 import (
+	"time"
+
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 )
 
 type LoadBalancedURLCreated struct {
 	event.Base
 	OriginalURLs []string
+	// Strategy is empty for the default strategy, and in the events saved before the strategies could be chosen
+	Strategy Strategy
+	// Weights are the weights of each original URL, only for the WeightedStrategy
+	Weights []int
 }
 
 // TODO(fede): There is an event that comes from the message broker, from the network, that verifies a URL, implement it
 type LoadBalancedURLVerified struct {
 	event.Base
 	VerifiedURL string
+	// Verdicts are the verdicts of the validators run, empty if the validator doesn't report them
+	Verdicts []Verdict `json:",omitempty"`
+}
+
+// LoadBalancedURLUnhealthy records that a valid original URL stopped passing the health checks
+type LoadBalancedURLUnhealthy struct {
+	event.Base
+	UnhealthyURL string
+	Reason       string
+}
+
+// LoadBalancedURLRecovered records that an unhealthy original URL passes the health checks again
+type LoadBalancedURLRecovered struct {
+	event.Base
+	RecoveredURL string
+}
+
+// LoadBalancedURLTargetAdded records a new original URL of a LoadBalancedURL, with its weight for the WeightedStrategy
+type LoadBalancedURLTargetAdded struct {
+	event.Base
+	OriginalURL string
+	Weight      int
+}
+
+type LoadBalancedURLTargetRemoved struct {
+	event.Base
+	OriginalURL string
+}
+
+// LoadBalancedURLTargetsReplaced records the new original URLs of a LoadBalancedURL, with their weights for the WeightedStrategy
+type LoadBalancedURLTargetsReplaced struct {
+	event.Base
+	OriginalURLs []string
+	Weights      []int
+}
+
+type LoadBalancedURLExpirationScheduled struct {
+	event.Base
+	ExpiresAt time.Time
+}
+
+type LoadBalancedURLExpired struct {
+	event.Base
 }
 
 type ShortURLCreated struct {
@@ -22,8 +71,49 @@ type ShortURLCreated struct {
 
 type ShortURLVerified struct {
 	event.Base
+	// VerifiedURL is the original URL verified, it's empty in the events saved before the target could be changed
+	VerifiedURL string
+	// Verdicts are the verdicts of the validators run, like in LoadBalancedURLVerified
+	Verdicts []Verdict `json:",omitempty"`
+}
+
+// ShortURLRejected records that the original URL didn't pass the validator, and why
+type ShortURLRejected struct {
+	event.Base
+	RejectedURL string
+	// Validator is the name of the validator that rejected the URL, empty if it's unknown
+	Validator string
+	Reason    string
+	// Verdicts are the verdicts of the validators run, like in ShortURLVerified
+	Verdicts []Verdict `json:",omitempty"`
 }
 
 type ShortURLClicked struct {
 	event.Base
 }
+
+type ShortURLExpirationScheduled struct {
+	event.Base
+	ExpiresAt time.Time
+}
+
+type ShortURLExpired struct {
+	event.Base
+}
+
+type ShortURLDisabled struct {
+	event.Base
+}
+
+type ShortURLEnabled struct {
+	event.Base
+}
+
+type ShortURLDeleted struct {
+	event.Base
+}
+
+type ShortURLTargetChanged struct {
+	event.Base
+	OriginalURL string
+}
@@ -0,0 +1,48 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrShortURLExpired   = errors.New("short url expired")
+	ErrInvalidExpiration = errors.New("invalid expiration")
+)
+
+// Expiration defines when a link stops redirecting, either at an absolute time or after a TTL since it's created.
+// The zero value never expires.
+type Expiration struct {
+	At  time.Time
+	TTL time.Duration
+}
+
+// expiresAt returns the time when the link created at now expires, or the zero time if it never expires.
+func (e Expiration) expiresAt(now time.Time) (time.Time, error) {
+	switch {
+	case !e.At.IsZero() && e.TTL != 0:
+		return time.Time{}, fmt.Errorf("%w: only one of the expiration time or the TTL can be specified", ErrInvalidExpiration)
+	case e.TTL < 0:
+		return time.Time{}, fmt.Errorf("%w: the TTL must be positive", ErrInvalidExpiration)
+	case e.TTL > 0:
+		return now.Add(e.TTL), nil
+	case !e.At.IsZero() && !e.At.After(now):
+		return time.Time{}, fmt.Errorf("%w: the expiration time must be in the future", ErrInvalidExpiration)
+	}
+	return e.At, nil
+}
+
+// isExpired tells if a link that expires at expiresAt, or was explicitly expired, is expired at now.
+func isExpired(expiresAt time.Time, expired bool, now time.Time) bool {
+	return expired || (!expiresAt.IsZero() && !now.Before(expiresAt))
+}
+
+// hashContentWithExpiration is the content hashed for a link, so links of the same content with
+// different expirations get different hashes.
+func hashContentWithExpiration(content string, expiresAt time.Time) string {
+	if expiresAt.IsZero() {
+		return content
+	}
+	return fmt.Sprintf("%s#expires=%s", content, expiresAt.UTC().Format(time.RFC3339Nano))
+}
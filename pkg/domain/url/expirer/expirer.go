@@ -0,0 +1,178 @@
+package expirer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+// maxExpireAttempts is the number of times a link is expired again when other events
+// of the link have been saved concurrently.
+const maxExpireAttempts = 5
+
+const ProjectionName = "pending_expiration"
+
+// PendingExpiration is a link scheduled to expire that has not been expired yet
+type PendingExpiration struct {
+	ID           string
+	LoadBalanced bool
+	ExpiresAt    time.Time
+}
+
+// PendingExpirationRepository keeps the links pending to expire
+type PendingExpirationRepository interface {
+	// SavePendingExpiration saves the expiration, replacing the one already saved for the link.
+	SavePendingExpiration(ctx context.Context, expiration *PendingExpiration) error
+
+	// DeletePendingExpiration deletes the expiration of the link, if there is one.
+	DeletePendingExpiration(ctx context.Context, id string) error
+
+	// FindDueExpirations returns the expirations whose time is not after now.
+	FindDueExpirations(ctx context.Context, now time.Time) ([]*PendingExpiration, error)
+}
+
+// Service records the expiration of the links once their expiration time is reached, saving a ShortURLExpired
+// or LoadBalancedURLExpired event. The links scheduled to expire are kept in a PendingExpirationRepository by
+// a projection of the events of the store, so they are not replayed from the beginning each time the service
+// is started, and every instance of the service sees the same links.
+type Service struct {
+	runner                    *projection.Runner
+	pending                   PendingExpirationRepository
+	shortURLRepository        event.Repository
+	loadBalancedURLRepository event.Repository
+	clock                     event.Clock
+	checkInterval             time.Duration
+}
+
+// Start runs the projection of the links scheduled to expire and expires the links every checkInterval,
+// blocking until the context is cancelled.
+func (s *Service) Start(ctx context.Context) {
+	projectionDone := make(chan struct{})
+	go func() {
+		defer close(projectionDone)
+		err := s.runner.Run(ctx, s)
+		if err != nil {
+			log.Printf("unable to run the %s projection: %s", ProjectionName, err)
+		}
+	}()
+	defer func() { <-projectionDone }()
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ExpireDueLinks(ctx)
+		}
+	}
+}
+
+func (s *Service) Name() string {
+	return ProjectionName
+}
+
+// Project implements the projection.Projection interface, keeping track of the links pending to expire.
+func (s *Service) Project(ctx context.Context, evt event.Event) error {
+	switch e := evt.(type) {
+	case *url.ShortURLExpirationScheduled:
+		return s.pending.SavePendingExpiration(ctx, &PendingExpiration{ID: e.EntityID(), ExpiresAt: e.ExpiresAt})
+	case *url.LoadBalancedURLExpirationScheduled:
+		return s.pending.SavePendingExpiration(ctx, &PendingExpiration{ID: e.EntityID(), LoadBalanced: true, ExpiresAt: e.ExpiresAt})
+	case *url.ShortURLExpired, *url.LoadBalancedURLExpired:
+		return s.pending.DeletePendingExpiration(ctx, e.EntityID())
+	}
+	return nil
+}
+
+// ExpireDueLinks records the expiration of the links whose expiration time has been reached.
+// The links that can't be expired yet are tried again on the next call.
+func (s *Service) ExpireDueLinks(ctx context.Context) {
+	due, err := s.pending.FindDueExpirations(ctx, s.clock.Now())
+	if err != nil {
+		log.Printf("unable to find the links to expire: %s", err)
+		return
+	}
+
+	for _, pending := range due {
+		repository := s.shortURLRepository
+		if pending.LoadBalanced {
+			repository = s.loadBalancedURLRepository
+		}
+		var expired bool
+		err = event.RetryOnConflict(ctx, maxExpireAttempts, func(ctx context.Context) error {
+			var err error
+			expired, err = s.expire(ctx, repository, pending.ID)
+			return err
+		})
+		if err != nil {
+			log.Printf("unable to expire the link %s: %s", pending.ID, err)
+			continue
+		}
+		if !expired {
+			continue
+		}
+		err = s.pending.DeletePendingExpiration(ctx, pending.ID)
+		if err != nil {
+			log.Printf("unable to delete the pending expiration of the link %s: %s", pending.ID, err)
+		}
+	}
+}
+
+// expire returns if the link is expired, either now or before. The links whose expiration time has not been
+// reached yet are not expired, so they are tried again on the next call.
+func (s *Service) expire(ctx context.Context, repository event.Repository, id string) (bool, error) {
+	entity, version, err := repository.Load(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("unable to load the link: %w", err)
+	}
+
+	now := s.clock.Now()
+	base := event.Base{ID: id, Version: version + 1, At: now}
+	var expired event.Event
+	switch link := entity.(type) {
+	case *url.ShortURL:
+		if link.Expired {
+			return true, nil
+		}
+		if !link.IsExpired(now) {
+			return false, nil
+		}
+		expired = &url.ShortURLExpired{Base: base}
+	case *url.LoadBalancedURL:
+		if link.Expired {
+			return true, nil
+		}
+		if !link.IsExpired(now) {
+			return false, nil
+		}
+		expired = &url.LoadBalancedURLExpired{Base: base}
+	default:
+		return false, fmt.Errorf("unknown entity type loaded while expiring the link: %T", entity)
+	}
+
+	err = repository.Save(ctx, version, expired)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// NewService creates a Service that runs the projection of the links scheduled to expire with the runner,
+// saving them in the pending repository.
+func NewService(runner *projection.Runner, pending PendingExpirationRepository, shortURLRepository, loadBalancedURLRepository event.Repository, clock event.Clock, checkInterval time.Duration) *Service {
+	return &Service{
+		runner:                    runner,
+		pending:                   pending,
+		shortURLRepository:        shortURLRepository,
+		loadBalancedURLRepository: loadBalancedURLRepository,
+		clock:                     clock,
+		checkInterval:             checkInterval,
+	}
+}
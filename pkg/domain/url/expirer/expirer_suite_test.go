@@ -0,0 +1,13 @@
+package expirer_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestExpirer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Expirer Suite")
+}
@@ -0,0 +1,188 @@
+package expirer_test
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/expirer"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/inmemory"
+	eventstore "github.com/WebEngineeringGroupI/backend/pkg/infrastructure/eventstore/inmemory"
+)
+
+var _ = Describe("Domain / URL / Expirer", func() {
+	var (
+		ctx                       context.Context
+		cancel                    context.CancelFunc
+		store                     *eventstore.EventStore
+		broker                    event.Broker
+		checkpoints               *inmemory.CheckpointStore
+		pending                   *inmemory.PendingExpirationRepository
+		shortURLRepository        event.Repository
+		loadBalancedURLRepository event.Repository
+		clock                     *FakeClock
+		service                   *expirer.Service
+		createdAt                 time.Time
+	)
+
+	newService := func() *expirer.Service {
+		runner := projection.NewRunner(store, broker, checkpoints, 10, 10*time.Millisecond)
+		return expirer.NewService(runner, pending, shortURLRepository, loadBalancedURLRepository, clock, 10*time.Millisecond)
+	}
+
+	BeforeEach(func() {
+		log.Default().SetOutput(GinkgoWriter)
+		ctx, cancel = context.WithCancel(context.Background())
+		store = eventstore.NewEventStore()
+		broker = event.NewBroker()
+		checkpoints = inmemory.NewCheckpointStore()
+		pending = inmemory.NewPendingExpirationRepository()
+		shortURLRepository = event.NewRepository(&url.ShortURL{}, store, broker)
+		loadBalancedURLRepository = event.NewRepository(&url.LoadBalancedURL{}, store, broker)
+		createdAt = time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+		clock = &FakeClock{now: createdAt}
+		service = newService()
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	loadShortURL := func() *url.ShortURL {
+		entity, _, err := shortURLRepository.Load(ctx, "launch-2026")
+		Expect(err).ToNot(HaveOccurred())
+		return entity.(*url.ShortURL)
+	}
+
+	createShortURL := func(ttl time.Duration) {
+		shortener := url.NewSingleURLShortener(shortURLRepository, clock, &FakeMetrics{}, url.NewURLSafeHashGenerator())
+		_, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{
+			Alias:      "launch-2026",
+			Expiration: url.Expiration{TTL: ttl},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("expires the short URLs once their expiration time is reached", func() {
+		createShortURL(time.Hour)
+		go service.Start(ctx)
+
+		Consistently(func() bool { return loadShortURL().Expired }, 50*time.Millisecond).Should(BeFalse())
+
+		clock.Set(createdAt.Add(time.Hour))
+		Eventually(func() bool { return loadShortURL().Expired }).Should(BeTrue())
+	})
+
+	It("expires the load balanced URLs once their expiration time is reached", func() {
+		loadBalancer := url.NewLoadBalancer(loadBalancedURLRepository, clock, url.NewURLSafeHashGenerator())
+		loadBalancedURL, err := loadBalancer.ShortURLsWithOptions(ctx, []string{"https://google.com"}, url.LoadBalancedURLOptions{
+			Expiration: url.Expiration{TTL: time.Minute},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		go service.Start(ctx)
+
+		clock.Set(createdAt.Add(2 * time.Minute))
+		Eventually(func() bool {
+			entity, _, err := loadBalancedURLRepository.Load(ctx, loadBalancedURL.Hash)
+			Expect(err).ToNot(HaveOccurred())
+			return entity.(*url.LoadBalancedURL).Expired
+		}).Should(BeTrue())
+	})
+
+	It("expires each link only once", func() {
+		createShortURL(time.Hour)
+		clock.Set(createdAt.Add(time.Hour))
+		go service.Start(ctx)
+		Eventually(func() bool { return loadShortURL().Expired }).Should(BeTrue())
+
+		go newService().Start(ctx)
+
+		Consistently(func() (int, error) {
+			_, version, err := shortURLRepository.Load(ctx, "launch-2026")
+			return version, err
+		}, 100*time.Millisecond).Should(Equal(2))
+	})
+
+	It("expires the links projected before restarting, resuming from the checkpoint", func() {
+		createShortURL(time.Hour)
+		firstCtx, stopFirst := context.WithCancel(ctx)
+		firstStopped := make(chan struct{})
+		go func() {
+			defer close(firstStopped)
+			service.Start(firstCtx)
+		}()
+		Eventually(func() ([]*expirer.PendingExpiration, error) {
+			return pending.FindDueExpirations(ctx, createdAt.Add(time.Hour))
+		}).Should(HaveLen(1))
+		Eventually(func() (int64, error) { return checkpoints.LoadCheckpoint(ctx, expirer.ProjectionName) }).ShouldNot(BeZero())
+		stopFirst()
+		<-firstStopped
+
+		go newService().Start(ctx)
+
+		clock.Set(createdAt.Add(time.Hour))
+		Eventually(func() bool { return loadShortURL().Expired }).Should(BeTrue())
+		Eventually(func() ([]*expirer.PendingExpiration, error) { return pending.FindDueExpirations(ctx, clock.Now()) }).Should(BeEmpty())
+	})
+
+	It("keeps the links pending to expire if they are found due before their expiration time", func() {
+		createShortURL(time.Hour)
+		err := pending.SavePendingExpiration(ctx, &expirer.PendingExpiration{ID: "launch-2026", ExpiresAt: createdAt.Add(time.Hour - time.Second)})
+		Expect(err).ToNot(HaveOccurred())
+
+		clock.Set(createdAt.Add(time.Hour - 500*time.Millisecond))
+		service.ExpireDueLinks(ctx)
+
+		Expect(loadShortURL().Expired).To(BeFalse())
+		Expect(pending.FindDueExpirations(ctx, clock.Now())).To(HaveLen(1))
+
+		clock.Set(createdAt.Add(time.Hour))
+		service.ExpireDueLinks(ctx)
+
+		Expect(loadShortURL().Expired).To(BeTrue())
+		Expect(pending.FindDueExpirations(ctx, clock.Now())).To(BeEmpty())
+	})
+
+	It("doesn't expire the links without expiration", func() {
+		shortener := url.NewSingleURLShortener(shortURLRepository, clock, &FakeMetrics{}, url.NewURLSafeHashGenerator())
+		shortURL, err := shortener.HashFromURL(ctx, "https://google.com")
+		Expect(err).ToNot(HaveOccurred())
+		go service.Start(ctx)
+
+		clock.Set(createdAt.Add(24 * 365 * time.Hour))
+		Consistently(func() (int, error) {
+			_, version, err := shortURLRepository.Load(ctx, shortURL.Hash)
+			return version, err
+		}, 50*time.Millisecond).Should(Equal(0))
+	})
+})
+
+type FakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+func (f *FakeClock) Set(now time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.now = now
+}
+
+type FakeMetrics struct{}
+
+func (f *FakeMetrics) RecordSingleURLMetrics() {}
+
+func (f *FakeMetrics) RecordFileURLMetrics() {}
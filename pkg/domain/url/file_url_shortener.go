@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 )
@@ -16,10 +17,12 @@ type Formatter interface {
 }
 
 type FileURLShortener struct {
-	repository event.Repository
-	formatter  Formatter
-	metrics    Metrics
-	clock      event.Clock
+	repository    event.Repository
+	formatter     Formatter
+	metrics       Metrics
+	clock         event.Clock
+	hashGenerator HashGenerator
+	normalizer    *Normalizer
 }
 
 func (s *FileURLShortener) HashesFromURLData(ctx context.Context, data []byte) ([]ShortURL, error) {
@@ -31,33 +34,69 @@ func (s *FileURLShortener) HashesFromURLData(ctx context.Context, data []byte) (
 		return nil, err
 	}
 
+	// all the URLs are checked before shortening any of them, so none is shortened if the file has invalid ones
+	for i, longURL := range longURLs {
+		longURLs[i] = s.normalizer.Normalize(longURL)
+	}
+	err = checkLongURLs(longURLs)
+	if err != nil {
+		return nil, err
+	}
+
 	shortURLs = make([]ShortURL, 0, len(longURLs))
 	for _, longURL := range longURLs {
-		events := []event.Event{
-			&ShortURLCreated{
-				Base: event.Base{
-					ID:      hashFromURL(longURL),
-					Version: 0,
-					At:      s.clock.Now(),
-				},
-				OriginalURL: longURL,
-			},
-		}
-		shortURLs = append(shortURLs, *shortURLFromEvents(events...))
-		err = s.repository.Save(ctx, events...)
+		var shortURL *ShortURL
+		err = event.RetryOnConflict(ctx, maxHashAttempts, func(ctx context.Context) error {
+			var err error
+			shortURL, err = s.shortURL(ctx, longURL)
+			return err
+		})
 		if err != nil {
-			return nil, fmt.Errorf("unable to save events to repository: %w", err)
+			return nil, err
 		}
+		shortURLs = append(shortURLs, *shortURL)
 	}
 
 	return shortURLs, nil
 }
 
-func NewFileURLShortener(repository event.Repository, metrics Metrics, clock event.Clock, formatter Formatter) *FileURLShortener {
+func (s *FileURLShortener) shortURL(ctx context.Context, longURL string) (*ShortURL, error) {
+	hash, entity, err := findHash(ctx, s.repository, s.hashGenerator, longURL, isShortURLOf(longURL, time.Time{}))
+	if err != nil {
+		return nil, err
+	}
+	if entity != nil {
+		shortURL, ok := entity.(*ShortURL)
+		if !ok {
+			return nil, fmt.Errorf("unknown entity returned while hashing from URL: %T", entity)
+		}
+		return shortURL, nil
+	}
+
+	events := []event.Event{
+		&ShortURLCreated{
+			Base: event.Base{
+				ID:      hash,
+				Version: 0,
+				At:      s.clock.Now(),
+			},
+			OriginalURL: longURL,
+		},
+	}
+	err = s.repository.Save(ctx, event.NewStreamVersion, events...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to save events to repository: %w", err)
+	}
+	return shortURLFromEvents(events...), nil
+}
+
+func NewFileURLShortener(repository event.Repository, metrics Metrics, clock event.Clock, formatter Formatter, hashGenerator HashGenerator, options ...ShortenerOption) *FileURLShortener {
 	return &FileURLShortener{
-		repository: repository,
-		formatter:  formatter,
-		metrics:    metrics,
-		clock:      clock,
+		repository:    repository,
+		formatter:     formatter,
+		metrics:       metrics,
+		clock:         clock,
+		hashGenerator: hashGenerator,
+		normalizer:    newShortenerOptions(options).normalizer,
 	}
 }
@@ -35,7 +35,7 @@ var _ = Describe("Multiple URL Shortener", func() {
 		clock = domainmocks.NewMockClock(ctrl)
 		repository = domainmocks.NewMockRepository(ctrl)
 
-		shortener = url.NewFileURLShortener(repository, metrics, clock, formatter)
+		shortener = url.NewFileURLShortener(repository, metrics, clock, formatter, url.NewURLSafeHashGenerator())
 
 		clock.EXPECT().Now().AnyTimes().Return(time.Time{})
 	})
@@ -43,76 +43,133 @@ var _ = Describe("Multiple URL Shortener", func() {
 		ctrl.Finish()
 	})
 
+	Context("when providing long URLs that are the same once normalized", func() {
+		It("generates the same hash for them", func() {
+			formatter.EXPECT().FormatDataToURLs(gomock.Any()).Return([]string{"https://Google.com/", "https://google.com:443"}, nil)
+			metrics.EXPECT().RecordFileURLMetrics()
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, event.ErrEntityNotFound).Times(2)
+			repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).Times(2)
+
+			shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shortURLs[0]).To(Equal(shortURLs[1]))
+			Expect(shortURLs[0].OriginalURL.URL).To(Equal("https://google.com"))
+		})
+	})
+
 	Context("when providing multiple long URLs", func() {
 		BeforeEach(func() {
 			formatter.EXPECT().FormatDataToURLs(gomock.Any()).Return(aLongURLSet(), nil)
 		})
 
-		It("generates a hash for each one", func() {
-			metrics.EXPECT().RecordFileURLMetrics().Times(1)
-			repository.EXPECT().Save(ctx, gomock.Any()).AnyTimes()
-			shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
+		When("they are new", func() {
+			BeforeEach(func() {
+				repository.EXPECT().Load(ctx, gomock.Any()).Return(nil, 0, event.ErrEntityNotFound).AnyTimes()
+			})
 
-			Expect(err).ToNot(HaveOccurred())
-			Expect(shortURLs).To(HaveLen(2))
-			Expect(shortURLs[0].Hash).To(HaveLen(8))
-			Expect(shortURLs[1].Hash).To(HaveLen(8))
-		})
+			It("generates a hash for each one", func() {
+				metrics.EXPECT().RecordFileURLMetrics().Times(1)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).AnyTimes()
+				shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
 
-		It("contains the real values from the original URLs", func() {
-			metrics.EXPECT().RecordFileURLMetrics().Times(1)
-			repository.EXPECT().Save(ctx, gomock.Any()).AnyTimes()
-			shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURLs).To(HaveLen(2))
+				Expect(shortURLs[0].Hash).To(HaveLen(8))
+				Expect(shortURLs[1].Hash).To(HaveLen(8))
+			})
 
-			Expect(err).ToNot(HaveOccurred())
-			Expect(shortURLs[0].OriginalURL.URL).To(Equal("https://google.com"))
-			Expect(shortURLs[1].OriginalURL.URL).To(Equal("https://unizar.es"))
-		})
+			It("contains the real values from the original URLs", func() {
+				metrics.EXPECT().RecordFileURLMetrics().Times(1)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).AnyTimes()
+				shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
 
-		It("saves the URLs as not verified", func() {
-			metrics.EXPECT().RecordFileURLMetrics().Times(1)
-			repository.EXPECT().Save(ctx, gomock.Any()).AnyTimes()
-			shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURLs[0].OriginalURL.URL).To(Equal("https://google.com"))
+				Expect(shortURLs[1].OriginalURL.URL).To(Equal("https://unizar.es"))
+			})
 
-			Expect(err).ToNot(HaveOccurred())
-			Expect(shortURLs[0].OriginalURL.IsValid).To(BeFalse())
-			Expect(shortURLs[1].OriginalURL.IsValid).To(BeFalse())
-		})
+			It("saves the URLs as not verified", func() {
+				metrics.EXPECT().RecordFileURLMetrics().Times(1)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).AnyTimes()
+				shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
 
-		It("generates different short URL hashes for each of the long URLs", func() {
-			metrics.EXPECT().RecordFileURLMetrics().Times(1)
-			repository.EXPECT().Save(ctx, gomock.Any()).AnyTimes()
-			shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURLs[0].OriginalURL.IsValid).To(BeFalse())
+				Expect(shortURLs[1].OriginalURL.IsValid).To(BeFalse())
+			})
 
-			Expect(err).ToNot(HaveOccurred())
-			Expect(shortURLs[0].Hash).ToNot(Equal(shortURLs[1].Hash))
-		})
+			It("generates different short URL hashes for each of the long URLs", func() {
+				metrics.EXPECT().RecordFileURLMetrics().Times(1)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).AnyTimes()
+				shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
 
-		It("stores the short URL in a repository", func() {
-			metrics.EXPECT().RecordFileURLMetrics().Times(1)
-			repository.EXPECT().Save(ctx, []event.Event{
-				&url.ShortURLCreated{
-					Base: event.Base{
-						ID:      "cv6VxVdu",
-						Version: 0,
-						At:      time.Time{},
-					},
-					OriginalURL: "https://google.com",
-				},
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURLs[0].Hash).ToNot(Equal(shortURLs[1].Hash))
 			})
-			repository.EXPECT().Save(ctx, []event.Event{
-				&url.ShortURLCreated{
-					Base: event.Base{
-						ID:      "2sMi6l0Z",
-						Version: 0,
-						At:      time.Time{},
+
+			It("stores the short URL in a repository", func() {
+				metrics.EXPECT().RecordFileURLMetrics().Times(1)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, []event.Event{
+					&url.ShortURLCreated{
+						Base: event.Base{
+							ID:      "cv6VxVdu",
+							Version: 0,
+							At:      time.Time{},
+						},
+						OriginalURL: "https://google.com",
+					},
+				})
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, []event.Event{
+					&url.ShortURLCreated{
+						Base: event.Base{
+							ID:      "2sMi6l0Z",
+							Version: 0,
+							At:      time.Time{},
+						},
+						OriginalURL: "https://unizar.es",
 					},
-					OriginalURL: "https://unizar.es",
-				},
+				})
+
+				_, err := shortener.HashesFromURLData(ctx, aLongURLData())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("one of them already exists", func() {
+			It("returns the existing short URL without saving it again", func() {
+				metrics.EXPECT().RecordFileURLMetrics()
+				repository.EXPECT().Load(ctx, "cv6VxVdu").Return(&url.ShortURL{
+					Hash:        "cv6VxVdu",
+					OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+				}, 1, nil)
+				repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any())
+
+				shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURLs[0].OriginalURL.IsValid).To(BeTrue())
+				Expect(shortURLs[1].Hash).To(Equal("2sMi6l0Z"))
 			})
+		})
 
-			_, err := shortener.HashesFromURLData(ctx, aLongURLData())
-			Expect(err).ToNot(HaveOccurred())
+		When("the hash of one of them is used by another URL", func() {
+			It("generates a longer hash", func() {
+				metrics.EXPECT().RecordFileURLMetrics()
+				repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(&url.ShortURL{
+					Hash:        "2sMi6l0Z",
+					OriginalURL: url.OriginalURL{URL: "https://another.url"},
+				}, 0, nil)
+				repository.EXPECT().Load(ctx, "2sMi6l0Zq").Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).Times(2)
+
+				shortURLs, err := shortener.HashesFromURLData(ctx, aLongURLData())
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURLs[1].Hash).To(Equal("2sMi6l0Zq"))
+			})
 		})
 	})
 
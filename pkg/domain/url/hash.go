@@ -0,0 +1,69 @@
+package url
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+var (
+	ErrHashCollision = errors.New("hash collision")
+)
+
+// maxHashAttempts is the number of hashes generated for a content before giving up
+// because all of them are used by other contents.
+const maxHashAttempts = 5
+
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+type HashGenerator interface {
+	// Hash returns the hash of the content for the attempt. It's called again with the next attempt when the hash
+	// is already used by another content, so it must return a different hash for each attempt.
+	Hash(content string, attempt int) string
+}
+
+const defaultHashLength = 8
+
+// URLSafeHashGenerator generates the hashes encoding the SHA-1 of the content with the URL-safe base64 alphabet,
+// so they can be used in a path. On each attempt the hash is one character longer, and once the whole SHA-1
+// has been used the content is salted with the attempt.
+type URLSafeHashGenerator struct{}
+
+func (u *URLSafeHashGenerator) Hash(content string, attempt int) string {
+	sum := urlSafeSHA1(content)
+	if length := defaultHashLength + attempt; length <= len(sum) {
+		return sum[:length]
+	}
+	return urlSafeSHA1(fmt.Sprintf("%s#%d", content, attempt))[:defaultHashLength]
+}
+
+func urlSafeSHA1(content string) string {
+	bytes := sha1.Sum([]byte(content))
+	return base64.RawURLEncoding.EncodeToString(bytes[:])
+}
+
+func NewURLSafeHashGenerator() *URLSafeHashGenerator {
+	return &URLSafeHashGenerator{}
+}
+
+// findHash looks for the first hash of the content that is not used by an entity with another content.
+// If the content was already saved with the hash, its entity is returned too.
+func findHash(ctx context.Context, repository event.Repository, hashGenerator HashGenerator, content string, hasSameContent func(entity event.Entity) bool) (string, event.Entity, error) {
+	for attempt := 0; attempt < maxHashAttempts; attempt++ {
+		hash := hashGenerator.Hash(content, attempt)
+		entity, _, err := repository.Load(ctx, hash)
+		if errors.Is(err, event.ErrEntityNotFound) {
+			return hash, nil, nil
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("unable to check if the hash %s is in use: %w", hash, err)
+		}
+		if hasSameContent(entity) {
+			return hash, entity, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%w: the %d hashes generated for %s are already in use", ErrHashCollision, maxHashAttempts, content)
+}
@@ -0,0 +1,43 @@
+package url_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+var _ = Describe("Domain / URL / Hash Generator", func() {
+	var hashGenerator *url.URLSafeHashGenerator
+
+	BeforeEach(func() {
+		hashGenerator = url.NewURLSafeHashGenerator()
+	})
+
+	It("generates a hash of 8 characters", func() {
+		Expect(hashGenerator.Hash("https://google.es", 0)).To(Equal("lxqrJ9xF"))
+	})
+
+	It("only uses characters that are safe in a URL path", func() {
+		Expect(hashGenerator.Hash("youtube.com", 0)).To(Equal("1-IiyNe6"))
+		for _, content := range []string{"google.com", "youtube.com", "https://unizar.es", "https://google.es"} {
+			for attempt := 0; attempt < 30; attempt++ {
+				Expect(hashGenerator.Hash(content, attempt)).To(MatchRegexp(`^[a-zA-Z0-9_-]+$`))
+			}
+		}
+	})
+
+	It("lengthens the hash on each attempt", func() {
+		Expect(hashGenerator.Hash("https://google.es", 1)).To(HavePrefix("lxqrJ9xF"))
+		Expect(hashGenerator.Hash("https://google.es", 1)).To(HaveLen(9))
+		Expect(hashGenerator.Hash("https://google.es", 2)).To(HaveLen(10))
+	})
+
+	It("generates different hashes once the whole digest is used", func() {
+		hashes := map[string]bool{}
+		for attempt := 0; attempt < 30; attempt++ {
+			hashes[hashGenerator.Hash("https://google.es", attempt)] = true
+		}
+		Expect(hashes).To(HaveLen(30))
+	})
+})
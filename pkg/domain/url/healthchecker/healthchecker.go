@@ -0,0 +1,213 @@
+package healthchecker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+// maxRecordAttempts is the number of times the health of a link is recorded again when other events
+// of the link have been saved concurrently.
+const maxRecordAttempts = 5
+
+// maxConcurrentProbes is the number of targets of a link probed at once
+const maxConcurrentProbes = 10
+
+const ProjectionName = "monitored_link"
+
+// MonitoredLinkRepository keeps the load balanced URLs whose targets are checked
+type MonitoredLinkRepository interface {
+	// SaveMonitoredLink saves the link, doing nothing if it is already saved.
+	SaveMonitoredLink(ctx context.Context, id string) error
+
+	// DeleteMonitoredLink deletes the link, if it is saved.
+	DeleteMonitoredLink(ctx context.Context, id string) error
+
+	// FindMonitoredLinks returns the ids of every link saved.
+	FindMonitoredLinks(ctx context.Context) ([]string, error)
+}
+
+// Service checks the health of the targets of the load balanced URLs every checkInterval, saving a
+// LoadBalancedURLUnhealthy event when a valid target stops passing the validator, and a LoadBalancedURLRecovered
+// event when it passes it again. The links to check are kept in a MonitoredLinkRepository by a projection of
+// the events of the store, so they are not replayed from the beginning each time the service is started, and
+// every instance of the service sees the same links.
+type Service struct {
+	runner        *projection.Runner
+	monitored     MonitoredLinkRepository
+	repository    event.Repository
+	validator     url.Validator
+	clock         event.Clock
+	checkInterval time.Duration
+}
+
+// Start runs the projection of the links to check and checks them every checkInterval, blocking until the context is cancelled.
+func (s *Service) Start(ctx context.Context) {
+	projectionDone := make(chan struct{})
+	go func() {
+		defer close(projectionDone)
+		err := s.runner.Run(ctx, s)
+		if err != nil {
+			log.Printf("unable to run the %s projection: %s", ProjectionName, err)
+		}
+	}()
+	defer func() { <-projectionDone }()
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckLinks(ctx)
+		}
+	}
+}
+
+func (s *Service) Name() string {
+	return ProjectionName
+}
+
+// Project implements the projection.Projection interface, keeping track of the links to check.
+func (s *Service) Project(ctx context.Context, evt event.Event) error {
+	switch e := evt.(type) {
+	case *url.LoadBalancedURLCreated:
+		return s.monitored.SaveMonitoredLink(ctx, e.EntityID())
+	case *url.LoadBalancedURLExpired:
+		return s.monitored.DeleteMonitoredLink(ctx, e.EntityID())
+	}
+	return nil
+}
+
+// CheckLinks checks the health of the targets of every link, the links that can't be checked are tried again on the next call.
+// The health is only recorded when it changes, so the links checked by several instances at once are recorded once.
+func (s *Service) CheckLinks(ctx context.Context) {
+	ids, err := s.monitored.FindMonitoredLinks(ctx)
+	if err != nil {
+		log.Printf("unable to find the links to check: %s", err)
+		return
+	}
+
+	for _, id := range ids {
+		err := s.checkLink(ctx, id)
+		if err != nil {
+			log.Printf("unable to check the health of the link %s: %s", id, err)
+		}
+	}
+}
+
+func (s *Service) checkLink(ctx context.Context, id string) error {
+	link, _, err := s.loadLink(ctx, id)
+	if err != nil {
+		return err
+	}
+	if link.IsExpired(s.clock.Now()) {
+		return nil
+	}
+
+	var targets []string
+	for _, target := range link.LongURLs {
+		if target.IsValid || target.Unhealthy {
+			targets = append(targets, target.URL)
+		}
+	}
+	failures := s.probeAll(ctx, targets)
+
+	return event.RetryOnConflict(ctx, maxRecordAttempts, func(ctx context.Context) error {
+		return s.recordHealth(ctx, id, failures)
+	})
+}
+
+// probeAll probes the targets concurrently, up to maxConcurrentProbes at once. It returns the reason each target
+// is unhealthy, the targets that are not there are healthy.
+func (s *Service) probeAll(ctx context.Context, targets []string) map[string]string {
+	mutex := &sync.Mutex{}
+	failures := map[string]string{}
+	slots := make(chan struct{}, maxConcurrentProbes)
+	wg := &sync.WaitGroup{}
+	wg.Add(len(targets))
+	for _, target := range targets {
+		slots <- struct{}{}
+		go func(target string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			reason, healthy := s.probe(ctx, target)
+			if !healthy {
+				mutex.Lock()
+				failures[target] = reason
+				mutex.Unlock()
+			}
+		}(target)
+	}
+	wg.Wait()
+	return failures
+}
+
+// probe returns if the target passes the validator, and the reason if it doesn't
+func (s *Service) probe(ctx context.Context, target string) (string, bool) {
+	valid, err := s.validator.ValidateURLs(ctx, []string{target})
+	if err != nil {
+		return err.Error(), false
+	}
+	if !valid {
+		return "the url is not valid", false
+	}
+	return "", true
+}
+
+// recordHealth saves the changes of health of the targets, the ones that were not checked are left untouched
+func (s *Service) recordHealth(ctx context.Context, id string, failures map[string]string) error {
+	link, version, err := s.loadLink(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	var events []event.Event
+	for _, target := range link.LongURLs {
+		reason, failed := failures[target.URL]
+		base := event.Base{ID: id, Version: version + len(events) + 1, At: now}
+		switch {
+		case target.IsValid && failed:
+			events = append(events, &url.LoadBalancedURLUnhealthy{Base: base, UnhealthyURL: target.URL, Reason: reason})
+		case target.Unhealthy && !failed:
+			events = append(events, &url.LoadBalancedURLRecovered{Base: base, RecoveredURL: target.URL})
+		}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return s.repository.Save(ctx, version, events...)
+}
+
+func (s *Service) loadLink(ctx context.Context, id string) (*url.LoadBalancedURL, int, error) {
+	entity, version, err := s.repository.Load(ctx, id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load the link: %w", err)
+	}
+	link, ok := entity.(*url.LoadBalancedURL)
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown entity type loaded while checking the link: %T", entity)
+	}
+	return link, version, nil
+}
+
+// NewService creates a Service that runs the projection of the links to check with the runner,
+// saving them in the monitored repository.
+func NewService(runner *projection.Runner, monitored MonitoredLinkRepository, repository event.Repository, validator url.Validator, clock event.Clock, checkInterval time.Duration) *Service {
+	return &Service{
+		runner:        runner,
+		monitored:     monitored,
+		repository:    repository,
+		validator:     validator,
+		clock:         clock,
+		checkInterval: checkInterval,
+	}
+}
@@ -0,0 +1,256 @@
+package healthchecker_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/healthchecker"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/inmemory"
+	eventstore "github.com/WebEngineeringGroupI/backend/pkg/infrastructure/eventstore/inmemory"
+)
+
+var _ = Describe("Domain / URL / Health Checker", func() {
+	var (
+		ctx                       context.Context
+		cancel                    context.CancelFunc
+		store                     *eventstore.EventStore
+		broker                    event.Broker
+		checkpoints               *inmemory.CheckpointStore
+		monitored                 *inmemory.MonitoredLinkRepository
+		loadBalancedURLRepository event.Repository
+		validator                 *FakeValidator
+		clock                     *FakeClock
+		service                   *healthchecker.Service
+		createdAt                 time.Time
+		hash                      string
+	)
+
+	newService := func() *healthchecker.Service {
+		runner := projection.NewRunner(store, broker, checkpoints, 10, 10*time.Millisecond)
+		return healthchecker.NewService(runner, monitored, loadBalancedURLRepository, validator, clock, 10*time.Millisecond)
+	}
+
+	BeforeEach(func() {
+		log.Default().SetOutput(GinkgoWriter)
+		ctx, cancel = context.WithCancel(context.Background())
+		store = eventstore.NewEventStore()
+		broker = event.NewBroker()
+		checkpoints = inmemory.NewCheckpointStore()
+		monitored = inmemory.NewMonitoredLinkRepository()
+		loadBalancedURLRepository = event.NewRepository(&url.LoadBalancedURL{}, store, broker)
+		validator = &FakeValidator{unreachable: map[string]bool{}}
+		createdAt = time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+		clock = &FakeClock{now: createdAt}
+		service = newService()
+
+		loadBalancer := url.NewLoadBalancer(loadBalancedURLRepository, clock, url.NewURLSafeHashGenerator())
+		loadBalancedURL, err := loadBalancer.ShortURLsWithOptions(ctx, []string{"https://google.es", "https://youtube.com"}, url.LoadBalancedURLOptions{
+			Expiration: url.Expiration{TTL: time.Hour},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		hash = loadBalancedURL.Hash
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	verify := func(urls ...string) {
+		_, version, err := loadBalancedURLRepository.Load(ctx, hash)
+		Expect(err).ToNot(HaveOccurred())
+		var events []event.Event
+		for i, verifiedURL := range urls {
+			events = append(events, &url.LoadBalancedURLVerified{
+				Base:        event.Base{ID: hash, Version: version + i + 1, At: createdAt},
+				VerifiedURL: verifiedURL,
+			})
+		}
+		Expect(loadBalancedURLRepository.Save(ctx, version, events...)).To(Succeed())
+	}
+
+	targets := func() []url.OriginalURL {
+		entity, _, err := loadBalancedURLRepository.Load(ctx, hash)
+		Expect(err).ToNot(HaveOccurred())
+		return entity.(*url.LoadBalancedURL).LongURLs
+	}
+
+	It("marks the targets that stop being reachable as unhealthy, and valid again once they recover", func() {
+		verify("https://google.es", "https://youtube.com")
+		go service.Start(ctx)
+
+		validator.SetUnreachable("https://google.es", true)
+		Eventually(targets).Should(ConsistOf(
+			url.OriginalURL{URL: "https://google.es", IsValid: false, Unhealthy: true},
+			url.OriginalURL{URL: "https://youtube.com", IsValid: true},
+		))
+
+		validator.SetUnreachable("https://google.es", false)
+		Eventually(targets).Should(ConsistOf(
+			url.OriginalURL{URL: "https://google.es", IsValid: true},
+			url.OriginalURL{URL: "https://youtube.com", IsValid: true},
+		))
+	})
+
+	It("records the reason a target is unhealthy only once", func() {
+		verify("https://google.es")
+		validator.SetUnreachable("https://google.es", true)
+
+		go service.Start(ctx)
+
+		Eventually(func() ([]*event.RecordedEvent, error) {
+			return store.LoadAll(ctx, event.BeginningPosition, 100)
+		}).Should(ContainElement(WithTransform(func(recorded *event.RecordedEvent) event.Event { return recorded.Event }, Equal(
+			&url.LoadBalancedURLUnhealthy{
+				Base:         event.Base{ID: hash, Version: 3, At: createdAt},
+				UnhealthyURL: "https://google.es",
+				Reason:       "unable to validate URLs: connection refused",
+			},
+		))))
+		Consistently(func() (int, error) {
+			_, version, err := loadBalancedURLRepository.Load(ctx, hash)
+			return version, err
+		}, 100*time.Millisecond).Should(Equal(3))
+	})
+
+	It("checks the links projected before restarting, resuming from the checkpoint", func() {
+		verify("https://google.es")
+		firstCtx, stopFirst := context.WithCancel(ctx)
+		firstStopped := make(chan struct{})
+		go func() {
+			defer close(firstStopped)
+			service.Start(firstCtx)
+		}()
+		Eventually(func() ([]string, error) { return monitored.FindMonitoredLinks(ctx) }).Should(Equal([]string{hash}))
+		Eventually(func() (int64, error) { return checkpoints.LoadCheckpoint(ctx, healthchecker.ProjectionName) }).ShouldNot(BeZero())
+		stopFirst()
+		<-firstStopped
+
+		validator.SetUnreachable("https://google.es", true)
+		go newService().Start(ctx)
+
+		Eventually(targets).Should(ConsistOf(
+			url.OriginalURL{URL: "https://google.es", IsValid: false, Unhealthy: true},
+			url.OriginalURL{URL: "https://youtube.com", IsValid: false},
+		))
+	})
+
+	It("stops checking the links once they expire", func() {
+		go service.Start(ctx)
+		Eventually(func() ([]string, error) { return monitored.FindMonitoredLinks(ctx) }).Should(Equal([]string{hash}))
+
+		_, version, err := loadBalancedURLRepository.Load(ctx, hash)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loadBalancedURLRepository.Save(ctx, version, &url.LoadBalancedURLExpired{
+			Base: event.Base{ID: hash, Version: version + 1, At: createdAt.Add(time.Hour)},
+		})).To(Succeed())
+
+		Eventually(func() ([]string, error) { return monitored.FindMonitoredLinks(ctx) }).Should(BeEmpty())
+	})
+
+	It("probes the targets of a link concurrently", func() {
+		targetURLs := []string{"https://a.example.com", "https://b.example.com", "https://c.example.com", "https://d.example.com"}
+		loadBalancer := url.NewLoadBalancer(loadBalancedURLRepository, clock, url.NewURLSafeHashGenerator())
+		loadBalancedURL, err := loadBalancer.ShortURLs(ctx, targetURLs)
+		Expect(err).ToNot(HaveOccurred())
+		hash = loadBalancedURL.Hash
+		verify(targetURLs...)
+		Expect(monitored.SaveMonitoredLink(ctx, hash)).To(Succeed())
+		validator.delay = 100 * time.Millisecond
+
+		start := time.Now()
+		service.CheckLinks(ctx)
+
+		Expect(time.Since(start)).To(BeNumerically("<", 2*validator.delay))
+		for _, targetURL := range targetURLs {
+			Expect(validator.Probed(targetURL)).To(BeTrue())
+		}
+	})
+
+	It("doesn't check the targets that were never valid", func() {
+		verify("https://google.es")
+		validator.SetUnreachable("https://youtube.com", true)
+		go service.Start(ctx)
+
+		Consistently(targets, 100*time.Millisecond).Should(ConsistOf(
+			url.OriginalURL{URL: "https://google.es", IsValid: true},
+			url.OriginalURL{URL: "https://youtube.com", IsValid: false},
+		))
+		Expect(validator.Probed("https://youtube.com")).To(BeFalse())
+	})
+
+	It("doesn't check the expired links", func() {
+		verify("https://google.es")
+		validator.SetUnreachable("https://google.es", true)
+		clock.Set(createdAt.Add(time.Hour))
+
+		go service.Start(ctx)
+
+		Consistently(targets, 100*time.Millisecond).Should(ConsistOf(
+			url.OriginalURL{URL: "https://google.es", IsValid: true},
+			url.OriginalURL{URL: "https://youtube.com", IsValid: false},
+		))
+	})
+})
+
+type FakeValidator struct {
+	mutex       sync.Mutex
+	unreachable map[string]bool
+	probed      map[string]bool
+	// delay is how long each validation takes
+	delay time.Duration
+}
+
+func (f *FakeValidator) ValidateURLs(_ context.Context, urls []string) (bool, error) {
+	time.Sleep(f.delay)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, aURL := range urls {
+		if f.probed == nil {
+			f.probed = map[string]bool{}
+		}
+		f.probed[aURL] = true
+		if f.unreachable[aURL] {
+			return false, errors.New("unable to validate URLs: connection refused")
+		}
+	}
+	return true, nil
+}
+
+func (f *FakeValidator) SetUnreachable(aURL string, unreachable bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.unreachable[aURL] = unreachable
+}
+
+func (f *FakeValidator) Probed(aURL string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.probed[aURL]
+}
+
+type FakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+func (f *FakeClock) Set(now time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.now = now
+}
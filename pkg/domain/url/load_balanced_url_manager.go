@@ -0,0 +1,150 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+var (
+	ErrLoadBalancedURLNotFound = errors.New("load balanced url not found")
+	ErrTargetNotFound          = errors.New("target not found")
+	ErrDuplicatedTarget        = errors.New("duplicated target")
+)
+
+// LoadBalancedURLManager lets the owner of a LoadBalancedURL change its targets once it's created, keeping its hash.
+type LoadBalancedURLManager struct {
+	repository event.Repository
+	clock      event.Clock
+	normalizer *Normalizer
+}
+
+// AddTarget adds a URL to the targets of the LoadBalancedURL. The weight is only given with the WeightedStrategy.
+// The new URL is not valid until it's verified.
+func (m *LoadBalancedURLManager) AddTarget(ctx context.Context, hash string, aLongURL string, weight int) (*LoadBalancedURL, error) {
+	aLongURL = m.normalizer.Normalize(aLongURL)
+	if err := checkLongURL(aLongURL); err != nil {
+		return nil, err
+	}
+	return m.update(ctx, hash, func(link *LoadBalancedURL, base event.Base) (event.Event, error) {
+		if indexOfTarget(link.LongURLs, aLongURL) >= 0 {
+			return nil, nil
+		}
+		if len(link.LongURLs) >= maxNumberOfURLsToLoadBalance {
+			return nil, ErrTooMuchMultipleURLs
+		}
+		var weights []int
+		if weight != 0 {
+			weights = []int{weight}
+		}
+		err := validateStrategy(link.Strategy, weights, 1)
+		if err != nil {
+			return nil, err
+		}
+		return &LoadBalancedURLTargetAdded{Base: base, OriginalURL: aLongURL, Weight: weight}, nil
+	})
+}
+
+// RemoveTarget stops the LoadBalancedURL from redirecting to the URL. The last target can't be removed.
+func (m *LoadBalancedURLManager) RemoveTarget(ctx context.Context, hash string, aLongURL string) (*LoadBalancedURL, error) {
+	aLongURL = m.normalizer.Normalize(aLongURL)
+	return m.update(ctx, hash, func(link *LoadBalancedURL, base event.Base) (event.Event, error) {
+		if indexOfTarget(link.LongURLs, aLongURL) < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrTargetNotFound, aLongURL)
+		}
+		if len(link.LongURLs) == 1 {
+			return nil, fmt.Errorf("%w: the last target can't be removed", ErrNoURLsSpecified)
+		}
+		return &LoadBalancedURLTargetRemoved{Base: base, OriginalURL: aLongURL}, nil
+	})
+}
+
+// ReplaceTargets replaces the targets of the LoadBalancedURL, in the given order. The weights are only given
+// with the WeightedStrategy. The URLs that were already targets keep their validation, the new ones are not
+// valid until they're verified. The same URL can't be given twice.
+func (m *LoadBalancedURLManager) ReplaceTargets(ctx context.Context, hash string, urls []string, weights []int) (*LoadBalancedURL, error) {
+	urls = normalizeURLs(m.normalizer, urls)
+	if len(urls) == 0 {
+		return nil, ErrNoURLsSpecified
+	}
+	if len(urls) > maxNumberOfURLsToLoadBalance {
+		return nil, ErrTooMuchMultipleURLs
+	}
+	if err := checkLongURLs(urls); err != nil {
+		return nil, err
+	}
+	if err := checkDuplicatedTargets(urls); err != nil {
+		return nil, err
+	}
+	return m.update(ctx, hash, func(link *LoadBalancedURL, base event.Base) (event.Event, error) {
+		err := validateStrategy(link.Strategy, weights, len(urls))
+		if err != nil {
+			return nil, err
+		}
+		return &LoadBalancedURLTargetsReplaced{Base: base, OriginalURLs: urls, Weights: weights}, nil
+	})
+}
+
+// update saves the event returned by change for the latest version of the LoadBalancedURL, or nothing if it returns nil.
+func (m *LoadBalancedURLManager) update(ctx context.Context, hash string, change func(link *LoadBalancedURL, base event.Base) (event.Event, error)) (*LoadBalancedURL, error) {
+	var link *LoadBalancedURL
+	err := event.RetryOnConflict(ctx, maxUpdateAttempts, func(ctx context.Context) error {
+		entity, version, err := m.repository.Load(ctx, hash)
+		// the events of another kind of link can't be handled, as the hash is not of a load balanced url
+		if errors.Is(err, event.ErrEntityNotFound) || errors.Is(err, event.ErrUnhandledEvent) {
+			return ErrLoadBalancedURLNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("unable to load the load balanced url: %w", err)
+		}
+		var ok bool
+		link, ok = entity.(*LoadBalancedURL)
+		if !ok {
+			return fmt.Errorf("unknown entity type loaded while updating the load balanced url: %T", entity)
+		}
+
+		evt, err := change(link, event.Base{ID: hash, Version: version + 1, At: m.clock.Now()})
+		if err != nil || evt == nil {
+			return err
+		}
+		err = m.repository.Save(ctx, version, evt)
+		if err != nil {
+			return err
+		}
+		return link.On(evt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func checkDuplicatedTargets(urls []string) error {
+	seen := make(map[string]bool, len(urls))
+	for _, aURL := range urls {
+		if seen[aURL] {
+			return fmt.Errorf("%w: %s", ErrDuplicatedTarget, aURL)
+		}
+		seen[aURL] = true
+	}
+	return nil
+}
+
+func indexOfTarget(targets []OriginalURL, aLongURL string) int {
+	for i, target := range targets {
+		if target.URL == aLongURL {
+			return i
+		}
+	}
+	return -1
+}
+
+func NewLoadBalancedURLManager(repository event.Repository, clock event.Clock, options ...ShortenerOption) *LoadBalancedURLManager {
+	return &LoadBalancedURLManager{
+		repository: repository,
+		clock:      clock,
+		normalizer: newShortenerOptions(options).normalizer,
+	}
+}
@@ -0,0 +1,293 @@
+package url_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	domainmocks "github.com/WebEngineeringGroupI/backend/pkg/domain/event/mocks"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+var _ = Describe("Domain / URL / Load balanced URL manager", func() {
+	var (
+		ctx        context.Context
+		ctrl       *gomock.Controller
+		repository *domainmocks.MockRepository
+		clock      *domainmocks.MockClock
+		manager    *url.LoadBalancedURLManager
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+		repository = domainmocks.NewMockRepository(ctrl)
+		clock = domainmocks.NewMockClock(ctrl)
+		manager = url.NewLoadBalancedURLManager(repository, clock)
+
+		clock.EXPECT().Now().AnyTimes().Return(time.Time{})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	aLoadBalancedURL := func() *url.LoadBalancedURL {
+		return &url.LoadBalancedURL{
+			Hash: "5XEOqhb0",
+			LongURLs: []url.OriginalURL{
+				{URL: "https://google.es", IsValid: true},
+				{URL: "https://youtube.com", IsValid: true},
+			},
+		}
+	}
+
+	aWeightedLoadBalancedURL := func() *url.LoadBalancedURL {
+		return &url.LoadBalancedURL{
+			Hash:     "5XEOqhb0",
+			Strategy: url.WeightedStrategy,
+			LongURLs: []url.OriginalURL{
+				{URL: "https://google.es", IsValid: true, Weight: 3},
+				{URL: "https://youtube.com", IsValid: true, Weight: 1},
+			},
+		}
+	}
+
+	Context("adding a target", func() {
+		It("adds the target, which has to be verified", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+			repository.EXPECT().Save(ctx, 1, &url.LoadBalancedURLTargetAdded{Base: event.Base{ID: "5XEOqhb0", Version: 2}, OriginalURL: "https://unizar.es"})
+
+			loadBalancedURL, err := manager.AddTarget(ctx, "5XEOqhb0", "https://unizar.es", 0)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURL.Hash).To(Equal("5XEOqhb0"))
+			Expect(loadBalancedURL.LongURLs).To(Equal([]url.OriginalURL{
+				{URL: "https://google.es", IsValid: true},
+				{URL: "https://youtube.com", IsValid: true},
+				{URL: "https://unizar.es", IsValid: false},
+			}))
+		})
+
+		It("adds the target with its weight to a weighted load balanced URL", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aWeightedLoadBalancedURL(), 1, nil)
+			repository.EXPECT().Save(ctx, 1, &url.LoadBalancedURLTargetAdded{Base: event.Base{ID: "5XEOqhb0", Version: 2}, OriginalURL: "https://unizar.es", Weight: 2})
+
+			loadBalancedURL, err := manager.AddTarget(ctx, "5XEOqhb0", "https://unizar.es", 2)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURL.LongURLs[2]).To(Equal(url.OriginalURL{URL: "https://unizar.es", Weight: 2}))
+		})
+
+		It("doesn't add a target twice", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+
+			loadBalancedURL, err := manager.AddTarget(ctx, "5XEOqhb0", "https://google.es", 0)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURL.LongURLs).To(HaveLen(2))
+		})
+
+		It("normalizes the target, so an existing one is not added twice", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+
+			loadBalancedURL, err := manager.AddTarget(ctx, "5XEOqhb0", "https://GOOGLE.es:443/", 0)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURL.LongURLs).To(HaveLen(2))
+		})
+
+		It("adds the target normalized", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+			repository.EXPECT().Save(ctx, 1, &url.LoadBalancedURLTargetAdded{Base: event.Base{ID: "5XEOqhb0", Version: 2}, OriginalURL: "https://unizar.es"})
+
+			_, err := manager.AddTarget(ctx, "5XEOqhb0", "https://Unizar.es/", 0)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("rejects an empty target", func() {
+			_, err := manager.AddTarget(ctx, "5XEOqhb0", "", 0)
+
+			Expect(err).To(MatchError(url.ErrInvalidLongURLSpecified))
+		})
+
+		It("rejects a weight that doesn't match the strategy", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aWeightedLoadBalancedURL(), 1, nil)
+
+			_, err := manager.AddTarget(ctx, "5XEOqhb0", "https://unizar.es", 0)
+
+			Expect(err).To(MatchError(url.ErrInvalidStrategy))
+		})
+
+		It("doesn't add more targets than the allowed", func() {
+			full := &url.LoadBalancedURL{Hash: "5XEOqhb0"}
+			for i := 0; i < 10; i++ {
+				full.LongURLs = append(full.LongURLs, url.OriginalURL{URL: fmt.Sprintf("https://example.com/%d", i)})
+			}
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(full, 1, nil)
+
+			_, err := manager.AddTarget(ctx, "5XEOqhb0", "https://unizar.es", 0)
+
+			Expect(err).To(MatchError(url.ErrTooMuchMultipleURLs))
+		})
+	})
+
+	Context("removing a target", func() {
+		It("removes the target", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+			repository.EXPECT().Save(ctx, 1, &url.LoadBalancedURLTargetRemoved{Base: event.Base{ID: "5XEOqhb0", Version: 2}, OriginalURL: "https://google.es"})
+
+			loadBalancedURL, err := manager.RemoveTarget(ctx, "5XEOqhb0", "https://google.es")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURL.LongURLs).To(Equal([]url.OriginalURL{{URL: "https://youtube.com", IsValid: true}}))
+		})
+
+		It("removes the target given with another form of the same URL", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+			repository.EXPECT().Save(ctx, 1, &url.LoadBalancedURLTargetRemoved{Base: event.Base{ID: "5XEOqhb0", Version: 2}, OriginalURL: "https://google.es"})
+
+			_, err := manager.RemoveTarget(ctx, "5XEOqhb0", "https://Google.es/")
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error if the target is not found", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+
+			_, err := manager.RemoveTarget(ctx, "5XEOqhb0", "https://unizar.es")
+
+			Expect(err).To(MatchError(url.ErrTargetNotFound))
+		})
+
+		It("doesn't remove the last target", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(&url.LoadBalancedURL{
+				Hash:     "5XEOqhb0",
+				LongURLs: []url.OriginalURL{{URL: "https://google.es", IsValid: true}},
+			}, 1, nil)
+
+			_, err := manager.RemoveTarget(ctx, "5XEOqhb0", "https://google.es")
+
+			Expect(err).To(MatchError(url.ErrNoURLsSpecified))
+		})
+	})
+
+	Context("replacing the targets", func() {
+		It("replaces the targets in order, keeping the validation of the ones already there", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+			repository.EXPECT().Save(ctx, 1, &url.LoadBalancedURLTargetsReplaced{
+				Base:         event.Base{ID: "5XEOqhb0", Version: 2},
+				OriginalURLs: []string{"https://unizar.es", "https://google.es"},
+			})
+
+			loadBalancedURL, err := manager.ReplaceTargets(ctx, "5XEOqhb0", []string{"https://unizar.es", "https://google.es"}, nil)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURL.LongURLs).To(Equal([]url.OriginalURL{
+				{URL: "https://unizar.es", IsValid: false},
+				{URL: "https://google.es", IsValid: true},
+			}))
+		})
+
+		It("replaces the weights of a weighted load balanced URL", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aWeightedLoadBalancedURL(), 1, nil)
+			repository.EXPECT().Save(ctx, 1, gomock.Any())
+
+			loadBalancedURL, err := manager.ReplaceTargets(ctx, "5XEOqhb0", []string{"https://youtube.com", "https://google.es"}, []int{5, 1})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURL.LongURLs).To(Equal([]url.OriginalURL{
+				{URL: "https://youtube.com", IsValid: true, Weight: 5},
+				{URL: "https://google.es", IsValid: true, Weight: 1},
+			}))
+		})
+
+		It("replaces the targets normalized", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+			repository.EXPECT().Save(ctx, 1, &url.LoadBalancedURLTargetsReplaced{
+				Base:         event.Base{ID: "5XEOqhb0", Version: 2},
+				OriginalURLs: []string{"https://unizar.es", "https://google.es"},
+			})
+
+			loadBalancedURL, err := manager.ReplaceTargets(ctx, "5XEOqhb0", []string{"https://UNIZAR.es/", "https://google.es:443"}, nil)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURL.LongURLs).To(Equal([]url.OriginalURL{
+				{URL: "https://unizar.es", IsValid: false},
+				{URL: "https://google.es", IsValid: true},
+			}))
+		})
+
+		It("rejects the same target twice", func() {
+			_, err := manager.ReplaceTargets(ctx, "5XEOqhb0", []string{"https://unizar.es", "https://google.es", "https://Unizar.es/"}, nil)
+
+			Expect(err).To(MatchError(url.ErrDuplicatedTarget))
+		})
+
+		It("rejects an empty list of targets", func() {
+			_, err := manager.ReplaceTargets(ctx, "5XEOqhb0", nil, nil)
+
+			Expect(err).To(MatchError(url.ErrNoURLsSpecified))
+		})
+
+		It("rejects more targets than the allowed", func() {
+			urls := make([]string, 11)
+			for i := range urls {
+				urls[i] = fmt.Sprintf("https://example.com/%d", i)
+			}
+
+			_, err := manager.ReplaceTargets(ctx, "5XEOqhb0", urls, nil)
+
+			Expect(err).To(MatchError(url.ErrTooMuchMultipleURLs))
+		})
+
+		It("rejects weights that don't match the strategy", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil)
+
+			_, err := manager.ReplaceTargets(ctx, "5XEOqhb0", []string{"https://unizar.es"}, []int{1})
+
+			Expect(err).To(MatchError(url.ErrInvalidStrategy))
+		})
+	})
+
+	When("another event of the load balanced URL is saved concurrently", func() {
+		It("loads the load balanced URL again and saves the change", func() {
+			gomock.InOrder(
+				repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 1, nil),
+				repository.EXPECT().Save(ctx, 1, gomock.Any()).Return(event.ErrConcurrencyConflict),
+				repository.EXPECT().Load(ctx, "5XEOqhb0").Return(aLoadBalancedURL(), 2, nil),
+				repository.EXPECT().Save(ctx, 2, &url.LoadBalancedURLTargetRemoved{Base: event.Base{ID: "5XEOqhb0", Version: 3}, OriginalURL: "https://youtube.com"}),
+			)
+
+			_, err := manager.RemoveTarget(ctx, "5XEOqhb0", "https://youtube.com")
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("the hash is of a short URL", func() {
+		It("returns that the load balanced URL doesn't exist, without changing it", func() {
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, fmt.Errorf("%w: aggregate was unable to handle event", event.ErrUnhandledEvent))
+
+			_, err := manager.AddTarget(ctx, "cv6VxVdu", "https://evil.example.org/x", 0)
+
+			Expect(err).To(MatchError(url.ErrLoadBalancedURLNotFound))
+		})
+	})
+
+	When("the load balanced URL doesn't exist", func() {
+		It("returns an error", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(nil, 0, event.ErrEntityNotFound)
+
+			_, err := manager.AddTarget(ctx, "5XEOqhb0", "https://unizar.es", 0)
+
+			Expect(err).To(MatchError(url.ErrLoadBalancedURLNotFound))
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 )
@@ -18,79 +19,203 @@ var (
 const maxNumberOfURLsToLoadBalance = 10
 
 type LoadBalancerService struct {
-	repository event.Repository
-	clock      event.Clock
+	repository    event.Repository
+	clock         event.Clock
+	hashGenerator HashGenerator
+	normalizer    *Normalizer
 }
 
 type LoadBalancedURL struct {
 	Hash     string
 	LongURLs []OriginalURL
+	// Strategy is how the LongURLs are chosen, use Strategy.OrDefault as it's empty for the default one
+	Strategy Strategy
+	// ExpiresAt is when the LoadBalancedURL stops redirecting, the zero time if it never expires
+	ExpiresAt time.Time
+	// Expired is set once the expiration of the LoadBalancedURL has been recorded
+	Expired bool
+}
+
+// IsExpired tells if the LoadBalancedURL is expired at the given time.
+func (l *LoadBalancedURL) IsExpired(now time.Time) bool {
+	return isExpired(l.ExpiresAt, l.Expired, now)
+}
+
+// LoadBalancedURLOptions customizes how a LoadBalancedURL is created
+type LoadBalancedURLOptions struct {
+	// Expiration is when the LoadBalancedURL stops redirecting
+	Expiration Expiration
+	// Strategy is how the URLs are chosen, the RandomStrategy if it's empty
+	Strategy Strategy
+	// Weights are the weights of each URL, in the same order, only for the WeightedStrategy
+	Weights []int
 }
 
 func (l *LoadBalancedURL) On(evt event.Event) error {
 	switch e := evt.(type) {
 	case *LoadBalancedURLCreated:
 		l.Hash = e.EntityID()
+		l.Strategy = e.Strategy
 		l.LongURLs = []OriginalURL{}
-		for _, url := range e.OriginalURLs {
+		for i, url := range e.OriginalURLs {
 			l.LongURLs = append(l.LongURLs, OriginalURL{
 				IsValid: false,
 				URL:     url,
+				Weight:  weightAt(e.Weights, i),
 			})
 		}
 	case *LoadBalancedURLVerified:
-		l.LongURLs = verifyLongURLFromList(l.LongURLs, e.VerifiedURL)
+		// a target verified again is healthy, even if it was unhealthy before
+		l.LongURLs = setHealthOfLongURLFromList(l.LongURLs, e.VerifiedURL, true)
+	case *LoadBalancedURLTargetAdded:
+		l.LongURLs = append(l.LongURLs, OriginalURL{URL: e.OriginalURL, Weight: e.Weight})
+	case *LoadBalancedURLTargetRemoved:
+		l.LongURLs = removeLongURLFromList(l.LongURLs, e.OriginalURL)
+	case *LoadBalancedURLTargetsReplaced:
+		l.LongURLs = replaceLongURLs(l.LongURLs, e.OriginalURLs, e.Weights)
+	case *LoadBalancedURLUnhealthy:
+		l.LongURLs = setHealthOfLongURLFromList(l.LongURLs, e.UnhealthyURL, false)
+	case *LoadBalancedURLRecovered:
+		l.LongURLs = setHealthOfLongURLFromList(l.LongURLs, e.RecoveredURL, true)
+	case *LoadBalancedURLExpirationScheduled:
+		l.ExpiresAt = e.ExpiresAt
+	case *LoadBalancedURLExpired:
+		l.Expired = true
 	default:
 		return event.ErrUnhandledEvent
 	}
 	return nil
 }
 
-func verifyLongURLFromList(original []OriginalURL, verifiedURL string) []OriginalURL {
+func removeLongURLFromList(original []OriginalURL, removedURL string) []OriginalURL {
 	newList := make([]OriginalURL, 0, len(original))
 	for _, url := range original {
-		if url.URL != verifiedURL {
+		if url.URL != removedURL {
 			newList = append(newList, url)
-			continue
 		}
-		newList = append(newList, OriginalURL{
-			URL:     url.URL,
-			IsValid: true,
-		})
 	}
 	return newList
 }
 
+// replaceLongURLs returns the new URLs, keeping the validation of the ones that were already in the list
+func replaceLongURLs(original []OriginalURL, urls []string, weights []int) []OriginalURL {
+	previous := map[string]OriginalURL{}
+	for _, url := range original {
+		previous[url.URL] = url
+	}
+
+	newList := make([]OriginalURL, 0, len(urls))
+	for i, aURL := range urls {
+		url := previous[aURL]
+		url.URL = aURL
+		url.Weight = weightAt(weights, i)
+		newList = append(newList, url)
+	}
+	return newList
+}
+
+// setHealthOfLongURLFromList makes the URL invalid while it's unhealthy, and valid again once it recovers
+func setHealthOfLongURLFromList(original []OriginalURL, aURL string, healthy bool) []OriginalURL {
+	newList := make([]OriginalURL, 0, len(original))
+	for _, url := range original {
+		if url.URL == aURL {
+			url.IsValid = healthy
+			url.Unhealthy = !healthy
+		}
+		newList = append(newList, url)
+	}
+	return newList
+}
+
+// weightAt returns the weight of the i-th URL, zero if there are no weights
+func weightAt(weights []int, i int) int {
+	if i >= len(weights) {
+		return 0
+	}
+	return weights[i]
+}
+
 func (b *LoadBalancerService) ShortURLs(ctx context.Context, urls []string) (*LoadBalancedURL, error) {
+	return b.ShortURLsWithOptions(ctx, urls, LoadBalancedURLOptions{})
+}
+
+// ShortURLsWithOptions creates a LoadBalancedURL like ShortURLs, but customized with the options.
+func (b *LoadBalancerService) ShortURLsWithOptions(ctx context.Context, urls []string, options LoadBalancedURLOptions) (*LoadBalancedURL, error) {
 	if len(urls) == 0 {
 		return nil, ErrNoURLsSpecified
 	}
 	if len(urls) > maxNumberOfURLsToLoadBalance {
 		return nil, ErrTooMuchMultipleURLs
 	}
+	expiresAt, err := options.Expiration.expiresAt(b.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	err = validateStrategy(options.Strategy, options.Weights, len(urls))
+	if err != nil {
+		return nil, err
+	}
+	urls = normalizeURLs(b.normalizer, urls)
+	err = checkLongURLs(urls)
+	if err != nil {
+		return nil, err
+	}
+	err = checkDuplicatedTargets(urls)
+	if err != nil {
+		return nil, err
+	}
+
+	var loadBalancedURL *LoadBalancedURL
+	err = event.RetryOnConflict(ctx, maxHashAttempts, func(ctx context.Context) error {
+		var err error
+		loadBalancedURL, err = b.loadBalancedURL(ctx, urls, expiresAt, options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loadBalancedURL, nil
+}
 
-	hash := hashFromURLs(urls)
-	entity, _, err := b.repository.Load(ctx, hash)
-	if err == nil {
+func (b *LoadBalancerService) loadBalancedURL(ctx context.Context, urls []string, expiresAt time.Time, options LoadBalancedURLOptions) (*LoadBalancedURL, error) {
+	content := hashContentWithExpiration(hashContentWithStrategy(strings.Join(urls, ""), options.Strategy, options.Weights), expiresAt)
+	hash, entity, err := findHash(ctx, b.repository, b.hashGenerator, content, isLoadBalancedURLOf(urls, expiresAt, options))
+	if err != nil {
+		return nil, err
+	}
+	if entity != nil {
 		loadBalancedURL, ok := entity.(*LoadBalancedURL)
 		if !ok {
-			return nil, fmt.Errorf("unknown entity type loaded while load balancing urls: %w", err)
+			return nil, fmt.Errorf("unknown entity type loaded while load balancing urls: %T", entity)
 		}
 		return loadBalancedURL, nil
 	}
 
+	now := b.clock.Now()
 	events := []event.Event{
 		&LoadBalancedURLCreated{
 			Base: event.Base{
 				ID:      hash,
 				Version: 0,
-				At:      b.clock.Now(),
+				At:      now,
 			},
 			OriginalURLs: urls,
+			Strategy:     options.Strategy,
+			Weights:      options.Weights,
 		},
 	}
+	if !expiresAt.IsZero() {
+		events = append(events, &LoadBalancedURLExpirationScheduled{
+			Base: event.Base{
+				ID:      hash,
+				Version: 1,
+				At:      now,
+			},
+			ExpiresAt: expiresAt,
+		})
+	}
 
-	err = b.repository.Save(ctx, events...)
+	err = b.repository.Save(ctx, event.NewStreamVersion, events...)
 	if err != nil {
 		return nil, fmt.Errorf("error saving load-balanced URLs into repository: %w", err)
 	}
@@ -106,13 +231,29 @@ func (b *LoadBalancerService) ShortURLs(ctx context.Context, urls []string) (*Lo
 	return url, nil
 }
 
-func hashFromURLs(urls []string) string {
-	return hashFromURL(strings.Join(urls, ""))
+func isLoadBalancedURLOf(urls []string, expiresAt time.Time, options LoadBalancedURLOptions) func(entity event.Entity) bool {
+	return func(entity event.Entity) bool {
+		loadBalancedURL, ok := entity.(*LoadBalancedURL)
+		if !ok || len(loadBalancedURL.LongURLs) != len(urls) || !loadBalancedURL.ExpiresAt.Equal(expiresAt) {
+			return false
+		}
+		if loadBalancedURL.Strategy.OrDefault() != options.Strategy.OrDefault() {
+			return false
+		}
+		for i, longURL := range loadBalancedURL.LongURLs {
+			if longURL.URL != urls[i] || longURL.Weight != weightAt(options.Weights, i) {
+				return false
+			}
+		}
+		return true
+	}
 }
 
-func NewLoadBalancer(repository event.Repository, clock event.Clock) *LoadBalancerService {
+func NewLoadBalancer(repository event.Repository, clock event.Clock, hashGenerator HashGenerator, options ...ShortenerOption) *LoadBalancerService {
 	return &LoadBalancerService{
-		repository: repository,
-		clock:      clock,
+		repository:    repository,
+		clock:         clock,
+		hashGenerator: hashGenerator,
+		normalizer:    newShortenerOptions(options).normalizer,
 	}
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
@@ -28,7 +29,7 @@ var _ = Describe("Domain / URL / Load Balancing", func() {
 		ctrl = gomock.NewController(GinkgoT())
 		multipleShortURLsRepository = domainmocks.NewMockRepository(ctrl)
 		clock = domainmocks.NewMockClock(ctrl)
-		loadBalancer = url.NewLoadBalancer(multipleShortURLsRepository, clock)
+		loadBalancer = url.NewLoadBalancer(multipleShortURLsRepository, clock, url.NewURLSafeHashGenerator())
 		ctx = context.Background()
 
 		clock.EXPECT().Now().AnyTimes().Return(time.Time{})
@@ -40,40 +41,219 @@ var _ = Describe("Domain / URL / Load Balancing", func() {
 
 	When("a single URL is generated from multiple URLs", func() {
 		It("is correctly generated", func() {
-			multipleShortURLsRepository.EXPECT().Save(ctx,
+			multipleShortURLsRepository.EXPECT().Save(ctx, event.NewStreamVersion,
 				&url.LoadBalancedURLCreated{
 					Base: event.Base{
-						ID:      "P3Z83Gpy",
+						ID:      "t1P_Dj3a",
 						Version: 0,
 						At:      time.Time{},
 					},
-					OriginalURLs: []string{"aURL", "anotherURL"},
+					OriginalURLs: []string{"https://a.example.com", "https://b.example.com"},
 				},
 			)
-			multipleShortURLsRepository.EXPECT().Load(ctx, "P3Z83Gpy").Return(nil, 0, url.ErrValidURLNotFound)
+			multipleShortURLsRepository.EXPECT().Load(ctx, "t1P_Dj3a").Return(nil, 0, event.ErrEntityNotFound)
 
-			loadBalancedURLs, err := loadBalancer.ShortURLs(ctx, []string{"aURL", "anotherURL"})
+			loadBalancedURLs, err := loadBalancer.ShortURLs(ctx, []string{"https://a.example.com", "https://b.example.com"})
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(loadBalancedURLs).To(Equal(&url.LoadBalancedURL{
-				Hash: "P3Z83Gpy",
+				Hash: "t1P_Dj3a",
 				LongURLs: []url.OriginalURL{
-					{URL: "aURL", IsValid: false},
-					{URL: "anotherURL", IsValid: false},
+					{URL: "https://a.example.com", IsValid: false},
+					{URL: "https://b.example.com", IsValid: false},
 				},
 			}))
 		})
 	})
 
+	When("the load balanced URL expires", func() {
+		It("schedules its expiration", func() {
+			var saved []event.Event
+			multipleShortURLsRepository.EXPECT().Load(ctx, gomock.Not("t1P_Dj3a")).Return(nil, 0, event.ErrEntityNotFound)
+			multipleShortURLsRepository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ int, events ...event.Event) error {
+					saved = events
+					return nil
+				})
+
+			loadBalancedURLs, err := loadBalancer.ShortURLsWithOptions(ctx, []string{"https://a.example.com", "https://b.example.com"}, url.LoadBalancedURLOptions{
+				Expiration: url.Expiration{TTL: time.Hour},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURLs.Hash).ToNot(Equal("t1P_Dj3a"))
+			Expect(loadBalancedURLs.ExpiresAt).To(Equal(time.Time{}.Add(time.Hour)))
+			Expect(saved).To(ContainElement(&url.LoadBalancedURLExpirationScheduled{
+				Base:      event.Base{ID: loadBalancedURLs.Hash, Version: 1, At: time.Time{}},
+				ExpiresAt: time.Time{}.Add(time.Hour),
+			}))
+		})
+
+		It("rejects an invalid expiration", func() {
+			_, err := loadBalancer.ShortURLsWithOptions(ctx, []string{"https://a.example.com"}, url.LoadBalancedURLOptions{
+				Expiration: url.Expiration{TTL: -time.Hour},
+			})
+
+			Expect(err).To(MatchError(url.ErrInvalidExpiration))
+		})
+	})
+
+	When("the load balanced URL has a strategy", func() {
+		It("is stored with the weights of the URLs", func() {
+			var saved []event.Event
+			multipleShortURLsRepository.EXPECT().Load(ctx, gomock.Not("t1P_Dj3a")).Return(nil, 0, event.ErrEntityNotFound)
+			multipleShortURLsRepository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ int, events ...event.Event) error {
+					saved = events
+					return nil
+				})
+
+			loadBalancedURLs, err := loadBalancer.ShortURLsWithOptions(ctx, []string{"https://a.example.com", "https://b.example.com"}, url.LoadBalancedURLOptions{
+				Strategy: url.WeightedStrategy,
+				Weights:  []int{1, 3},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURLs.Hash).ToNot(Equal("t1P_Dj3a"))
+			Expect(loadBalancedURLs.Strategy).To(Equal(url.WeightedStrategy))
+			Expect(loadBalancedURLs.LongURLs).To(Equal([]url.OriginalURL{
+				{URL: "https://a.example.com", Weight: 1},
+				{URL: "https://b.example.com", Weight: 3},
+			}))
+			Expect(saved).To(Equal([]event.Event{&url.LoadBalancedURLCreated{
+				Base:         event.Base{ID: loadBalancedURLs.Hash, Version: 0, At: time.Time{}},
+				OriginalURLs: []string{"https://a.example.com", "https://b.example.com"},
+				Strategy:     url.WeightedStrategy,
+				Weights:      []int{1, 3},
+			}}))
+		})
+
+		It("doesn't reuse the load balanced URL of the same URLs with another strategy", func() {
+			gomock.InOrder(
+				multipleShortURLsRepository.EXPECT().Load(ctx, gomock.Any()).Return(&url.LoadBalancedURL{
+					Hash:     "t1P_Dj3a",
+					LongURLs: []url.OriginalURL{{URL: "https://a.example.com"}, {URL: "https://b.example.com"}},
+				}, 0, nil),
+				multipleShortURLsRepository.EXPECT().Load(ctx, gomock.Any()).Return(nil, 0, event.ErrEntityNotFound),
+			)
+			multipleShortURLsRepository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any())
+
+			loadBalancedURLs, err := loadBalancer.ShortURLsWithOptions(ctx, []string{"https://a.example.com", "https://b.example.com"}, url.LoadBalancedURLOptions{
+				Strategy: url.RoundRobinStrategy,
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURLs.Strategy).To(Equal(url.RoundRobinStrategy))
+		})
+
+		DescribeTable("rejects invalid strategies",
+			func(options url.LoadBalancedURLOptions) {
+				_, err := loadBalancer.ShortURLsWithOptions(ctx, []string{"https://a.example.com", "https://b.example.com"}, options)
+
+				Expect(err).To(MatchError(url.ErrInvalidStrategy))
+			},
+			Entry("an unknown strategy", url.LoadBalancedURLOptions{Strategy: "fastest"}),
+			Entry("weights without the weighted strategy", url.LoadBalancedURLOptions{Strategy: url.RoundRobinStrategy, Weights: []int{1, 2}}),
+			Entry("the weighted strategy without weights", url.LoadBalancedURLOptions{Strategy: url.WeightedStrategy}),
+			Entry("a weight for each URL missing", url.LoadBalancedURLOptions{Strategy: url.WeightedStrategy, Weights: []int{1}}),
+			Entry("a weight of zero", url.LoadBalancedURLOptions{Strategy: url.WeightedStrategy, Weights: []int{0, 1}}),
+		)
+	})
+
+	When("the URLs are not normalized", func() {
+		It("balances the normalized URLs", func() {
+			multipleShortURLsRepository.EXPECT().Load(ctx, "5XEOqhb0").Return(nil, 0, event.ErrEntityNotFound)
+			multipleShortURLsRepository.EXPECT().Save(ctx, event.NewStreamVersion, &url.LoadBalancedURLCreated{
+				Base:         event.Base{ID: "5XEOqhb0"},
+				OriginalURLs: []string{"https://google.es", "https://youtube.com"},
+			})
+
+			loadBalancedURL, err := loadBalancer.ShortURLs(ctx, []string{"HTTPS://google.ES/", "https://youtube.com:443"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURL.Hash).To(Equal("5XEOqhb0"))
+		})
+	})
+
+	When("the same URL is given twice once normalized", func() {
+		It("returns an error and doesn't save it", func() {
+			multipleShortURLsRepository.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			loadBalancedURL, err := loadBalancer.ShortURLs(ctx, []string{"https://google.es", "https://youtube.com", "HTTPS://google.ES/"})
+
+			Expect(err).To(MatchError(url.ErrDuplicatedTarget))
+			Expect(loadBalancedURL).To(BeNil())
+		})
+	})
+
+	When("the health of its URLs changes", func() {
+		It("stops using the unhealthy URLs until they recover", func() {
+			loadBalancedURL := &url.LoadBalancedURL{}
+			Expect(loadBalancedURL.On(&url.LoadBalancedURLCreated{Base: event.Base{ID: "t1P_Dj3a"}, OriginalURLs: []string{"https://a.example.com", "https://b.example.com"}})).To(Succeed())
+			Expect(loadBalancedURL.On(&url.LoadBalancedURLVerified{VerifiedURL: "https://a.example.com"})).To(Succeed())
+			Expect(loadBalancedURL.On(&url.LoadBalancedURLVerified{VerifiedURL: "https://b.example.com"})).To(Succeed())
+
+			Expect(loadBalancedURL.On(&url.LoadBalancedURLUnhealthy{UnhealthyURL: "https://a.example.com", Reason: "connection refused"})).To(Succeed())
+			Expect(loadBalancedURL.LongURLs).To(Equal([]url.OriginalURL{
+				{URL: "https://a.example.com", IsValid: false, Unhealthy: true},
+				{URL: "https://b.example.com", IsValid: true},
+			}))
+
+			Expect(loadBalancedURL.On(&url.LoadBalancedURLRecovered{RecoveredURL: "https://a.example.com"})).To(Succeed())
+			Expect(loadBalancedURL.LongURLs).To(Equal([]url.OriginalURL{
+				{URL: "https://a.example.com", IsValid: true},
+				{URL: "https://b.example.com", IsValid: true},
+			}))
+		})
+
+		It("uses an unhealthy URL again once it is verified again", func() {
+			loadBalancedURL := &url.LoadBalancedURL{}
+			Expect(loadBalancedURL.On(&url.LoadBalancedURLCreated{Base: event.Base{ID: "t1P_Dj3a"}, OriginalURLs: []string{"https://a.example.com"}})).To(Succeed())
+			Expect(loadBalancedURL.On(&url.LoadBalancedURLVerified{VerifiedURL: "https://a.example.com"})).To(Succeed())
+			Expect(loadBalancedURL.On(&url.LoadBalancedURLUnhealthy{UnhealthyURL: "https://a.example.com", Reason: "connection refused"})).To(Succeed())
+
+			Expect(loadBalancedURL.On(&url.LoadBalancedURLVerified{VerifiedURL: "https://a.example.com"})).To(Succeed())
+
+			Expect(loadBalancedURL.LongURLs).To(Equal([]url.OriginalURL{
+				{URL: "https://a.example.com", IsValid: true},
+			}))
+		})
+	})
+
 	When("the repository returns an error", func() {
 		It("returns the error from the repository", func() {
-			multipleShortURLsRepository.EXPECT().Load(ctx, "aSAQaNaB").Return(nil, 0, url.ErrValidURLNotFound)
-			multipleShortURLsRepository.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("unknown error"))
-			loadBalancedURLs, err := loadBalancer.ShortURLs(ctx, []string{"aURL"})
+			multipleShortURLsRepository.EXPECT().Load(ctx, "_ljDEc2i").Return(nil, 0, event.ErrEntityNotFound)
+			multipleShortURLsRepository.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("unknown error"))
+			loadBalancedURLs, err := loadBalancer.ShortURLs(ctx, []string{"https://a.example.com"})
 
 			Expect(err).To(MatchError("error saving load-balanced URLs into repository: unknown error"))
 			Expect(loadBalancedURLs).To(BeNil())
 		})
+
+		It("doesn't save the URLs if it can't check if the hash is in use", func() {
+			multipleShortURLsRepository.EXPECT().Load(ctx, "_ljDEc2i").Return(nil, 0, errors.New("unknown error"))
+
+			loadBalancedURLs, err := loadBalancer.ShortURLs(ctx, []string{"https://a.example.com"})
+
+			Expect(err).To(MatchError(ContainSubstring("unknown error")))
+			Expect(loadBalancedURLs).To(BeNil())
+		})
+	})
+
+	When("the hash is already used by other URLs", func() {
+		It("generates a longer hash", func() {
+			multipleShortURLsRepository.EXPECT().Load(ctx, "t1P_Dj3a").Return(&url.LoadBalancedURL{
+				Hash:     "t1P_Dj3a",
+				LongURLs: []url.OriginalURL{{URL: "https://a.example.comhttps://b"}, {URL: ".example.com"}},
+			}, 0, nil)
+			multipleShortURLsRepository.EXPECT().Load(ctx, "t1P_Dj3aE").Return(nil, 0, event.ErrEntityNotFound)
+			multipleShortURLsRepository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any())
+
+			loadBalancedURLs, err := loadBalancer.ShortURLs(ctx, []string{"https://a.example.com", "https://b.example.com"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadBalancedURLs.Hash).To(Equal("t1P_Dj3aE"))
+		})
 	})
 
 	When("the list of URLs is empty", func() {
@@ -96,22 +276,22 @@ var _ = Describe("Domain / URL / Load Balancing", func() {
 
 	When("the load balanced URL already exists in the database", func() {
 		It("returns the load balanced URL and doesn't try to save it again", func() {
-			multipleShortURLsRepository.EXPECT().Load(ctx, "P3Z83Gpy").Return(&url.LoadBalancedURL{
-				Hash: "P3Z83Gpy",
+			multipleShortURLsRepository.EXPECT().Load(ctx, "t1P_Dj3a").Return(&url.LoadBalancedURL{
+				Hash: "t1P_Dj3a",
 				LongURLs: []url.OriginalURL{
-					{URL: "aURL", IsValid: false},
-					{URL: "anotherURL", IsValid: false},
+					{URL: "https://a.example.com", IsValid: false},
+					{URL: "https://b.example.com", IsValid: false},
 				},
 			}, 0, nil)
 
-			loadBalancedURLs, err := loadBalancer.ShortURLs(ctx, []string{"aURL", "anotherURL"})
+			loadBalancedURLs, err := loadBalancer.ShortURLs(ctx, []string{"https://a.example.com", "https://b.example.com"})
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(loadBalancedURLs).To(Equal(&url.LoadBalancedURL{
-				Hash: "P3Z83Gpy",
+				Hash: "t1P_Dj3a",
 				LongURLs: []url.OriginalURL{
-					{URL: "aURL", IsValid: false},
-					{URL: "anotherURL", IsValid: false},
+					{URL: "https://a.example.com", IsValid: false},
+					{URL: "https://b.example.com", IsValid: false},
 				},
 			}))
 		})
@@ -0,0 +1,91 @@
+package url
+
+import (
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strings"
+)
+
+// maxLongURLLength limits the length of the long URLs, in bytes, as most browsers and servers don't handle longer ones
+const maxLongURLLength = 2048
+
+// InvalidURLReason is why a long URL is rejected
+type InvalidURLReason string
+
+const (
+	MalformedURL      InvalidURLReason = "malformed"
+	UnsupportedScheme InvalidURLReason = "unsupported_scheme"
+	MissingHost       InvalidURLReason = "missing_host"
+	PrivateHost       InvalidURLReason = "private_host"
+	TooLongURL        InvalidURLReason = "too_long"
+)
+
+// InvalidURLError is returned when a long URL is rejected before being shortened.
+// It wraps ErrInvalidLongURLSpecified, so it can be checked with errors.Is.
+type InvalidURLError struct {
+	URL    string
+	Reason InvalidURLReason
+	Detail string
+}
+
+func (e *InvalidURLError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidLongURLSpecified, e.Reason, e.Detail)
+}
+
+func (e *InvalidURLError) Unwrap() error {
+	return ErrInvalidLongURLSpecified
+}
+
+// supportedSchemes are the schemes of the URLs that can be shortened
+var supportedSchemes = map[string]bool{
+	"http":  true,
+	"https": true,
+}
+
+// checkLongURL rejects the long URLs that can't be redirected to, without any network access. The hosts are
+// not resolved, so only the IPs and the localhost names are known to be private; the asynchronous validators
+// check the rest.
+func checkLongURL(aLongURL string) error {
+	if len(aLongURL) > maxLongURLLength {
+		return &InvalidURLError{URL: aLongURL, Reason: TooLongURL, Detail: fmt.Sprintf("the url is longer than %d bytes", maxLongURLLength)}
+	}
+	parsed, err := neturl.Parse(aLongURL)
+	if err != nil {
+		return &InvalidURLError{URL: aLongURL, Reason: MalformedURL, Detail: err.Error()}
+	}
+	if !supportedSchemes[strings.ToLower(parsed.Scheme)] {
+		return &InvalidURLError{URL: aLongURL, Reason: UnsupportedScheme, Detail: fmt.Sprintf("the scheme %q is not http nor https", parsed.Scheme)}
+	}
+	host := parsed.Hostname()
+	if host == "" {
+		return &InvalidURLError{URL: aLongURL, Reason: MissingHost, Detail: "the url has no host"}
+	}
+	if isPrivateHost(host) {
+		return &InvalidURLError{URL: aLongURL, Reason: PrivateHost, Detail: fmt.Sprintf("the host %s is not public", host)}
+	}
+	return nil
+}
+
+func isPrivateHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+func checkLongURLs(urls []string) error {
+	for _, aLongURL := range urls {
+		err := checkLongURL(aLongURL)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
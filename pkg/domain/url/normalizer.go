@@ -0,0 +1,184 @@
+package url
+
+import (
+	"net"
+	neturl "net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// defaultPorts are the ports dropped from the URLs of each scheme, as they are implied
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// trackingParameters are the query parameters only used to track the visitors, which don't change the target.
+// The parameters starting with trackingParameterPrefix are tracking parameters too.
+var trackingParameters = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"mc_eid":  true,
+}
+
+const trackingParameterPrefix = "utm_"
+
+// Normalizer rewrites the URLs in a canonical form, so the same target is always shortened to the same hash.
+// It lowercases the scheme and host, drops the default ports and cleans the path. The URLs that are not absolute
+// are left as they are.
+type Normalizer struct {
+	sortQueryParameters     bool
+	stripTrackingParameters bool
+}
+
+// NormalizerOption configures the optional rewrites of a Normalizer created through NewNormalizer
+type NormalizerOption func(n *Normalizer)
+
+// WithSortedQueryParameters sorts the query parameters by their name, keeping the order of the values of each one
+func WithSortedQueryParameters() NormalizerOption {
+	return func(n *Normalizer) {
+		n.sortQueryParameters = true
+	}
+}
+
+// WithoutTrackingParameters removes the query parameters used to track the visitors, like utm_source or fbclid
+func WithoutTrackingParameters() NormalizerOption {
+	return func(n *Normalizer) {
+		n.stripTrackingParameters = true
+	}
+}
+
+func (n *Normalizer) Normalize(aLongURL string) string {
+	parsed, err := neturl.Parse(aLongURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return aLongURL
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = normalizeHost(parsed.Scheme, parsed.Host)
+	if parsed.RawPath == "" {
+		parsed.Path = cleanPath(parsed.Path)
+	}
+	parsed.RawQuery = n.normalizeQuery(parsed.RawQuery)
+	if parsed.RawQuery == "" {
+		parsed.ForceQuery = false
+	}
+	return parsed.String()
+}
+
+// normalizeURLs returns the URLs normalized with the normalizer, in the same order
+func normalizeURLs(normalizer *Normalizer, urls []string) []string {
+	normalized := make([]string, 0, len(urls))
+	for _, aURL := range urls {
+		normalized = append(normalized, normalizer.Normalize(aURL))
+	}
+	return normalized
+}
+
+func normalizeHost(scheme string, host string) string {
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		// there is no port
+		return strings.ToLower(host)
+	}
+	hostname = strings.ToLower(hostname)
+	if port == "" || defaultPorts[scheme] == port {
+		if strings.Contains(hostname, ":") {
+			return "[" + hostname + "]"
+		}
+		return hostname
+	}
+	return net.JoinHostPort(hostname, port)
+}
+
+// cleanPath removes the dot segments and repeated slashes of the path. The trailing slash is kept,
+// as it can be meaningful for the target, but the root path is removed.
+func cleanPath(aPath string) string {
+	if aPath == "" || aPath == "/" {
+		return ""
+	}
+	cleaned := path.Clean(aPath)
+	if cleaned == "/" {
+		return ""
+	}
+	if strings.HasSuffix(aPath, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+func (n *Normalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" || (!n.sortQueryParameters && !n.stripTrackingParameters) {
+		return rawQuery
+	}
+
+	parameters := make([]string, 0, strings.Count(rawQuery, "&")+1)
+	for _, parameter := range strings.Split(rawQuery, "&") {
+		if parameter == "" {
+			continue
+		}
+		if n.stripTrackingParameters && isTrackingParameter(queryParameterName(parameter)) {
+			continue
+		}
+		parameters = append(parameters, parameter)
+	}
+	if n.sortQueryParameters {
+		sort.SliceStable(parameters, func(i, j int) bool {
+			return queryParameterName(parameters[i]) < queryParameterName(parameters[j])
+		})
+	}
+	return strings.Join(parameters, "&")
+}
+
+func queryParameterName(parameter string) string {
+	name := parameter
+	if i := strings.Index(parameter, "="); i >= 0 {
+		name = parameter[:i]
+	}
+	unescaped, err := neturl.QueryUnescape(name)
+	if err != nil {
+		return name
+	}
+	return unescaped
+}
+
+func isTrackingParameter(name string) bool {
+	name = strings.ToLower(name)
+	return trackingParameters[name] || strings.HasPrefix(name, trackingParameterPrefix)
+}
+
+func NewNormalizer(options ...NormalizerOption) *Normalizer {
+	n := &Normalizer{}
+	for _, option := range options {
+		option(n)
+	}
+	return n
+}
+
+// ShortenerOption configures the optional behaviour of the SingleURLShortener, FileURLShortener and LoadBalancerService,
+// and of the ShortURLManager and LoadBalancedURLManager, so the targets are normalized the same way when they change
+type ShortenerOption func(o *shortenerOptions)
+
+type shortenerOptions struct {
+	normalizer *Normalizer
+}
+
+// WithNormalizer replaces the Normalizer the URLs are normalized with before they are hashed or changed, which by default
+// doesn't sort nor strip the query parameters
+func WithNormalizer(normalizer *Normalizer) ShortenerOption {
+	return func(o *shortenerOptions) {
+		o.normalizer = normalizer
+	}
+}
+
+func newShortenerOptions(options []ShortenerOption) shortenerOptions {
+	o := shortenerOptions{normalizer: NewNormalizer()}
+	for _, option := range options {
+		option(&o)
+	}
+	return o
+}
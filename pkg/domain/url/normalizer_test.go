@@ -0,0 +1,50 @@
+package url_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+var _ = Describe("Domain / URL / Normalizer", func() {
+	DescribeTable("normalizes the URLs",
+		func(aLongURL string, expected string) {
+			Expect(url.NewNormalizer().Normalize(aLongURL)).To(Equal(expected))
+		},
+		Entry("lowercasing the scheme and host", "HTTP://Example.COM/Path", "http://example.com/Path"),
+		Entry("removing the root path", "http://example.com/", "http://example.com"),
+		Entry("dropping the default http port", "http://example.com:80/", "http://example.com"),
+		Entry("dropping the default https port", "https://example.com:443/a", "https://example.com/a"),
+		Entry("keeping other ports", "https://example.com:8443/a", "https://example.com:8443/a"),
+		Entry("keeping the port of another scheme", "ftp://example.com:80/a", "ftp://example.com:80/a"),
+		Entry("dropping the default port of an IPv6 host", "http://[2001:DB8::1]:80/a", "http://[2001:db8::1]/a"),
+		Entry("cleaning the path", "http://example.com/a//b/./c/../d", "http://example.com/a/b/d"),
+		Entry("keeping the trailing slash", "http://example.com/a/b/", "http://example.com/a/b/"),
+		Entry("keeping the query and fragment", "http://example.com/a?b=1&a=2#top", "http://example.com/a?b=1&a=2#top"),
+		Entry("leaving the relative URLs as they are", "Example.com/a/../b", "Example.com/a/../b"),
+		Entry("leaving the unparseable URLs as they are", "http://exa mple.com:port", "http://exa mple.com:port"),
+	)
+
+	It("normalizes the same target to the same URL", func() {
+		normalizer := url.NewNormalizer()
+
+		Expect(normalizer.Normalize("http://Example.com/")).To(Equal("http://example.com"))
+		Expect(normalizer.Normalize("http://example.com")).To(Equal("http://example.com"))
+		Expect(normalizer.Normalize("http://example.com:80/")).To(Equal("http://example.com"))
+	})
+
+	It("sorts the query parameters by name if requested", func() {
+		normalizer := url.NewNormalizer(url.WithSortedQueryParameters())
+
+		Expect(normalizer.Normalize("http://example.com/?b=2&a=1&b=1")).To(Equal("http://example.com?a=1&b=2&b=1"))
+	})
+
+	It("strips the tracking parameters if requested", func() {
+		normalizer := url.NewNormalizer(url.WithoutTrackingParameters())
+
+		Expect(normalizer.Normalize("http://example.com/a?utm_source=x&id=3&fbclid=y&UTM_Medium=z&gclid=w")).To(Equal("http://example.com/a?id=3"))
+		Expect(normalizer.Normalize("http://example.com/a?utm_source=x")).To(Equal("http://example.com/a"))
+	})
+})
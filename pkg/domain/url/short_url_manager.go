@@ -0,0 +1,131 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+var (
+	ErrShortURLDisabled = errors.New("short url disabled")
+)
+
+// maxUpdateAttempts is the number of times an update is retried when other events
+// of the same ShortURL have been saved concurrently.
+const maxUpdateAttempts = 5
+
+// ShortURLManager lets the owner of a ShortURL change it once it's created.
+type ShortURLManager struct {
+	repository event.Repository
+	clock      event.Clock
+	normalizer *Normalizer
+}
+
+// Disable stops the ShortURL from redirecting until it's enabled again.
+func (m *ShortURLManager) Disable(ctx context.Context, hash string) (*ShortURL, error) {
+	return m.update(ctx, hash, func(shortURL *ShortURL, base event.Base) event.Event {
+		if shortURL.Disabled {
+			return nil
+		}
+		return &ShortURLDisabled{Base: base}
+	})
+}
+
+// Enable makes a disabled ShortURL redirect again.
+func (m *ShortURLManager) Enable(ctx context.Context, hash string) (*ShortURL, error) {
+	return m.update(ctx, hash, func(shortURL *ShortURL, base event.Base) event.Event {
+		if !shortURL.Disabled {
+			return nil
+		}
+		return &ShortURLEnabled{Base: base}
+	})
+}
+
+// Delete removes the ShortURL, so it's not found anymore.
+// The hash is not reused for other URLs, as it may still be shared somewhere.
+func (m *ShortURLManager) Delete(ctx context.Context, hash string) error {
+	_, err := m.update(ctx, hash, func(shortURL *ShortURL, base event.Base) event.Event {
+		return &ShortURLDeleted{Base: base}
+	})
+	return err
+}
+
+// ChangeTarget makes the ShortURL redirect to another URL.
+// The new URL is not valid until it's verified again.
+func (m *ShortURLManager) ChangeTarget(ctx context.Context, hash string, aLongURL string) (*ShortURL, error) {
+	aLongURL = m.normalizer.Normalize(aLongURL)
+	if err := checkLongURL(aLongURL); err != nil {
+		return nil, err
+	}
+	return m.update(ctx, hash, func(shortURL *ShortURL, base event.Base) event.Event {
+		if shortURL.OriginalURL.URL == aLongURL {
+			return nil
+		}
+		return &ShortURLTargetChanged{Base: base, OriginalURL: aLongURL}
+	})
+}
+
+// Find returns the ShortURL with its validation status, or ErrShortURLNotFound if it doesn't exist or it was deleted.
+func (m *ShortURLManager) Find(ctx context.Context, hash string) (*ShortURL, error) {
+	shortURL, _, err := m.load(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return shortURL, nil
+}
+
+// update saves the event returned by change for the latest version of the ShortURL, or nothing if it returns nil.
+func (m *ShortURLManager) update(ctx context.Context, hash string, change func(shortURL *ShortURL, base event.Base) event.Event) (*ShortURL, error) {
+	var shortURL *ShortURL
+	err := event.RetryOnConflict(ctx, maxUpdateAttempts, func(ctx context.Context) error {
+		var version int
+		var err error
+		shortURL, version, err = m.load(ctx, hash)
+		if err != nil {
+			return err
+		}
+
+		evt := change(shortURL, event.Base{ID: hash, Version: version + 1, At: m.clock.Now()})
+		if evt == nil {
+			return nil
+		}
+		err = m.repository.Save(ctx, version, evt)
+		if err != nil {
+			return err
+		}
+		return shortURL.On(evt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shortURL, nil
+}
+
+func (m *ShortURLManager) load(ctx context.Context, hash string) (*ShortURL, int, error) {
+	entity, version, err := m.repository.Load(ctx, hash)
+	// the events of another kind of link can't be handled, as the hash is not of a short url
+	if errors.Is(err, event.ErrEntityNotFound) || errors.Is(err, event.ErrUnhandledEvent) {
+		return nil, 0, ErrShortURLNotFound
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load the short url: %w", err)
+	}
+	shortURL, ok := entity.(*ShortURL)
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown entity type loaded from the short url repository: %T", entity)
+	}
+	if shortURL.Deleted {
+		return nil, 0, ErrShortURLNotFound
+	}
+	return shortURL, version, nil
+}
+
+func NewShortURLManager(repository event.Repository, clock event.Clock, options ...ShortenerOption) *ShortURLManager {
+	return &ShortURLManager{
+		repository: repository,
+		clock:      clock,
+		normalizer: newShortenerOptions(options).normalizer,
+	}
+}
@@ -0,0 +1,269 @@
+package url_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	domainmocks "github.com/WebEngineeringGroupI/backend/pkg/domain/event/mocks"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+var _ = Describe("Domain / URL / Short URL manager", func() {
+	var (
+		ctx        context.Context
+		ctrl       *gomock.Controller
+		repository *domainmocks.MockRepository
+		clock      *domainmocks.MockClock
+		manager    *url.ShortURLManager
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+		repository = domainmocks.NewMockRepository(ctrl)
+		clock = domainmocks.NewMockClock(ctrl)
+		manager = url.NewShortURLManager(repository, clock)
+
+		clock.EXPECT().Now().AnyTimes().Return(time.Time{})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	aShortURL := func() *url.ShortURL {
+		return &url.ShortURL{
+			Hash:        "cv6VxVdu",
+			OriginalURL: url.OriginalURL{URL: "https://google.com", IsValid: true},
+		}
+	}
+
+	It("disables a short URL", func() {
+		repository.EXPECT().Load(ctx, "cv6VxVdu").Return(aShortURL(), 1, nil)
+		repository.EXPECT().Save(ctx, 1, &url.ShortURLDisabled{Base: event.Base{ID: "cv6VxVdu", Version: 2}})
+
+		shortURL, err := manager.Disable(ctx, "cv6VxVdu")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURL.Disabled).To(BeTrue())
+	})
+
+	It("doesn't disable a short URL twice", func() {
+		disabled := aShortURL()
+		disabled.Disabled = true
+		repository.EXPECT().Load(ctx, "cv6VxVdu").Return(disabled, 2, nil)
+
+		shortURL, err := manager.Disable(ctx, "cv6VxVdu")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURL.Disabled).To(BeTrue())
+	})
+
+	It("enables a disabled short URL", func() {
+		disabled := aShortURL()
+		disabled.Disabled = true
+		repository.EXPECT().Load(ctx, "cv6VxVdu").Return(disabled, 2, nil)
+		repository.EXPECT().Save(ctx, 2, &url.ShortURLEnabled{Base: event.Base{ID: "cv6VxVdu", Version: 3}})
+
+		shortURL, err := manager.Enable(ctx, "cv6VxVdu")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURL.Disabled).To(BeFalse())
+	})
+
+	It("deletes a short URL", func() {
+		repository.EXPECT().Load(ctx, "cv6VxVdu").Return(aShortURL(), 1, nil)
+		repository.EXPECT().Save(ctx, 1, &url.ShortURLDeleted{Base: event.Base{ID: "cv6VxVdu", Version: 2}})
+
+		Expect(manager.Delete(ctx, "cv6VxVdu")).To(Succeed())
+	})
+
+	It("changes the target of a short URL, which has to be verified again", func() {
+		repository.EXPECT().Load(ctx, "cv6VxVdu").Return(aShortURL(), 1, nil)
+		repository.EXPECT().Save(ctx, 1, &url.ShortURLTargetChanged{Base: event.Base{ID: "cv6VxVdu", Version: 2}, OriginalURL: "https://unizar.es"})
+
+		shortURL, err := manager.ChangeTarget(ctx, "cv6VxVdu", "https://unizar.es")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURL.OriginalURL).To(Equal(url.OriginalURL{URL: "https://unizar.es", IsValid: false}))
+	})
+
+	It("normalizes the new target", func() {
+		repository.EXPECT().Load(ctx, "cv6VxVdu").Return(aShortURL(), 1, nil)
+		repository.EXPECT().Save(ctx, 1, &url.ShortURLTargetChanged{Base: event.Base{ID: "cv6VxVdu", Version: 2}, OriginalURL: "https://unizar.es"})
+
+		shortURL, err := manager.ChangeTarget(ctx, "cv6VxVdu", "https://Unizar.es:443/")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURL.OriginalURL.URL).To(Equal("https://unizar.es"))
+	})
+
+	It("doesn't change the target to the same normalized URL", func() {
+		repository.EXPECT().Load(ctx, "cv6VxVdu").Return(aShortURL(), 1, nil)
+
+		shortURL, err := manager.ChangeTarget(ctx, "cv6VxVdu", "https://GOOGLE.com/")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortURL.OriginalURL).To(Equal(url.OriginalURL{URL: "https://google.com", IsValid: true}))
+	})
+
+	It("normalizes the new target with the configured normalizer", func() {
+		manager = url.NewShortURLManager(repository, clock, url.WithNormalizer(url.NewNormalizer(url.WithoutTrackingParameters())))
+		repository.EXPECT().Load(ctx, "cv6VxVdu").Return(aShortURL(), 1, nil)
+		repository.EXPECT().Save(ctx, 1, &url.ShortURLTargetChanged{Base: event.Base{ID: "cv6VxVdu", Version: 2}, OriginalURL: "https://unizar.es"})
+
+		_, err := manager.ChangeTarget(ctx, "cv6VxVdu", "https://unizar.es/?utm_source=newsletter")
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("rejects an empty target", func() {
+		_, err := manager.ChangeTarget(ctx, "cv6VxVdu", "")
+
+		Expect(err).To(MatchError(url.ErrInvalidLongURLSpecified))
+	})
+
+	When("another event of the short URL is saved concurrently", func() {
+		It("loads the short URL again and saves the change", func() {
+			gomock.InOrder(
+				repository.EXPECT().Load(ctx, "cv6VxVdu").Return(aShortURL(), 1, nil),
+				repository.EXPECT().Save(ctx, 1, gomock.Any()).Return(event.ErrConcurrencyConflict),
+				repository.EXPECT().Load(ctx, "cv6VxVdu").Return(aShortURL(), 2, nil),
+				repository.EXPECT().Save(ctx, 2, &url.ShortURLDisabled{Base: event.Base{ID: "cv6VxVdu", Version: 3}}),
+			)
+
+			_, err := manager.Disable(ctx, "cv6VxVdu")
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("the short URL doesn't exist", func() {
+		It("returns an error", func() {
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, event.ErrEntityNotFound)
+
+			_, err := manager.Disable(ctx, "cv6VxVdu")
+
+			Expect(err).To(MatchError(url.ErrShortURLNotFound))
+		})
+	})
+
+	When("the hash is of a load balanced URL", func() {
+		It("returns that the short URL doesn't exist", func() {
+			repository.EXPECT().Load(ctx, "5XEOqhb0").Return(nil, 0, fmt.Errorf("%w: aggregate was unable to handle event", event.ErrUnhandledEvent))
+
+			_, err := manager.Disable(ctx, "5XEOqhb0")
+
+			Expect(err).To(MatchError(url.ErrShortURLNotFound))
+		})
+	})
+
+	When("the short URL is deleted", func() {
+		It("returns an error", func() {
+			deleted := aShortURL()
+			deleted.Deleted = true
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(deleted, 2, nil)
+
+			_, err := manager.ChangeTarget(ctx, "cv6VxVdu", "https://unizar.es")
+
+			Expect(err).To(MatchError(url.ErrShortURLNotFound))
+		})
+	})
+
+	When("a short URL is verified after its target is changed", func() {
+		It("is not valid until the new target is verified", func() {
+			shortURL := &url.ShortURL{}
+			Expect(shortURL.On(&url.ShortURLCreated{Base: event.Base{ID: "cv6VxVdu", Version: 0}, OriginalURL: "https://google.com"})).To(Succeed())
+			Expect(shortURL.On(&url.ShortURLTargetChanged{Base: event.Base{ID: "cv6VxVdu", Version: 1}, OriginalURL: "https://unizar.es"})).To(Succeed())
+			Expect(shortURL.On(&url.ShortURLVerified{Base: event.Base{ID: "cv6VxVdu", Version: 2}, VerifiedURL: "https://google.com"})).To(Succeed())
+
+			Expect(shortURL.OriginalURL.IsValid).To(BeFalse())
+
+			Expect(shortURL.On(&url.ShortURLVerified{Base: event.Base{ID: "cv6VxVdu", Version: 3}, VerifiedURL: "https://unizar.es"})).To(Succeed())
+
+			Expect(shortURL.OriginalURL.IsValid).To(BeTrue())
+		})
+	})
+
+	Context("finding a short URL", func() {
+		It("returns the short URL", func() {
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(aShortURL(), 1, nil)
+
+			shortURL, err := manager.Find(ctx, "cv6VxVdu")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shortURL).To(Equal(aShortURL()))
+		})
+
+		It("returns an error if the short URL doesn't exist", func() {
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, event.ErrEntityNotFound)
+
+			_, err := manager.Find(ctx, "cv6VxVdu")
+
+			Expect(err).To(MatchError(url.ErrShortURLNotFound))
+		})
+	})
+
+	Context("the validation status of a short URL", func() {
+		var (
+			shortURL  *url.ShortURL
+			createdAt time.Time
+		)
+
+		BeforeEach(func() {
+			createdAt = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+			shortURL = &url.ShortURL{}
+			Expect(shortURL.On(&url.ShortURLCreated{Base: event.Base{ID: "cv6VxVdu", Version: 0, At: createdAt}, OriginalURL: "https://google.com"})).To(Succeed())
+		})
+
+		It("is pending until the original URL is validated", func() {
+			Expect(shortURL.ValidationStatus()).To(Equal(url.ValidationPending))
+			Expect(shortURL.CreatedAt).To(Equal(createdAt))
+			Expect(shortURL.ValidatedAt).To(BeZero())
+		})
+
+		It("is valid once the original URL is verified", func() {
+			verifiedAt := createdAt.Add(time.Second)
+			Expect(shortURL.On(&url.ShortURLVerified{Base: event.Base{ID: "cv6VxVdu", Version: 1, At: verifiedAt}, VerifiedURL: "https://google.com"})).To(Succeed())
+
+			Expect(shortURL.ValidationStatus()).To(Equal(url.ValidationValid))
+			Expect(shortURL.ValidatedAt).To(Equal(verifiedAt))
+		})
+
+		It("is rejected with the reason once the original URL is rejected", func() {
+			rejectedAt := createdAt.Add(time.Second)
+			Expect(shortURL.On(&url.ShortURLRejected{
+				Base:        event.Base{ID: "cv6VxVdu", Version: 1, At: rejectedAt},
+				RejectedURL: "https://google.com",
+				Validator:   "safebrowsing",
+				Reason:      "the url is not valid",
+			})).To(Succeed())
+
+			Expect(shortURL.ValidationStatus()).To(Equal(url.ValidationRejected))
+			Expect(shortURL.Rejection).To(Equal(&url.Rejection{Validator: "safebrowsing", Reason: "the url is not valid"}))
+			Expect(shortURL.ValidatedAt).To(Equal(rejectedAt))
+		})
+
+		It("ignores the rejection of a previous target", func() {
+			Expect(shortURL.On(&url.ShortURLTargetChanged{Base: event.Base{ID: "cv6VxVdu", Version: 1}, OriginalURL: "https://unizar.es"})).To(Succeed())
+			Expect(shortURL.On(&url.ShortURLRejected{Base: event.Base{ID: "cv6VxVdu", Version: 2}, RejectedURL: "https://google.com", Reason: "the url is not valid"})).To(Succeed())
+
+			Expect(shortURL.ValidationStatus()).To(Equal(url.ValidationPending))
+		})
+
+		It("is pending again after the target of a rejected URL is changed", func() {
+			Expect(shortURL.On(&url.ShortURLRejected{Base: event.Base{ID: "cv6VxVdu", Version: 1, At: createdAt}, RejectedURL: "https://google.com", Reason: "the url is not valid"})).To(Succeed())
+			Expect(shortURL.On(&url.ShortURLTargetChanged{Base: event.Base{ID: "cv6VxVdu", Version: 2}, OriginalURL: "https://unizar.es"})).To(Succeed())
+
+			Expect(shortURL.ValidationStatus()).To(Equal(url.ValidationPending))
+			Expect(shortURL.Rejection).To(BeNil())
+			Expect(shortURL.ValidatedAt).To(BeZero())
+		})
+	})
+})
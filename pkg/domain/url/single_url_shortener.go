@@ -2,10 +2,9 @@ package url
 
 import (
 	"context"
-	"crypto/sha1"
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
 )
@@ -15,20 +14,74 @@ var (
 )
 
 type SingleURLShortener struct {
-	repository event.Repository
-	metrics    Metrics
-	clock      event.Clock
+	repository    event.Repository
+	metrics       Metrics
+	clock         event.Clock
+	hashGenerator HashGenerator
+	normalizer    *Normalizer
 }
 
 type OriginalURL struct {
 	URL     string
 	IsValid bool
+	// Weight is the weight of a target of a LoadBalancedURL with the WeightedStrategy, zero otherwise
+	Weight int `json:",omitempty"`
+	// Unhealthy is set while a valid target of a LoadBalancedURL fails its health checks, which makes it invalid
+	Unhealthy bool `json:",omitempty"`
 }
 
 type ShortURL struct {
 	Hash        string
 	OriginalURL OriginalURL
 	Clicks      int
+	// ExpiresAt is when the ShortURL stops redirecting, the zero time if it never expires
+	ExpiresAt time.Time
+	// Expired is set once the expiration of the ShortURL has been recorded
+	Expired bool
+	// Disabled is set while the owner doesn't want the ShortURL to redirect
+	Disabled bool
+	// Deleted is set once the owner has deleted the ShortURL
+	Deleted bool
+	// CreatedAt is when the ShortURL was created
+	CreatedAt time.Time
+	// Rejection is why the validator rejected the original URL, nil unless it was rejected
+	Rejection *Rejection
+	// ValidatedAt is when the original URL was verified or rejected, the zero time while the validation is pending
+	ValidatedAt time.Time
+	// Verdicts are the verdicts of the validators in the last validation of the original URL
+	Verdicts []Verdict
+}
+
+// ValidationStatus is the state of the validation of an original URL
+type ValidationStatus string
+
+const (
+	ValidationPending  ValidationStatus = "pending"
+	ValidationValid    ValidationStatus = "valid"
+	ValidationRejected ValidationStatus = "rejected"
+)
+
+// Rejection tells which validator rejected an original URL and why
+type Rejection struct {
+	Validator string
+	Reason    string
+}
+
+// ValidationStatus tells if the original URL of the ShortURL is still being validated, or if it was verified or rejected
+func (s *ShortURL) ValidationStatus() ValidationStatus {
+	switch {
+	case s.OriginalURL.IsValid:
+		return ValidationValid
+	case s.Rejection != nil:
+		return ValidationRejected
+	default:
+		return ValidationPending
+	}
+}
+
+// IsExpired tells if the ShortURL is expired at the given time.
+func (s *ShortURL) IsExpired(now time.Time) bool {
+	return isExpired(s.ExpiresAt, s.Expired, now)
 }
 
 func shortURLFromEvents(events ...event.Event) *ShortURL {
@@ -45,13 +98,45 @@ func (s *ShortURL) On(evt event.Event) error {
 		s.Hash = e.EntityID()
 		s.OriginalURL = OriginalURL{URL: e.OriginalURL, IsValid: false}
 		s.Clicks = 0
+		s.CreatedAt = e.HappenedOn()
 	case *ShortURLVerified:
+		if e.VerifiedURL != "" && e.VerifiedURL != s.OriginalURL.URL {
+			// the target was changed after it was sent to be verified
+			return nil
+		}
 		s.OriginalURL = OriginalURL{
 			URL:     s.OriginalURL.URL,
 			IsValid: true,
 		}
+		s.Rejection = nil
+		s.ValidatedAt = e.HappenedOn()
+		s.Verdicts = e.Verdicts
+	case *ShortURLRejected:
+		if e.RejectedURL != s.OriginalURL.URL {
+			// the target was changed after it was sent to be verified
+			return nil
+		}
+		s.OriginalURL.IsValid = false
+		s.Rejection = &Rejection{Validator: e.Validator, Reason: e.Reason}
+		s.ValidatedAt = e.HappenedOn()
+		s.Verdicts = e.Verdicts
 	case *ShortURLClicked:
 		s.Clicks++
+	case *ShortURLExpirationScheduled:
+		s.ExpiresAt = e.ExpiresAt
+	case *ShortURLExpired:
+		s.Expired = true
+	case *ShortURLDisabled:
+		s.Disabled = true
+	case *ShortURLEnabled:
+		s.Disabled = false
+	case *ShortURLDeleted:
+		s.Deleted = true
+	case *ShortURLTargetChanged:
+		s.OriginalURL = OriginalURL{URL: e.OriginalURL, IsValid: false}
+		s.Rejection = nil
+		s.ValidatedAt = time.Time{}
+		s.Verdicts = nil
 	default:
 		return event.ErrUnhandledEvent
 	}
@@ -63,30 +148,76 @@ var (
 )
 
 func (s *SingleURLShortener) HashFromURL(ctx context.Context, aLongURL string) (*ShortURL, error) {
+	return s.HashFromURLWithOptions(ctx, aLongURL, ShortURLOptions{})
+}
+
+// HashFromURLWithOptions creates a ShortURL like HashFromURL, but customized with the options.
+// If an alias is specified, it must be valid and not used by a ShortURL with another original URL or expiration.
+func (s *SingleURLShortener) HashFromURLWithOptions(ctx context.Context, aLongURL string, options ShortURLOptions) (*ShortURL, error) {
 	s.metrics.RecordSingleURLMetrics()
+	aLongURL = s.normalizer.Normalize(aLongURL)
+	if err := checkLongURL(aLongURL); err != nil {
+		return nil, err
+	}
+
+	if options.Alias != "" {
+		if err := validateAlias(options.Alias); err != nil {
+			return nil, err
+		}
+	}
+	expiresAt, err := options.Expiration.expiresAt(s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var shortURL *ShortURL
+	err = event.RetryOnConflict(ctx, maxHashAttempts, func(ctx context.Context) error {
+		var err error
+		shortURL, err = s.shortURL(ctx, aLongURL, options.Alias, expiresAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shortURL, nil
+}
 
-	urlHash := hashFromURL(aLongURL)
-	entity, _, err := s.repository.Load(ctx, urlHash)
-	if err == nil {
+func (s *SingleURLShortener) shortURL(ctx context.Context, aLongURL string, alias string, expiresAt time.Time) (*ShortURL, error) {
+	urlHash, entity, err := s.hashFor(ctx, aLongURL, alias, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if entity != nil {
 		shortURL, ok := entity.(*ShortURL)
 		if !ok {
-			return nil, fmt.Errorf("unknown entity returned while hashing from URL: %T", shortURL)
+			return nil, fmt.Errorf("unknown entity returned while hashing from URL: %T", entity)
 		}
 		return shortURL, nil
 	}
 
+	now := s.clock.Now()
 	events := []event.Event{
 		&ShortURLCreated{
 			Base: event.Base{
 				ID:      urlHash,
 				Version: 0,
-				At:      s.clock.Now(),
+				At:      now,
 			},
 			OriginalURL: aLongURL,
 		},
 	}
+	if !expiresAt.IsZero() {
+		events = append(events, &ShortURLExpirationScheduled{
+			Base: event.Base{
+				ID:      urlHash,
+				Version: 1,
+				At:      now,
+			},
+			ExpiresAt: expiresAt,
+		})
+	}
 
-	err = s.repository.Save(ctx, events...)
+	err = s.repository.Save(ctx, event.NewStreamVersion, events...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to save shortURL in the repository: %w", err)
 	}
@@ -94,17 +225,38 @@ func (s *SingleURLShortener) HashFromURL(ctx context.Context, aLongURL string) (
 	return shortURLFromEvents(events...), nil
 }
 
-func hashFromURL(aLongURL string) string {
-	bytes := sha1.Sum([]byte(aLongURL))
-	sum := base64.StdEncoding.EncodeToString(bytes[:])
-	hash := sum[0:8]
-	return hash
+func (s *SingleURLShortener) hashFor(ctx context.Context, aLongURL string, alias string, expiresAt time.Time) (string, event.Entity, error) {
+	if alias == "" {
+		return findHash(ctx, s.repository, s.hashGenerator, hashContentWithExpiration(aLongURL, expiresAt), isShortURLOf(aLongURL, expiresAt))
+	}
+
+	entity, _, err := s.repository.Load(ctx, alias)
+	if errors.Is(err, event.ErrEntityNotFound) {
+		return alias, nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to check if the alias %s is in use: %w", alias, err)
+	}
+	if !isShortURLOf(aLongURL, expiresAt)(entity) {
+		return "", nil, fmt.Errorf("%w: %s", ErrAliasAlreadyInUse, alias)
+	}
+	return alias, entity, nil
+}
+
+func isShortURLOf(aLongURL string, expiresAt time.Time) func(entity event.Entity) bool {
+	return func(entity event.Entity) bool {
+		shortURL, ok := entity.(*ShortURL)
+		return ok && !shortURL.Deleted && !shortURL.Disabled &&
+			shortURL.OriginalURL.URL == aLongURL && shortURL.ExpiresAt.Equal(expiresAt)
+	}
 }
 
-func NewSingleURLShortener(repository event.Repository, clock event.Clock, metrics Metrics) *SingleURLShortener {
+func NewSingleURLShortener(repository event.Repository, clock event.Clock, metrics Metrics, hashGenerator HashGenerator, options ...ShortenerOption) *SingleURLShortener {
 	return &SingleURLShortener{
-		repository: repository,
-		clock:      clock,
-		metrics:    metrics,
+		repository:    repository,
+		clock:         clock,
+		metrics:       metrics,
+		hashGenerator: hashGenerator,
+		normalizer:    newShortenerOptions(options).normalizer,
 	}
 }
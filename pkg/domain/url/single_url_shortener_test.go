@@ -2,10 +2,13 @@ package url_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
@@ -32,7 +35,7 @@ var _ = Describe("Single URL shortener", func() {
 		clock = domainmocks.NewMockClock(ctrl)
 		metrics = mocks.NewMockMetrics(ctrl)
 
-		shortener = url.NewSingleURLShortener(repository, clock, metrics)
+		shortener = url.NewSingleURLShortener(repository, clock, metrics, url.NewURLSafeHashGenerator())
 
 		clock.EXPECT().Now().AnyTimes().Return(time.Time{})
 	})
@@ -45,8 +48,8 @@ var _ = Describe("Single URL shortener", func() {
 
 		It("generates a hash", func() {
 			metrics.EXPECT().RecordSingleURLMetrics().Times(1)
-			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, url.ErrShortURLNotFound)
-			repository.EXPECT().Save(ctx, gomock.Any())
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, event.ErrEntityNotFound)
+			repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any())
 			shortURL, err := shortener.HashFromURL(ctx, "https://google.com")
 
 			Expect(err).ToNot(HaveOccurred())
@@ -55,20 +58,72 @@ var _ = Describe("Single URL shortener", func() {
 
 		It("contains the real value from the original URL", func() {
 			metrics.EXPECT().RecordSingleURLMetrics().Times(1)
-			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, url.ErrShortURLNotFound)
-			repository.EXPECT().Save(ctx, gomock.Any())
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, event.ErrEntityNotFound)
+			repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any())
 			shortURL, err := shortener.HashFromURL(ctx, "https://google.com")
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(shortURL.OriginalURL.URL).To(Equal("https://google.com"))
 		})
 
+		It("generates the same hash for the same normalized URL", func() {
+			metrics.EXPECT().RecordSingleURLMetrics().Times(3)
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, event.ErrEntityNotFound).Times(3)
+			repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).Times(3)
+
+			for _, aLongURL := range []string{"https://Google.com/", "https://google.com", "https://google.com:443/"} {
+				shortURL, err := shortener.HashFromURL(ctx, aLongURL)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURL.Hash).To(Equal("cv6VxVdu"))
+				Expect(shortURL.OriginalURL.URL).To(Equal("https://google.com"))
+			}
+		})
+
+		It("strips the tracking parameters with a normalizer that removes them", func() {
+			shortener = url.NewSingleURLShortener(repository, clock, metrics, url.NewURLSafeHashGenerator(),
+				url.WithNormalizer(url.NewNormalizer(url.WithoutTrackingParameters())))
+			metrics.EXPECT().RecordSingleURLMetrics()
+			repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, event.ErrEntityNotFound)
+			repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any())
+
+			shortURL, err := shortener.HashFromURL(ctx, "https://google.com/?utm_source=newsletter")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shortURL.OriginalURL.URL).To(Equal("https://google.com"))
+		})
+
+		DescribeTable("rejects the URLs that can't be redirected to",
+			func(aLongURL string, reason url.InvalidURLReason) {
+				metrics.EXPECT().RecordSingleURLMetrics()
+
+				shortURL, err := shortener.HashFromURL(ctx, aLongURL)
+
+				Expect(err).To(MatchError(url.ErrInvalidLongURLSpecified))
+				var invalidURLError *url.InvalidURLError
+				Expect(errors.As(err, &invalidURLError)).To(BeTrue())
+				Expect(invalidURLError.Reason).To(Equal(reason))
+				Expect(shortURL).To(BeNil())
+			},
+			Entry("without scheme", "foo", url.UnsupportedScheme),
+			Entry("with an unsupported scheme", "ftp://example.com/file", url.UnsupportedScheme),
+			Entry("with a javascript scheme", "javascript:alert(1)", url.UnsupportedScheme),
+			Entry("without host", "https:///path", url.MissingHost),
+			Entry("malformed", "https://example.com/%zz", url.MalformedURL),
+			Entry("to localhost", "http://localhost:8080/admin", url.PrivateHost),
+			Entry("to a loopback IP", "http://127.0.0.1", url.PrivateHost),
+			Entry("to a private IP", "http://192.168.1.1/router", url.PrivateHost),
+			Entry("to a private IPv6", "http://[fd00::1]/", url.PrivateHost),
+			Entry("to a link-local IP", "http://169.254.169.254/latest/meta-data", url.PrivateHost),
+			Entry("too long", "https://example.com/"+strings.Repeat("a", 2048), url.TooLongURL),
+		)
+
 		Context("when providing different long URLs", func() {
 			It("generates different short URL hashes", func() {
 				metrics.EXPECT().RecordSingleURLMetrics().Times(2)
-				repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, url.ErrShortURLNotFound)
-				repository.EXPECT().Load(ctx, "iEonOBJL").Return(nil, 0, url.ErrShortURLNotFound)
-				repository.EXPECT().Save(ctx, gomock.Any()).Times(2)
+				repository.EXPECT().Load(ctx, "cv6VxVdu").Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Load(ctx, "iEonOBJL").Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).Times(2)
 				shortGoogleURL, err := shortener.HashFromURL(ctx, "https://google.com")
 				Expect(err).ToNot(HaveOccurred())
 
@@ -81,8 +136,8 @@ var _ = Describe("Single URL shortener", func() {
 
 		It("stores the short URL in a repository", func() {
 			metrics.EXPECT().RecordSingleURLMetrics().Times(1)
-			repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(nil, 0, url.ErrShortURLNotFound)
-			repository.EXPECT().Save(ctx, []event.Event{
+			repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(nil, 0, event.ErrEntityNotFound)
+			repository.EXPECT().Save(ctx, event.NewStreamVersion, []event.Event{
 				&url.ShortURLCreated{
 					Base: event.Base{
 						ID:      "2sMi6l0Z",
@@ -102,20 +157,273 @@ var _ = Describe("Single URL shortener", func() {
 			It("just returns it", func() {
 				metrics.EXPECT().RecordSingleURLMetrics().Times(1)
 				repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(&url.ShortURL{
-					Hash: "cv6VxVdu",
+					Hash: "2sMi6l0Z",
 					OriginalURL: url.OriginalURL{
-						URL:     "https://google.com",
-						IsValid: false,
+						URL:     "https://unizar.es",
+						IsValid: true,
 					},
-					Clicks: 0,
+					Clicks: 3,
 				}, 0, nil)
 
 				shortURL, err := shortener.HashFromURL(ctx, "https://unizar.es")
 				Expect(err).ToNot(HaveOccurred())
-				Expect(shortURL.Hash).To(Equal("cv6VxVdu"))
+				Expect(shortURL.Hash).To(Equal("2sMi6l0Z"))
+				Expect(shortURL.OriginalURL.URL).To(Equal("https://unizar.es"))
+				Expect(shortURL.Clicks).To(Equal(3))
+			})
+		})
+
+		When("the URL already exists in the database but it was deleted", func() {
+			It("generates a new short URL with a longer hash", func() {
+				metrics.EXPECT().RecordSingleURLMetrics()
+				repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(&url.ShortURL{
+					Hash:        "2sMi6l0Z",
+					OriginalURL: url.OriginalURL{URL: "https://unizar.es"},
+					Deleted:     true,
+				}, 1, nil)
+				repository.EXPECT().Load(ctx, "2sMi6l0Zq").Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any())
+
+				shortURL, err := shortener.HashFromURL(ctx, "https://unizar.es")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURL.Hash).To(Equal("2sMi6l0Zq"))
+			})
+		})
+
+		When("the hash is already used by another URL", func() {
+			It("generates a longer hash for the URL", func() {
+				metrics.EXPECT().RecordSingleURLMetrics()
+				repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(&url.ShortURL{
+					Hash:        "2sMi6l0Z",
+					OriginalURL: url.OriginalURL{URL: "https://google.com"},
+				}, 0, nil)
+				repository.EXPECT().Load(ctx, "2sMi6l0Zq").Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, []event.Event{
+					&url.ShortURLCreated{
+						Base:        event.Base{ID: "2sMi6l0Zq", Version: 0, At: time.Time{}},
+						OriginalURL: "https://unizar.es",
+					},
+				})
+
+				shortURL, err := shortener.HashFromURL(ctx, "https://unizar.es")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURL.Hash).To(Equal("2sMi6l0Zq"))
+				Expect(shortURL.OriginalURL.URL).To(Equal("https://unizar.es"))
+			})
+
+			When("all the hashes generated are used", func() {
+				It("returns an error", func() {
+					metrics.EXPECT().RecordSingleURLMetrics()
+					repository.EXPECT().Load(ctx, gomock.Any()).Return(&url.ShortURL{
+						OriginalURL: url.OriginalURL{URL: "https://google.com"},
+					}, 0, nil).Times(5)
+
+					shortURL, err := shortener.HashFromURL(ctx, "https://unizar.es")
+
+					Expect(err).To(MatchError(url.ErrHashCollision))
+					Expect(shortURL).To(BeNil())
+				})
+			})
+		})
+
+		When("it's not possible to check if the hash is in use", func() {
+			It("returns the error without saving the URL", func() {
+				metrics.EXPECT().RecordSingleURLMetrics()
+				repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(nil, 0, errors.New("database down"))
+
+				shortURL, err := shortener.HashFromURL(ctx, "https://unizar.es")
+
+				Expect(err).To(MatchError(ContainSubstring("database down")))
+				Expect(shortURL).To(BeNil())
+			})
+		})
+
+		When("the same URL is shortened concurrently", func() {
+			It("returns the short URL saved by the other request", func() {
+				metrics.EXPECT().RecordSingleURLMetrics()
+				gomock.InOrder(
+					repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(nil, 0, event.ErrEntityNotFound),
+					repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).Return(event.ErrConcurrencyConflict),
+					repository.EXPECT().Load(ctx, "2sMi6l0Z").Return(&url.ShortURL{
+						Hash:        "2sMi6l0Z",
+						OriginalURL: url.OriginalURL{URL: "https://unizar.es"},
+					}, 0, nil),
+				)
+
+				shortURL, err := shortener.HashFromURL(ctx, "https://unizar.es")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURL.Hash).To(Equal("2sMi6l0Z"))
+			})
+		})
+
+		Context("and a custom alias", func() {
+			It("uses the alias as the hash", func() {
+				metrics.EXPECT().RecordSingleURLMetrics()
+				repository.EXPECT().Load(ctx, "launch-2026").Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, []event.Event{
+					&url.ShortURLCreated{
+						Base:        event.Base{ID: "launch-2026", Version: 0, At: time.Time{}},
+						OriginalURL: "https://google.com",
+					},
+				})
+
+				shortURL, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{Alias: "launch-2026"})
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURL.Hash).To(Equal("launch-2026"))
 				Expect(shortURL.OriginalURL.URL).To(Equal("https://google.com"))
+			})
 
+			DescribeTable("rejects the invalid aliases",
+				func(alias string) {
+					metrics.EXPECT().RecordSingleURLMetrics()
+
+					shortURL, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{Alias: alias})
+
+					Expect(err).To(MatchError(url.ErrInvalidAlias))
+					Expect(shortURL).To(BeNil())
+				},
+				Entry("too short", "ab"),
+				Entry("too long", strings.Repeat("a", 65)),
+				Entry("with invalid characters", "launch/2026"),
+				Entry("with spaces", "launch 2026"),
+				Entry("reserved", "metrics"),
+				Entry("reserved in other case", "CSV"),
+			)
+
+			When("the alias is already used by another URL", func() {
+				It("returns an error", func() {
+					metrics.EXPECT().RecordSingleURLMetrics()
+					repository.EXPECT().Load(ctx, "launch-2026").Return(&url.ShortURL{
+						Hash:        "launch-2026",
+						OriginalURL: url.OriginalURL{URL: "https://unizar.es"},
+					}, 0, nil)
+
+					_, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{Alias: "launch-2026"})
+
+					Expect(err).To(MatchError(url.ErrAliasAlreadyInUse))
+				})
+			})
+
+			When("the alias is already used by the same URL", func() {
+				It("just returns it", func() {
+					metrics.EXPECT().RecordSingleURLMetrics()
+					repository.EXPECT().Load(ctx, "launch-2026").Return(&url.ShortURL{
+						Hash:        "launch-2026",
+						OriginalURL: url.OriginalURL{URL: "https://google.com"},
+					}, 0, nil)
+
+					shortURL, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{Alias: "launch-2026"})
+
+					Expect(err).ToNot(HaveOccurred())
+					Expect(shortURL.Hash).To(Equal("launch-2026"))
+				})
+			})
+
+			When("the alias is taken while it's being created", func() {
+				It("returns an error", func() {
+					metrics.EXPECT().RecordSingleURLMetrics()
+					gomock.InOrder(
+						repository.EXPECT().Load(ctx, "launch-2026").Return(nil, 0, event.ErrEntityNotFound),
+						repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any()).Return(event.ErrConcurrencyConflict),
+						repository.EXPECT().Load(ctx, "launch-2026").Return(&url.ShortURL{
+							Hash:        "launch-2026",
+							OriginalURL: url.OriginalURL{URL: "https://unizar.es"},
+						}, 0, nil),
+					)
+
+					_, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{Alias: "launch-2026"})
+
+					Expect(err).To(MatchError(url.ErrAliasAlreadyInUse))
+				})
 			})
+
+			When("it's not possible to check if the alias is in use", func() {
+				It("returns the error without saving the URL", func() {
+					metrics.EXPECT().RecordSingleURLMetrics()
+					repository.EXPECT().Load(ctx, "launch-2026").Return(nil, 0, errors.New("database down"))
+
+					shortURL, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{Alias: "launch-2026"})
+
+					Expect(err).To(MatchError(ContainSubstring("database down")))
+					Expect(shortURL).To(BeNil())
+				})
+			})
+		})
+
+		Context("and an expiration", func() {
+			It("schedules the expiration after the TTL", func() {
+				metrics.EXPECT().RecordSingleURLMetrics()
+				repository.EXPECT().Load(ctx, "launch-2026").Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, []event.Event{
+					&url.ShortURLCreated{
+						Base:        event.Base{ID: "launch-2026", Version: 0, At: time.Time{}},
+						OriginalURL: "https://google.com",
+					},
+					&url.ShortURLExpirationScheduled{
+						Base:      event.Base{ID: "launch-2026", Version: 1, At: time.Time{}},
+						ExpiresAt: time.Time{}.Add(time.Hour),
+					},
+				})
+
+				shortURL, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{
+					Alias:      "launch-2026",
+					Expiration: url.Expiration{TTL: time.Hour},
+				})
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURL.ExpiresAt).To(Equal(time.Time{}.Add(time.Hour)))
+				Expect(shortURL.IsExpired(time.Time{})).To(BeFalse())
+				Expect(shortURL.IsExpired(time.Time{}.Add(time.Hour))).To(BeTrue())
+			})
+
+			It("generates a different hash than the URL without expiration", func() {
+				metrics.EXPECT().RecordSingleURLMetrics()
+				repository.EXPECT().Load(ctx, gomock.Not("cv6VxVdu")).Return(nil, 0, event.ErrEntityNotFound)
+				repository.EXPECT().Save(ctx, event.NewStreamVersion, gomock.Any())
+
+				shortURL, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{
+					Expiration: url.Expiration{At: time.Time{}.Add(24 * time.Hour)},
+				})
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shortURL.Hash).ToNot(Equal("cv6VxVdu"))
+				Expect(shortURL.ExpiresAt).To(Equal(time.Time{}.Add(24 * time.Hour)))
+			})
+
+			When("the alias is used by the same URL with another expiration", func() {
+				It("returns an error", func() {
+					metrics.EXPECT().RecordSingleURLMetrics()
+					repository.EXPECT().Load(ctx, "launch-2026").Return(&url.ShortURL{
+						Hash:        "launch-2026",
+						OriginalURL: url.OriginalURL{URL: "https://google.com"},
+					}, 0, nil)
+
+					_, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{
+						Alias:      "launch-2026",
+						Expiration: url.Expiration{TTL: time.Hour},
+					})
+
+					Expect(err).To(MatchError(url.ErrAliasAlreadyInUse))
+				})
+			})
+
+			DescribeTable("rejects the invalid expirations",
+				func(expiration url.Expiration) {
+					metrics.EXPECT().RecordSingleURLMetrics()
+
+					shortURL, err := shortener.HashFromURLWithOptions(ctx, "https://google.com", url.ShortURLOptions{Expiration: expiration})
+
+					Expect(err).To(MatchError(url.ErrInvalidExpiration))
+					Expect(shortURL).To(BeNil())
+				},
+				Entry("in the past", url.Expiration{At: time.Time{}.Add(-time.Minute)}),
+				Entry("with a negative TTL", url.Expiration{TTL: -time.Hour}),
+				Entry("with both a time and a TTL", url.Expiration{At: time.Time{}.Add(time.Hour), TTL: time.Hour}),
+			)
 		})
 
 		// TODO(german): Each time a new hash is generated, do we need to check if it already exists?
@@ -0,0 +1,76 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidStrategy = errors.New("invalid load balancing strategy")
+)
+
+// Strategy is how a LoadBalancedURL chooses the target each visitor is redirected to
+type Strategy string
+
+const (
+	RandomStrategy Strategy = "random"
+	// RoundRobinStrategy chooses the targets in turns
+	RoundRobinStrategy Strategy = "round_robin"
+	// WeightedStrategy chooses the targets randomly, proportionally to their weights
+	WeightedStrategy Strategy = "weighted"
+	// StickyStrategy always chooses the same target for the same client IP, while it's valid
+	StickyStrategy Strategy = "sticky"
+)
+
+// maxWeight limits the weight of each target of a WeightedStrategy
+const maxWeight = 1000
+
+// OrDefault returns the RandomStrategy for the LoadBalancedURLs without a strategy,
+// like the ones created before the strategies could be chosen.
+func (s Strategy) OrDefault() Strategy {
+	if s == "" {
+		return RandomStrategy
+	}
+	return s
+}
+
+func validateStrategy(strategy Strategy, weights []int, numberOfURLs int) error {
+	switch strategy.OrDefault() {
+	case RandomStrategy, RoundRobinStrategy, StickyStrategy:
+		if len(weights) != 0 {
+			return fmt.Errorf("%w: weights can only be given to the %s strategy", ErrInvalidStrategy, WeightedStrategy)
+		}
+	case WeightedStrategy:
+		if len(weights) != numberOfURLs {
+			return fmt.Errorf("%w: expected %d weights but found %d", ErrInvalidStrategy, numberOfURLs, len(weights))
+		}
+		for _, weight := range weights {
+			if weight < 1 || weight > maxWeight {
+				return fmt.Errorf("%w: the weights must be between 1 and %d", ErrInvalidStrategy, maxWeight)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown strategy %q", ErrInvalidStrategy, strategy)
+	}
+	return nil
+}
+
+// hashContentWithStrategy adds the strategy to the content hashed for a LoadBalancedURL, so the same URLs
+// can be balanced with different strategies. The default strategy is not added, so the hashes of the
+// LoadBalancedURLs created before the strategies could be chosen don't change.
+func hashContentWithStrategy(content string, strategy Strategy, weights []int) string {
+	if strategy.OrDefault() == RandomStrategy {
+		return content
+	}
+	content = fmt.Sprintf("%s#strategy=%s", content, strategy)
+	if len(weights) == 0 {
+		return content
+	}
+	formatted := make([]string, 0, len(weights))
+	for _, weight := range weights {
+		formatted = append(formatted, strconv.Itoa(weight))
+	}
+	return fmt.Sprintf("%s#weights=%s", content, strings.Join(formatted, ","))
+}
@@ -10,9 +10,14 @@ import (
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
 )
 
+// maxSaveAttempts is the number of times a validation is saved again when other events
+// of the same entity have been saved concurrently.
+const maxSaveAttempts = 5
+
 type Service struct {
-	eventRepo      event.Repository
-	brokerReceiver redirector.ExternalBrokerReceiver
+	shortURLRepository        event.Repository
+	loadBalancedURLRepository event.Repository
+	brokerReceiver            redirector.ExternalBrokerReceiver
 	// FIXME(fede): Some refactor in the future, the serializer could be embedded in the broker receiver, thus, only receiving events and not byte slices.
 	serializer event.Serializer
 }
@@ -33,19 +38,38 @@ func (s *Service) Start(ctx context.Context) error {
 }
 
 func (s *Service) handleEvent(ctx context.Context, evt event.Event) {
+	var err error
 	switch e := evt.(type) {
-	case *url.ShortURLVerified, *url.LoadBalancedURLVerified:
-		err := s.eventRepo.Save(ctx, e)
+	case *url.ShortURLVerified:
+		err = s.appendEvent(ctx, s.shortURLRepository, e, &e.Base)
+	case *url.ShortURLRejected:
+		err = s.appendEvent(ctx, s.shortURLRepository, e, &e.Base)
+	case *url.LoadBalancedURLVerified:
+		err = s.appendEvent(ctx, s.loadBalancedURLRepository, e, &e.Base)
+	}
+	if err != nil {
+		log.Printf("unable to save event in the repository: %s", err)
+	}
+}
+
+// appendEvent saves the event after the latest version of its entity. The version computed by the validator
+// is not used, as other events of the entity may have been saved since it was created.
+func (s *Service) appendEvent(ctx context.Context, repository event.Repository, evt event.Event, base *event.Base) error {
+	return event.RetryOnConflict(ctx, maxSaveAttempts, func(ctx context.Context) error {
+		_, version, err := repository.Load(ctx, evt.EntityID())
 		if err != nil {
-			log.Printf("unable to save event in the repository: %s", err)
+			return fmt.Errorf("unable to load the entity %s: %w", evt.EntityID(), err)
 		}
-	}
+		base.Version = version + 1
+		return repository.Save(ctx, version, evt)
+	})
 }
 
-func NewService(eventRepo event.Repository, brokerReceiver redirector.ExternalBrokerReceiver, eventSerializer event.Serializer) *Service {
+func NewService(shortURLRepository, loadBalancedURLRepository event.Repository, brokerReceiver redirector.ExternalBrokerReceiver, eventSerializer event.Serializer) *Service {
 	return &Service{
-		eventRepo:      eventRepo,
-		brokerReceiver: brokerReceiver,
-		serializer:     eventSerializer,
+		shortURLRepository:        shortURLRepository,
+		loadBalancedURLRepository: loadBalancedURLRepository,
+		brokerReceiver:            brokerReceiver,
+		serializer:                eventSerializer,
 	}
 }
@@ -21,43 +21,88 @@ import (
 
 var _ = Describe("ValidationSaver", func() {
 	var (
-		ctx                    context.Context
-		ctrl                   *gomock.Controller
-		validationSaverService *validationsaver.Service
-		eventRepo              *eventmocks.MockRepository
-		brokerReceiver         *mocks.MockExternalBrokerReceiver
-		logger                 *strings.Builder
+		ctx                       context.Context
+		ctrl                      *gomock.Controller
+		validationSaverService    *validationsaver.Service
+		shortURLRepository        *eventmocks.MockRepository
+		loadBalancedURLRepository *eventmocks.MockRepository
+		brokerReceiver            *mocks.MockExternalBrokerReceiver
+		logger                    *strings.Builder
 	)
 	BeforeEach(func() {
 		ctx = context.Background()
 		ctrl = gomock.NewController(GinkgoT())
-		eventRepo = eventmocks.NewMockRepository(ctrl)
+		shortURLRepository = eventmocks.NewMockRepository(ctrl)
+		loadBalancedURLRepository = eventmocks.NewMockRepository(ctrl)
 		brokerReceiver = mocks.NewMockExternalBrokerReceiver(ctrl)
 		logger = &strings.Builder{}
 		log.Default().SetOutput(logger)
 
-		validationSaverService = validationsaver.NewService(eventRepo, brokerReceiver, json.NewSerializer(&url.ShortURLVerified{}, &url.LoadBalancedURLVerified{}))
+		validationSaverService = validationsaver.NewService(shortURLRepository, loadBalancedURLRepository, brokerReceiver, json.NewSerializer(&url.ShortURLVerified{}, &url.ShortURLRejected{}, &url.LoadBalancedURLVerified{}))
 	})
 	AfterEach(func() {
 		ctrl.Finish()
 	})
 
-	DescribeTable("receives a validation event", func(eventReceived event.Event) {
+	DescribeTable("receives a validation event", func(eventReceived event.Event, repository func() *eventmocks.MockRepository) {
 		brokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(eventReceived), nil)
-		eventRepo.EXPECT().Save(ctx, eventReceived).Return(nil)
+		repository().EXPECT().Load(ctx, "someID").Return(nil, 0, nil)
+		repository().EXPECT().Save(ctx, 0, eventReceived).Return(nil)
 
 		err := validationSaverService.Start(ctx)
 
 		Expect(err).ToNot(HaveOccurred())
 		Consistently(logger.String()).ShouldNot(ContainSubstring("unable"))
 	},
-		Entry("receives a shortURLVerified event", shortURLVerifiedEvent()),
-		Entry("receives a loadBalancedURLVerified event", loadBalancedURLVerifiedEvent()),
+		Entry("receives a shortURLVerified event", shortURLVerifiedEvent(), func() *eventmocks.MockRepository { return shortURLRepository }),
+		Entry("receives a shortURLRejected event", shortURLRejectedEvent(), func() *eventmocks.MockRepository { return shortURLRepository }),
+		Entry("receives a loadBalancedURLVerified event", loadBalancedURLVerifiedEvent(), func() *eventmocks.MockRepository { return loadBalancedURLRepository }),
 	)
 
-	When("receives a single url validated event", func() {
-		It("saves the aggregate data in the database", func() {
+	When("other events of the entity were saved after it was created", func() {
+		It("saves the validation after the latest version of the entity", func() {
+			brokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(shortURLVerifiedEvent()), nil)
+			shortURLRepository.EXPECT().Load(ctx, "someID").Return(nil, 3, nil)
+			shortURLRepository.EXPECT().Save(ctx, 3, &url.ShortURLVerified{
+				Base: event.Base{ID: "someID", Version: 4, At: time.Time{}},
+			}).Return(nil)
 
+			err := validationSaverService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Consistently(logger.String()).ShouldNot(ContainSubstring("unable"))
+		})
+	})
+
+	When("another event of the entity is saved concurrently", func() {
+		It("loads the entity again and saves the validation", func() {
+			brokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(loadBalancedURLVerifiedEvent()), nil)
+			gomock.InOrder(
+				loadBalancedURLRepository.EXPECT().Load(ctx, "someID").Return(nil, 1, nil),
+				loadBalancedURLRepository.EXPECT().Save(ctx, 1, gomock.Any()).Return(event.ErrConcurrencyConflict),
+				loadBalancedURLRepository.EXPECT().Load(ctx, "someID").Return(nil, 2, nil),
+				loadBalancedURLRepository.EXPECT().Save(ctx, 2, &url.LoadBalancedURLVerified{
+					Base:        event.Base{ID: "someID", Version: 3, At: time.Time{}},
+					VerifiedURL: "someURL",
+				}).Return(nil),
+			)
+
+			err := validationSaverService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Consistently(logger.String()).ShouldNot(ContainSubstring("unable"))
+		})
+	})
+
+	When("the entity can't be loaded", func() {
+		It("logs the error", func() {
+			brokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(shortURLVerifiedEvent()), nil)
+			shortURLRepository.EXPECT().Load(ctx, "someID").Return(nil, 0, event.ErrEntityNotFound)
+
+			err := validationSaverService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logger.String()).To(ContainSubstring("unable to save event in the repository"))
 		})
 	})
 })
@@ -83,6 +128,19 @@ func shortURLVerifiedEvent() *url.ShortURLVerified {
 	}
 }
 
+func shortURLRejectedEvent() *url.ShortURLRejected {
+	return &url.ShortURLRejected{
+		Base: event.Base{
+			ID:      "someID",
+			Version: 1,
+			At:      time.Time{},
+		},
+		RejectedURL: "someURL",
+		Validator:   "safebrowsing",
+		Reason:      "the url is not valid",
+	}
+}
+
 func eventPayload(event event.Event) []byte {
 	data, _ := json.NewSerializer(event).MarshalEvent(event)
 	return data
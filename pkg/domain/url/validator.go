@@ -3,11 +3,37 @@ package url
 import (
 	"context"
 	"errors"
+	"fmt"
 )
 
-var ErrUnableToValidateURLs = errors.New("unable to validate URLs")
+var (
+	ErrUnableToValidateURLs = errors.New("unable to validate URLs")
+	ErrURLRejected          = errors.New("url rejected")
+)
+
+// RejectionError is returned along with false by the validators that can tell which of their checks rejected
+// the URLs and why. It wraps ErrURLRejected, so it can be checked with errors.Is.
+type RejectionError struct {
+	Validator string
+	Reason    string
+}
+
+func (e *RejectionError) Error() string {
+	return fmt.Sprintf("%s by %s: %s", ErrURLRejected, e.Validator, e.Reason)
+}
+
+func (e *RejectionError) Unwrap() error {
+	return ErrURLRejected
+}
 
 //go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
 type Validator interface {
 	ValidateURLs(ctx context.Context, url []string) (bool, error)
 }
+
+// VerdictValidator is a Validator that reports the verdict of each of the validators it's made of
+type VerdictValidator interface {
+	Validator
+	// Verdicts reports the verdict of each of the validators it's made of for the URLs, in the order they were run
+	Verdicts(ctx context.Context, urls []string) []Verdict
+}
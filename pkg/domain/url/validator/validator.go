@@ -2,6 +2,8 @@ package validator
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
@@ -37,40 +39,108 @@ func (s *Service) Start(ctx context.Context) error {
 func (s *Service) handleEvent(ctx context.Context, evt event.Event) {
 	switch e := evt.(type) {
 	case *url.ShortURLCreated:
-		isValid, err := s.urlValidator.ValidateURLs(ctx, []string{e.OriginalURL})
+		s.validateShortURL(ctx, e, e.OriginalURL)
+	case *url.ShortURLTargetChanged:
+		s.validateShortURL(ctx, e, e.OriginalURL)
+	case *url.LoadBalancedURLCreated:
+		s.validateLoadBalancedURLs(ctx, e, e.OriginalURLs)
+	case *url.LoadBalancedURLTargetAdded:
+		s.validateLoadBalancedURLs(ctx, e, []string{e.OriginalURL})
+	case *url.LoadBalancedURLTargetsReplaced:
+		s.validateLoadBalancedURLs(ctx, e, e.OriginalURLs)
+	}
+}
+
+func (s *Service) validateLoadBalancedURLs(ctx context.Context, evt event.Event, originalURLs []string) {
+	verifiedURLs := 0
+	for _, originalURL := range originalURLs {
+		result, err := s.validate(ctx, originalURL)
 		if err != nil {
-			log.Printf("unable to validate URL %s: %s", e.OriginalURL, err)
-			return
+			log.Printf("unable to validate URL %s: %s", originalURL, err)
+			continue
 		}
-		if isValid {
-			log.Printf("validated url: %s", e.OriginalURL)
-			s.sendEvent(ctx, &url.ShortURLVerified{
-				Base: event.Base{
-					ID:      e.EntityID(),
-					Version: e.EventVersion() + 1,
-					At:      s.clock.Now(),
-				},
-			})
+		if result.rejection != nil {
+			log.Printf("rejected url %s: %s", originalURL, result.rejection.Reason)
+			continue
 		}
-	case *url.LoadBalancedURLCreated:
-		for idx, originalURL := range e.OriginalURLs {
-			isValid, err := s.urlValidator.ValidateURLs(ctx, []string{originalURL})
-			if err != nil {
-				log.Printf("unable to validate URL %s: %s", originalURL, err)
-				return
-			}
-			if isValid {
-				log.Printf("validated url: %s", originalURL)
-				s.sendEvent(ctx, &url.LoadBalancedURLVerified{
-					Base: event.Base{
-						ID:      e.EntityID(),
-						Version: e.EventVersion() + idx + 1,
-						At:      s.clock.Now(),
-					},
-					VerifiedURL: originalURL,
-				})
-			}
+		log.Printf("validated url: %s", originalURL)
+		verifiedURLs++
+		s.sendEvent(ctx, &url.LoadBalancedURLVerified{
+			Base: event.Base{
+				ID:      evt.EntityID(),
+				Version: evt.EventVersion() + verifiedURLs,
+				At:      s.clock.Now(),
+			},
+			VerifiedURL: originalURL,
+			Verdicts:    result.verdicts,
+		})
+	}
+}
+
+func (s *Service) validateShortURL(ctx context.Context, evt event.Event, originalURL string) {
+	result, err := s.validate(ctx, originalURL)
+	if err != nil {
+		log.Printf("unable to validate URL %s: %s", originalURL, err)
+		return
+	}
+	base := event.Base{
+		ID:      evt.EntityID(),
+		Version: evt.EventVersion() + 1,
+		At:      s.clock.Now(),
+	}
+	if result.rejection != nil {
+		log.Printf("rejected url %s: %s", originalURL, result.rejection.Reason)
+		s.sendEvent(ctx, &url.ShortURLRejected{
+			Base:        base,
+			RejectedURL: originalURL,
+			Validator:   result.rejection.Validator,
+			Reason:      result.rejection.Reason,
+			Verdicts:    result.verdicts,
+		})
+		return
+	}
+	log.Printf("validated url: %s", originalURL)
+	s.sendEvent(ctx, &url.ShortURLVerified{
+		Base:        base,
+		VerifiedURL: originalURL,
+		Verdicts:    result.verdicts,
+	})
+}
+
+// validation is the result of the validation of an original URL
+type validation struct {
+	// verdicts are only reported by the url.VerdictValidator validators
+	verdicts []url.Verdict
+	// rejection is why the URL was rejected, nil if it's valid
+	rejection *url.RejectionError
+}
+
+// validate returns an error only if the URL can't be validated, so it's neither verified nor rejected until it's
+// validated again. The validators that don't tell why they reject the URLs just return false.
+func (s *Service) validate(ctx context.Context, originalURL string) (*validation, error) {
+	if verdictValidator, ok := s.urlValidator.(url.VerdictValidator); ok {
+		result := &validation{verdicts: verdictValidator.Verdicts(ctx, []string{originalURL})}
+		if failed, isFailed := url.FirstFailedVerdict(result.verdicts); isFailed {
+			result.rejection = &url.RejectionError{Validator: failed.Validator, Reason: failed.Reason}
+			return result, nil
+		}
+		if errored, isErrored := url.FirstErrorVerdict(result.verdicts); isErrored {
+			return nil, fmt.Errorf("%w: %s was unable to check it: %s", url.ErrUnableToValidateURLs, errored.Validator, errored.Reason)
 		}
+		return result, nil
+	}
+
+	isValid, err := s.urlValidator.ValidateURLs(ctx, []string{originalURL})
+	var rejection *url.RejectionError
+	switch {
+	case errors.As(err, &rejection):
+		return &validation{rejection: rejection}, nil
+	case err != nil:
+		return nil, err
+	case !isValid:
+		return &validation{rejection: &url.RejectionError{Reason: "the url is not valid"}}, nil
+	default:
+		return &validation{}, nil
 	}
 }
 
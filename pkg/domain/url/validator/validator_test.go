@@ -39,7 +39,7 @@ var _ = Describe("Validator", func() {
 		externalBrokerSender = mocks.NewMockExternalBrokerSender(ctrl)
 		urlValidator = urlmocks.NewMockValidator(ctrl)
 		clock = eventmocks.NewMockClock(ctrl)
-		serializer = json.NewSerializer(&url.ShortURLCreated{}, &url.LoadBalancedURLCreated{})
+		serializer = json.NewSerializer(&url.ShortURLCreated{}, &url.ShortURLTargetChanged{}, &url.LoadBalancedURLCreated{}, &url.LoadBalancedURLTargetAdded{}, &url.LoadBalancedURLTargetsReplaced{})
 		logger = &strings.Builder{}
 		log.Default().SetOutput(logger)
 
@@ -65,14 +65,144 @@ var _ = Describe("Validator", func() {
 			Consistently(logger.String()).ShouldNot(ContainSubstring("unable"))
 		},
 		Entry("retrieves a shortURLCreated event and is valid",
-			shortURLCreatedEvent("someURL"), true, shortURLVerifiedEvent()),
+			shortURLCreatedEvent("someURL"), true, shortURLVerifiedEvent("someURL", 1)),
 		Entry("retrieves a shortURLCreated event and is not valid",
-			shortURLCreatedEvent("someURL"), false),
+			shortURLCreatedEvent("someURL"), false, shortURLRejectedEvent("someURL", 1, "", "the url is not valid")),
+		Entry("retrieves a shortURLTargetChanged event and is valid",
+			shortURLTargetChangedEvent("anotherURL", 3), true, shortURLVerifiedEvent("anotherURL", 4)),
+		Entry("retrieves a shortURLTargetChanged event and is not valid",
+			shortURLTargetChangedEvent("anotherURL", 3), false, shortURLRejectedEvent("anotherURL", 4, "", "the url is not valid")),
 		Entry("retrieves a loadBalancedURLCreated event and is valid",
 			loadBalancedURLCreatedEvent([]string{"someURL1", "someURL2"}), true, loadBalancedURLVerifiedEvent("someURL1", 1), loadBalancedURLVerifiedEvent("someURL2", 2)),
 		Entry("retrieves a loadBalancedURLCreated event and is not valid",
 			loadBalancedURLCreatedEvent([]string{"someURL1", "someURL2"}), false),
+		Entry("retrieves a loadBalancedURLTargetAdded event and is valid",
+			loadBalancedURLTargetAddedEvent("someURL3", 4), true, loadBalancedURLVerifiedEvent("someURL3", 5)),
+		Entry("retrieves a loadBalancedURLTargetAdded event and is not valid",
+			loadBalancedURLTargetAddedEvent("someURL3", 4), false),
+		Entry("retrieves a loadBalancedURLTargetsReplaced event and is valid",
+			loadBalancedURLTargetsReplacedEvent([]string{"someURL3", "someURL4"}, 4), true, loadBalancedURLVerifiedEvent("someURL3", 5), loadBalancedURLVerifiedEvent("someURL4", 6)),
+		Entry("retrieves a loadBalancedURLTargetsReplaced event and is not valid",
+			loadBalancedURLTargetsReplacedEvent([]string{"someURL3", "someURL4"}, 4), false),
 	)
+
+	When("the validator tells why it rejects the URL", func() {
+		It("sends the rejection with the validator and the reason", func() {
+			externalBrokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(shortURLCreatedEvent("someURL")), nil)
+			urlValidator.EXPECT().ValidateURLs(ctx, []string{"someURL"}).Return(false, &url.RejectionError{Validator: "reachable", Reason: "connection refused"})
+			externalBrokerSender.EXPECT().SendEvents(ctx, [][]byte{eventPayload(shortURLRejectedEvent("someURL", 1, "reachable", "connection refused"))}).Return(nil)
+
+			err := validatorService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Consistently(logger.String()).ShouldNot(ContainSubstring("unable"))
+		})
+
+		It("keeps validating the other URLs of a load balanced URL", func() {
+			externalBrokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(loadBalancedURLCreatedEvent([]string{"someURL1", "someURL2"})), nil)
+			urlValidator.EXPECT().ValidateURLs(ctx, []string{"someURL1"}).Return(false, &url.RejectionError{Validator: "reachable", Reason: "connection refused"})
+			urlValidator.EXPECT().ValidateURLs(ctx, []string{"someURL2"}).Return(true, nil)
+			externalBrokerSender.EXPECT().SendEvents(ctx, [][]byte{eventPayload(loadBalancedURLVerifiedEvent("someURL2", 1))}).Return(nil)
+
+			err := validatorService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("the validator reports the verdict of each of its validators", func() {
+		var verdictValidator *urlmocks.MockVerdictValidator
+
+		BeforeEach(func() {
+			verdictValidator = urlmocks.NewMockVerdictValidator(ctrl)
+			validatorService = validator.NewService(externalBrokerReceiver, externalBrokerSender, verdictValidator, serializer, clock)
+		})
+
+		It("sends the verdicts with the verification", func() {
+			verdicts := []url.Verdict{
+				{Validator: "schema", Result: url.VerdictPass, Latency: time.Millisecond},
+				{Validator: "reachable", Result: url.VerdictPass, Latency: time.Second},
+			}
+			externalBrokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(shortURLCreatedEvent("someURL")), nil)
+			verdictValidator.EXPECT().Verdicts(ctx, []string{"someURL"}).Return(verdicts)
+			verified := shortURLVerifiedEvent("someURL", 1)
+			verified.Verdicts = verdicts
+			externalBrokerSender.EXPECT().SendEvents(ctx, [][]byte{eventPayload(verified)}).Return(nil)
+
+			err := validatorService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("sends the verdicts with the rejection of the first validator that rejected it", func() {
+			verdicts := []url.Verdict{
+				{Validator: "schema", Result: url.VerdictPass},
+				{Validator: "reachable", Result: url.VerdictError, Reason: "connection refused"},
+				{Validator: "safebrowsing", Result: url.VerdictFail, Reason: "the url is not valid"},
+			}
+			externalBrokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(shortURLCreatedEvent("someURL")), nil)
+			verdictValidator.EXPECT().Verdicts(ctx, []string{"someURL"}).Return(verdicts)
+			rejected := shortURLRejectedEvent("someURL", 1, "safebrowsing", "the url is not valid")
+			rejected.Verdicts = verdicts
+			externalBrokerSender.EXPECT().SendEvents(ctx, [][]byte{eventPayload(rejected)}).Return(nil)
+
+			err := validatorService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("doesn't send any event if a validator was unable to check it and none rejected it", func() {
+			verdicts := []url.Verdict{
+				{Validator: "schema", Result: url.VerdictPass},
+				{Validator: "safebrowsing", Result: url.VerdictError, Reason: "service unavailable"},
+			}
+			externalBrokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(shortURLCreatedEvent("someURL")), nil)
+			verdictValidator.EXPECT().Verdicts(ctx, []string{"someURL"}).Return(verdicts)
+
+			err := validatorService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logger.String()).To(ContainSubstring("unable to validate URL someURL"))
+			Expect(logger.String()).To(ContainSubstring("safebrowsing was unable to check it: service unavailable"))
+		})
+
+		It("sends the verdicts with the verification of each load balanced URL", func() {
+			verdicts := []url.Verdict{{Validator: "schema", Result: url.VerdictPass}}
+			externalBrokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(loadBalancedURLTargetAddedEvent("someURL3", 4)), nil)
+			verdictValidator.EXPECT().Verdicts(ctx, []string{"someURL3"}).Return(verdicts)
+			verified := loadBalancedURLVerifiedEvent("someURL3", 5)
+			verified.Verdicts = verdicts
+			externalBrokerSender.EXPECT().SendEvents(ctx, [][]byte{eventPayload(verified)}).Return(nil)
+
+			err := validatorService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("the URL can't be validated", func() {
+		It("doesn't send any event", func() {
+			externalBrokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(shortURLCreatedEvent("someURL")), nil)
+			urlValidator.EXPECT().ValidateURLs(ctx, []string{"someURL"}).Return(false, url.ErrUnableToValidateURLs)
+
+			err := validatorService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logger.String()).To(ContainSubstring("unable to validate URL someURL"))
+		})
+
+		It("keeps validating the other URLs of a load balanced URL", func() {
+			externalBrokerReceiver.EXPECT().ReceiveEvents(ctx).Return(channelWithEvents(loadBalancedURLCreatedEvent([]string{"someURL1", "someURL2"})), nil)
+			urlValidator.EXPECT().ValidateURLs(ctx, []string{"someURL1"}).Return(false, url.ErrUnableToValidateURLs)
+			urlValidator.EXPECT().ValidateURLs(ctx, []string{"someURL2"}).Return(true, nil)
+			externalBrokerSender.EXPECT().SendEvents(ctx, [][]byte{eventPayload(loadBalancedURLVerifiedEvent("someURL2", 1))}).Return(nil)
+
+			err := validatorService.Start(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logger.String()).To(ContainSubstring("unable to validate URL someURL1"))
+		})
+	})
 })
 
 func loadBalancedURLVerifiedEvent(verifiedURL string, version int) *url.LoadBalancedURLVerified {
@@ -97,13 +227,60 @@ func loadBalancedURLCreatedEvent(originalURLs []string) *url.LoadBalancedURLCrea
 	}
 }
 
-func shortURLVerifiedEvent() *url.ShortURLVerified {
+func loadBalancedURLTargetAddedEvent(originalURL string, version int) *url.LoadBalancedURLTargetAdded {
+	return &url.LoadBalancedURLTargetAdded{
+		Base: event.Base{
+			ID:      "someID",
+			Version: version,
+			At:      time.Time{},
+		},
+		OriginalURL: originalURL,
+	}
+}
+
+func loadBalancedURLTargetsReplacedEvent(originalURLs []string, version int) *url.LoadBalancedURLTargetsReplaced {
+	return &url.LoadBalancedURLTargetsReplaced{
+		Base: event.Base{
+			ID:      "someID",
+			Version: version,
+			At:      time.Time{},
+		},
+		OriginalURLs: originalURLs,
+	}
+}
+
+func shortURLVerifiedEvent(verifiedURL string, version int) *url.ShortURLVerified {
 	return &url.ShortURLVerified{
 		Base: event.Base{
 			ID:      "someID",
-			Version: 1,
+			Version: version,
 			At:      time.Time{},
 		},
+		VerifiedURL: verifiedURL,
+	}
+}
+
+func shortURLRejectedEvent(rejectedURL string, version int, validator string, reason string) *url.ShortURLRejected {
+	return &url.ShortURLRejected{
+		Base: event.Base{
+			ID:      "someID",
+			Version: version,
+			At:      time.Time{},
+		},
+		RejectedURL: rejectedURL,
+		Validator:   validator,
+		Reason:      reason,
+	}
+}
+
+func shortURLTargetChangedEvent(originalURL string, version int) *url.ShortURLTargetChanged {
+	return &url.ShortURLTargetChanged{
+		Base: event.Base{
+			ID:      "someID",
+			Version: version,
+			At:      time.Time{},
+		},
+		OriginalURL: originalURL,
 	}
 }
 
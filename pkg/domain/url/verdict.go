@@ -0,0 +1,50 @@
+package url
+
+import "time"
+
+// VerdictResult is the outcome of a validator for some URLs
+type VerdictResult string
+
+const (
+	// VerdictPass is the result of a validator that accepts the URLs
+	VerdictPass VerdictResult = "pass"
+	// VerdictFail is the result of a validator that rejects the URLs
+	VerdictFail VerdictResult = "fail"
+	// VerdictError is the result of a validator that is unable to check the URLs
+	VerdictError VerdictResult = "error"
+)
+
+// Verdict is what a validator decided about some URLs, and how long it took
+type Verdict struct {
+	Validator string
+	Result    VerdictResult
+	// Reason is why the validator didn't accept the URLs, empty if it did
+	Reason string `json:",omitempty"`
+	// URL is the only URL the verdict is about, empty if it's about all the URLs
+	URL string `json:",omitempty"`
+	// FinalURL is the URL answered after following the redirects of URL, for the validators that request it
+	FinalURL string `json:",omitempty"`
+	// StatusCode is the status code answered by FinalURL, for the validators that request it
+	StatusCode int           `json:",omitempty"`
+	Latency    time.Duration `json:",omitempty"`
+}
+
+// FirstFailedVerdict returns the first VerdictFail, the URLs are rejected if there is one
+func FirstFailedVerdict(verdicts []Verdict) (Verdict, bool) {
+	return firstVerdictWith(VerdictFail, verdicts)
+}
+
+// FirstErrorVerdict returns the first VerdictError. If there is one but no VerdictFail, the URLs can't be validated
+// until the validator is able to check them, so they are neither valid nor rejected.
+func FirstErrorVerdict(verdicts []Verdict) (Verdict, bool) {
+	return firstVerdictWith(VerdictError, verdicts)
+}
+
+func firstVerdictWith(result VerdictResult, verdicts []Verdict) (Verdict, bool) {
+	for _, verdict := range verdicts {
+		if verdict.Result == result {
+			return verdict, true
+		}
+	}
+	return Verdict{}, false
+}
@@ -0,0 +1,40 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+// CheckpointStore provides an in-memory implementation of projection.CheckpointStore
+type CheckpointStore struct {
+	mux         *sync.Mutex
+	checkpoints map[string]int64
+}
+
+func (c *CheckpointStore) SaveCheckpoint(ctx context.Context, name string, position int64) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.checkpoints[name] = position
+	return nil
+}
+
+func (c *CheckpointStore) LoadCheckpoint(ctx context.Context, name string) (int64, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	position, ok := c.checkpoints[name]
+	if !ok {
+		return event.BeginningPosition, nil
+	}
+	return position, nil
+}
+
+func NewCheckpointStore() *CheckpointStore {
+	return &CheckpointStore{
+		mux:         &sync.Mutex{},
+		checkpoints: map[string]int64{},
+	}
+}
@@ -0,0 +1,143 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+)
+
+// ClickerRepository provides an in-memory implementation of click.ClickerRepository
+type ClickerRepository struct {
+	mux    *sync.Mutex
+	clicks map[string][]*click.Details
+}
+
+func (c *ClickerRepository) SaveClick(ctx context.Context, details *click.Details) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	saved := *details
+	c.clicks[details.Hash] = append(c.clicks[details.Hash], &saved)
+	return nil
+}
+
+func (c *ClickerRepository) FindClicksByHash(ctx context.Context, hash string) ([]*click.Details, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	clicks := make([]*click.Details, 0, len(c.clicks[hash]))
+	for _, details := range c.clicks[hash] {
+		found := *details
+		clicks = append(clicks, &found)
+	}
+	return clicks, nil
+}
+
+func (c *ClickerRepository) CountClicks(ctx context.Context, query click.StatsQuery) (int, int, error) {
+	clicks := c.clicksOf(query)
+	visitors := map[string]bool{}
+	for _, details := range clicks {
+		visitors[details.IP] = true
+	}
+	return len(clicks), len(visitors), nil
+}
+
+func (c *ClickerRepository) CountAllClicks(ctx context.Context, hash string, excludeBots bool) (int, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	clicks := 0
+	for _, details := range c.clicks[hash] {
+		if !excludeBots || !details.IsBot {
+			clicks++
+		}
+	}
+	return clicks, nil
+}
+
+func (c *ClickerRepository) CountClicksByBucket(ctx context.Context, query click.StatsQuery) ([]*click.Bucket, error) {
+	clicksByStart := map[time.Time]int{}
+	for _, details := range c.clicksOf(query) {
+		clicksByStart[click.BucketStart(details.ClickedAt, query.Granularity)]++
+	}
+
+	buckets := make([]*click.Bucket, 0, len(clicksByStart))
+	for start, clicks := range clicksByStart {
+		buckets = append(buckets, &click.Bucket{Start: start, Clicks: clicks})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Start.Before(buckets[j].Start) })
+	return buckets, nil
+}
+
+func (c *ClickerRepository) TopValues(ctx context.Context, query click.StatsQuery, dimension click.Dimension, limit int) ([]*click.Count, error) {
+	clicksByValue := map[string]int{}
+	for _, details := range c.clicksOf(query) {
+		if value := valueOf(details, dimension); value != "" {
+			clicksByValue[value]++
+		}
+	}
+
+	counts := make([]*click.Count, 0, len(clicksByValue))
+	for value, clicks := range clicksByValue {
+		counts = append(counts, &click.Count{Value: value, Clicks: clicks})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Clicks != counts[j].Clicks {
+			return counts[i].Clicks > counts[j].Clicks
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts, nil
+}
+
+// clicksOf returns the clicks selected by the query
+func (c *ClickerRepository) clicksOf(query click.StatsQuery) []*click.Details {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	clicks := []*click.Details{}
+	for _, details := range c.clicks[query.Hash] {
+		if details.ClickedAt.Before(query.From) || !details.ClickedAt.Before(query.To) {
+			continue
+		}
+		if query.ExcludeBots && details.IsBot {
+			continue
+		}
+		clicks = append(clicks, details)
+	}
+	return clicks
+}
+
+func valueOf(details *click.Details, dimension click.Dimension) string {
+	switch dimension {
+	case click.ByReferrer:
+		return details.Referer
+	case click.ByUserAgent:
+		return details.UserAgent
+	case click.ByCountry:
+		return details.Country
+	case click.ByCity:
+		return details.City
+	case click.ByBrowser:
+		return details.Browser
+	case click.ByOperatingSystem:
+		return details.OS
+	case click.ByDevice:
+		return string(details.Device)
+	default:
+		return ""
+	}
+}
+
+func NewClickerRepository() *ClickerRepository {
+	return &ClickerRepository{
+		mux:    &sync.Mutex{},
+		clicks: map[string][]*click.Details{},
+	}
+}
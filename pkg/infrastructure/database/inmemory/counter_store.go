@@ -0,0 +1,28 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+)
+
+// CounterStore provides an in-memory implementation of redirect.CounterStore,
+// the counters are only shared by the users of the same instance
+type CounterStore struct {
+	mux      *sync.Mutex
+	counters map[string]int64
+}
+
+func (c *CounterStore) IncrementCounter(ctx context.Context, key string) (int64, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.counters[key]++
+	return c.counters[key], nil
+}
+
+func NewCounterStore() *CounterStore {
+	return &CounterStore{
+		mux:      &sync.Mutex{},
+		counters: map[string]int64{},
+	}
+}
@@ -0,0 +1,48 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MonitoredLinkRepository provides an in-memory implementation of healthchecker.MonitoredLinkRepository
+type MonitoredLinkRepository struct {
+	mux   *sync.Mutex
+	links map[string]struct{}
+}
+
+func (m *MonitoredLinkRepository) SaveMonitoredLink(ctx context.Context, id string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.links[id] = struct{}{}
+	return nil
+}
+
+func (m *MonitoredLinkRepository) DeleteMonitoredLink(ctx context.Context, id string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	delete(m.links, id)
+	return nil
+}
+
+func (m *MonitoredLinkRepository) FindMonitoredLinks(ctx context.Context) ([]string, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	ids := make([]string, 0, len(m.links))
+	for id := range m.links {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func NewMonitoredLinkRepository() *MonitoredLinkRepository {
+	return &MonitoredLinkRepository{
+		mux:   &sync.Mutex{},
+		links: map[string]struct{}{},
+	}
+}
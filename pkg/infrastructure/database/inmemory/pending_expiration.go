@@ -0,0 +1,54 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/expirer"
+)
+
+// PendingExpirationRepository provides an in-memory implementation of expirer.PendingExpirationRepository
+type PendingExpirationRepository struct {
+	mux         *sync.Mutex
+	expirations map[string]expirer.PendingExpiration
+}
+
+func (p *PendingExpirationRepository) SavePendingExpiration(ctx context.Context, expiration *expirer.PendingExpiration) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.expirations[expiration.ID] = *expiration
+	return nil
+}
+
+func (p *PendingExpirationRepository) DeletePendingExpiration(ctx context.Context, id string) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	delete(p.expirations, id)
+	return nil
+}
+
+func (p *PendingExpirationRepository) FindDueExpirations(ctx context.Context, now time.Time) ([]*expirer.PendingExpiration, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	due := make([]*expirer.PendingExpiration, 0)
+	for _, expiration := range p.expirations {
+		if !expiration.ExpiresAt.After(now) {
+			expiration := expiration
+			due = append(due, &expiration)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ExpiresAt.Before(due[j].ExpiresAt) })
+	return due, nil
+}
+
+func NewPendingExpirationRepository() *PendingExpirationRepository {
+	return &PendingExpirationRepository{
+		mux:         &sync.Mutex{},
+		expirations: map[string]expirer.PendingExpiration{},
+	}
+}
@@ -0,0 +1,74 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
+)
+
+// ShortURLViewRepository provides an in-memory implementation of projection.ShortURLViewRepository
+type ShortURLViewRepository struct {
+	mux   *sync.Mutex
+	views map[string]projection.ShortURLView
+}
+
+func (s *ShortURLViewRepository) SaveShortURLView(ctx context.Context, view *projection.ShortURLView) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if current, ok := s.views[view.Hash]; ok && current.Version >= view.Version {
+		return nil
+	}
+	s.views[view.Hash] = *view
+	return nil
+}
+
+func (s *ShortURLViewRepository) FindShortURLView(ctx context.Context, hash string) (*projection.ShortURLView, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	view, ok := s.views[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: no view found with hash %v", projection.ErrShortURLViewNotFound, hash)
+	}
+	return &view, nil
+}
+
+func (s *ShortURLViewRepository) ListShortURLViews(ctx context.Context, filter projection.ShortURLViewFilter) ([]*projection.ShortURLView, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	views := make([]*projection.ShortURLView, 0, len(s.views))
+	for _, view := range s.views {
+		if view.Deleted || (filter.IsValid != nil && view.IsValid != *filter.IsValid) {
+			continue
+		}
+		view := view
+		views = append(views, &view)
+	}
+	sort.Slice(views, func(i, j int) bool {
+		if !views[i].CreatedAt.Equal(views[j].CreatedAt) {
+			return views[i].CreatedAt.After(views[j].CreatedAt)
+		}
+		return views[i].Hash < views[j].Hash
+	})
+
+	if filter.Offset >= len(views) {
+		return []*projection.ShortURLView{}, nil
+	}
+	views = views[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(views) {
+		views = views[:filter.Limit]
+	}
+	return views, nil
+}
+
+func NewShortURLViewRepository() *ShortURLViewRepository {
+	return &ShortURLViewRepository{
+		mux:   &sync.Mutex{},
+		views: map[string]projection.ShortURLView{},
+	}
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+type ProjectionCheckpoint struct {
+	Name     string `xorm:"'name'"`
+	Position int64  `xorm:"'position'"`
+}
+
+// SaveCheckpoint implements the projection.CheckpointStore interface
+func (d *DB) SaveCheckpoint(ctx context.Context, name string, position int64) error {
+	_, err := d.engine.Context(ctx).Exec(`INSERT INTO projection_checkpoint (name, position) VALUES (?, ?)
+ON CONFLICT (name) DO UPDATE SET position = EXCLUDED.position, updated_at = now()`,
+		name, position)
+	if err != nil {
+		return fmt.Errorf("unable to save checkpoint in database: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint implements the projection.CheckpointStore interface
+func (d *DB) LoadCheckpoint(ctx context.Context, name string) (int64, error) {
+	checkpoint := &ProjectionCheckpoint{Name: name}
+	found, err := d.engine.Context(ctx).Get(checkpoint)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve checkpoint from database: %w", err)
+	}
+	if !found {
+		return event.BeginningPosition, nil
+	}
+	return checkpoint.Position, nil
+}
@@ -0,0 +1,203 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+)
+
+type ClickDetails struct {
+	ID             int       `xorm:"'id' autoincr"`
+	Hash           string    `xorm:"'hash'"`
+	IP             string    `xorm:"'ip'"`
+	UserAgent      string    `xorm:"'user_agent'"`
+	Referer        string    `xorm:"'referer'"`
+	AcceptLanguage string    `xorm:"'accept_language'"`
+	Country        string    `xorm:"'country'"`
+	City           string    `xorm:"'city'"`
+	Browser        string    `xorm:"'browser'"`
+	OS             string    `xorm:"'os'"`
+	Device         string    `xorm:"'device'"`
+	IsBot          bool      `xorm:"'is_bot'"`
+	ClickedAt      time.Time `xorm:"'clicked_at' timestampz"`
+}
+
+func (c *ClickDetails) TableName() string {
+	return "clickdetails"
+}
+
+// SaveClick implements the click.ClickerRepository interface
+func (d *DB) SaveClick(ctx context.Context, details *click.Details) error {
+	_, err := d.engine.Context(ctx).Insert(&ClickDetails{
+		Hash:           details.Hash,
+		IP:             details.IP,
+		UserAgent:      details.UserAgent,
+		Referer:        details.Referer,
+		AcceptLanguage: details.AcceptLanguage,
+		Country:        details.Country,
+		City:           details.City,
+		Browser:        details.Browser,
+		OS:             details.OS,
+		Device:         string(details.Device),
+		IsBot:          details.IsBot,
+		ClickedAt:      details.ClickedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to insert click details in database: %w", err)
+	}
+	return nil
+}
+
+// FindClicksByHash implements the click.ClickerRepository interface
+func (d *DB) FindClicksByHash(ctx context.Context, hash string) ([]*click.Details, error) {
+	var result []ClickDetails
+	err := d.engine.Context(ctx).Where("hash = ?", hash).Asc("clicked_at", "id").Find(&result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve click details from database: %w", err)
+	}
+	return clickDetailsToDomain(result), nil
+}
+
+// dimensionColumns are the columns of the clickdetails table ranked for each dimension
+var dimensionColumns = map[click.Dimension]string{
+	click.ByReferrer:        "referer",
+	click.ByUserAgent:       "user_agent",
+	click.ByCountry:         "country",
+	click.ByCity:            "city",
+	click.ByBrowser:         "browser",
+	click.ByOperatingSystem: "os",
+	click.ByDevice:          "device",
+}
+
+// granularityFields are the date_trunc fields of the granularities, the weeks start on Monday as in the domain.
+// The clicks are truncated in UTC, as the buckets of the domain.
+var granularityFields = map[click.Granularity]string{
+	click.Hour: "hour",
+	click.Day:  "day",
+	click.Week: "week",
+}
+
+type clickCount struct {
+	Clicks         int `xorm:"'clicks'"`
+	UniqueVisitors int `xorm:"'unique_visitors'"`
+}
+
+type bucketCount struct {
+	// Start is the Unix time of the start of the bucket, so it isn't moved to the time zone of the database
+	Start  int64 `xorm:"'start'"`
+	Clicks int   `xorm:"'clicks'"`
+}
+
+type valueCount struct {
+	Value  string `xorm:"'value'"`
+	Clicks int    `xorm:"'clicks'"`
+}
+
+// CountClicks implements the click.ClickerRepository interface
+func (d *DB) CountClicks(ctx context.Context, query click.StatsQuery) (int, int, error) {
+	condition, args := statsCondition(query)
+	var count clickCount
+	_, err := d.engine.Context(ctx).
+		SQL(`SELECT COUNT(*) AS clicks, COUNT(DISTINCT ip) AS unique_visitors FROM clickdetails WHERE `+condition, args...).
+		Get(&count)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to count clicks in database: %w", err)
+	}
+	return count.Clicks, count.UniqueVisitors, nil
+}
+
+// CountAllClicks implements the click.ClickerRepository interface
+func (d *DB) CountAllClicks(ctx context.Context, hash string, excludeBots bool) (int, error) {
+	session := d.engine.Context(ctx).Where("hash = ?", hash)
+	if excludeBots {
+		session = session.And("NOT is_bot")
+	}
+	clicks, err := session.Count(&ClickDetails{})
+	if err != nil {
+		return 0, fmt.Errorf("unable to count all the clicks in database: %w", err)
+	}
+	return int(clicks), nil
+}
+
+// CountClicksByBucket implements the click.ClickerRepository interface
+func (d *DB) CountClicksByBucket(ctx context.Context, query click.StatsQuery) ([]*click.Bucket, error) {
+	field, ok := granularityFields[query.Granularity]
+	if !ok {
+		return nil, fmt.Errorf("unable to count clicks in database: unknown granularity %q", query.Granularity)
+	}
+	condition, args := statsCondition(query)
+	var result []bucketCount
+	err := d.engine.Context(ctx).
+		SQL(`SELECT EXTRACT(EPOCH FROM date_trunc('`+field+`', clicked_at AT TIME ZONE 'UTC'))::BIGINT AS start, COUNT(*) AS clicks
+FROM clickdetails WHERE `+condition+` GROUP BY 1 ORDER BY 1`, args...).
+		Find(&result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to count clicks in database: %w", err)
+	}
+
+	buckets := make([]*click.Bucket, 0, len(result))
+	for _, bucket := range result {
+		buckets = append(buckets, &click.Bucket{Start: time.Unix(bucket.Start, 0).UTC(), Clicks: bucket.Clicks})
+	}
+	return buckets, nil
+}
+
+// TopValues implements the click.ClickerRepository interface
+func (d *DB) TopValues(ctx context.Context, query click.StatsQuery, dimension click.Dimension, limit int) ([]*click.Count, error) {
+	column, ok := dimensionColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unable to rank clicks in database: unknown dimension %q", dimension)
+	}
+	condition, args := statsCondition(query)
+	var result []valueCount
+	err := d.engine.Context(ctx).
+		SQL(`SELECT `+column+` AS value, COUNT(*) AS clicks FROM clickdetails WHERE `+condition+` AND `+column+` <> ''
+GROUP BY `+column+` ORDER BY clicks DESC, `+column+` COLLATE "C" LIMIT ?`, append(args, limit)...).
+		Find(&result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to rank clicks in database: %w", err)
+	}
+
+	counts := make([]*click.Count, 0, len(result))
+	for _, count := range result {
+		counts = append(counts, &click.Count{Value: count.Value, Clicks: count.Clicks})
+	}
+	return counts, nil
+}
+
+// statsCondition selects the clicks of the query, using the clickdetails_hash_clicked_at index.
+// The range is compared as instants, whatever the offset of its times.
+func statsCondition(query click.StatsQuery) (string, []interface{}) {
+	condition := "hash = ? AND clicked_at >= ? AND clicked_at < ?"
+	if query.ExcludeBots {
+		condition += " AND NOT is_bot"
+	}
+	return condition, []interface{}{query.Hash, query.From, query.To}
+}
+
+func clickDetailsToDomain(result []ClickDetails) []*click.Details {
+	clicks := make([]*click.Details, 0, len(result))
+	for i := range result {
+		clicks = append(clicks, result[i].toDomain())
+	}
+	return clicks
+}
+
+func (c *ClickDetails) toDomain() *click.Details {
+	return &click.Details{
+		Hash:           c.Hash,
+		IP:             c.IP,
+		UserAgent:      c.UserAgent,
+		Referer:        c.Referer,
+		AcceptLanguage: c.AcceptLanguage,
+		Country:        c.Country,
+		City:           c.City,
+		Browser:        c.Browser,
+		OS:             c.OS,
+		Device:         click.DeviceClass(c.Device),
+		IsBot:          c.IsBot,
+		ClickedAt:      c.ClickedAt,
+	}
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+// IncrementCounter implements the redirect.CounterStore interface. The counters are shared by every replica
+// using the same database, as the increment is done atomically by the database itself.
+func (d *DB) IncrementCounter(ctx context.Context, key string) (int64, error) {
+	var value int64
+	found, err := d.engine.Context(ctx).SQL(`INSERT INTO counter (key, value) VALUES (?, 1)
+ON CONFLICT (key) DO UPDATE SET value = counter.value + 1, updated_at = now()
+RETURNING value`, key).Get(&value)
+	if err != nil {
+		return 0, fmt.Errorf("unable to increment counter in database: %w", err)
+	}
+	if !found {
+		return 0, fmt.Errorf("unable to increment counter in database: no value returned for %s", key)
+	}
+	return value, nil
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+type DomainEventDeadLetter struct {
+	ID         int    `xorm:"'id' autoincr"`
+	EntityID   string `xorm:"'entity_id'"`
+	Subscriber string `xorm:"'subscriber'"`
+	Error      string `xorm:"'error'"`
+	Attempts   int    `xorm:"'attempts'"`
+	Payload    []byte `xorm:"'payload'"`
+}
+
+// SendDeadLetter implements the event.DeadLetterSink interface
+func (d *DB) SendDeadLetter(ctx context.Context, deadLetter *event.DeadLetter) error {
+	payload, err := d.serializer.MarshalEvent(deadLetter.Event)
+	if err != nil {
+		return fmt.Errorf("unable to save dead letter in the database: %w", err)
+	}
+
+	_, err = d.engine.Context(ctx).Insert(&DomainEventDeadLetter{
+		EntityID:   deadLetter.Event.EntityID(),
+		Subscriber: fmt.Sprintf("%T", deadLetter.Subscriber),
+		Error:      deadLetter.Err.Error(),
+		Attempts:   deadLetter.Attempts,
+		Payload:    payload,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to insert dead letter in database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+type MonitoredLink struct {
+	ID string `xorm:"'id'"`
+}
+
+// SaveMonitoredLink implements the healthchecker.MonitoredLinkRepository interface
+func (d *DB) SaveMonitoredLink(ctx context.Context, id string) error {
+	_, err := d.engine.Context(ctx).Exec(`INSERT INTO monitored_link (id) VALUES (?) ON CONFLICT (id) DO NOTHING`, id)
+	if err != nil {
+		return fmt.Errorf("unable to save monitored link in database: %w", err)
+	}
+	return nil
+}
+
+// DeleteMonitoredLink implements the healthchecker.MonitoredLinkRepository interface
+func (d *DB) DeleteMonitoredLink(ctx context.Context, id string) error {
+	_, err := d.engine.Context(ctx).Exec(`DELETE FROM monitored_link WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("unable to delete monitored link from database: %w", err)
+	}
+	return nil
+}
+
+// FindMonitoredLinks implements the healthchecker.MonitoredLinkRepository interface
+func (d *DB) FindMonitoredLinks(ctx context.Context) ([]string, error) {
+	var result []MonitoredLink
+	err := d.engine.Context(ctx).Asc("id").Find(&result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find monitored links in database: %w", err)
+	}
+
+	ids := make([]string, 0, len(result))
+	for _, link := range result {
+		ids = append(ids, link.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/expirer"
+)
+
+type PendingExpiration struct {
+	ID           string    `xorm:"'id'"`
+	LoadBalanced bool      `xorm:"'load_balanced'"`
+	ExpiresAt    time.Time `xorm:"'expires_at'"`
+}
+
+// SavePendingExpiration implements the expirer.PendingExpirationRepository interface
+func (d *DB) SavePendingExpiration(ctx context.Context, expiration *expirer.PendingExpiration) error {
+	_, err := d.engine.Context(ctx).Exec(`INSERT INTO pending_expiration (id, load_balanced, expires_at) VALUES (?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET load_balanced = EXCLUDED.load_balanced, expires_at = EXCLUDED.expires_at`,
+		expiration.ID, expiration.LoadBalanced, timestampOf(expiration.ExpiresAt))
+	if err != nil {
+		return fmt.Errorf("unable to save pending expiration in database: %w", err)
+	}
+	return nil
+}
+
+// DeletePendingExpiration implements the expirer.PendingExpirationRepository interface
+func (d *DB) DeletePendingExpiration(ctx context.Context, id string) error {
+	_, err := d.engine.Context(ctx).Exec(`DELETE FROM pending_expiration WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("unable to delete pending expiration from database: %w", err)
+	}
+	return nil
+}
+
+// FindDueExpirations implements the expirer.PendingExpirationRepository interface
+func (d *DB) FindDueExpirations(ctx context.Context, now time.Time) ([]*expirer.PendingExpiration, error) {
+	var result []PendingExpiration
+	err := d.engine.Context(ctx).Where("expires_at <= ?", timestampOf(now)).Asc("expires_at").Find(&result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find due expirations in database: %w", err)
+	}
+
+	due := make([]*expirer.PendingExpiration, 0, len(result))
+	for _, expiration := range result {
+		due = append(due, &expirer.PendingExpiration{
+			ID:           expiration.ID,
+			LoadBalanced: expiration.LoadBalanced,
+			ExpiresAt:    expiration.ExpiresAt,
+		})
+	}
+	return due, nil
+}
+
+// timestampOf formats the time for a TIMESTAMPTZ column, as the raw queries of xorm format
+// the time arguments without their fraction of a second nor their time zone
+func timestampOf(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
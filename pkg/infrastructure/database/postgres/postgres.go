@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/lib/pq"
 	"xorm.io/xorm"
@@ -34,12 +35,18 @@ func (d *ConnectionDetails) ConnectionString() string {
 type DB struct {
 	engine     *xorm.Engine
 	serializer event.Serializer
+
+	gapsMutex sync.Mutex
+	// gaps are the transactions that could still fill the gap before each position read,
+	// those whose transaction id is lower than the one kept
+	gaps map[int64]int64
 }
 
 type DomainEvent struct {
-	ID      string `xorm:"'id'"`
-	Version int    `xorm:"'version'"`
-	Payload []byte `xorm:"'payload'"`
+	ID       string `xorm:"'id'"`
+	Version  int    `xorm:"'version'"`
+	Payload  []byte `xorm:"'payload'"`
+	Position int64  `xorm:"'position' <-"`
 }
 
 type DomainEventOutbox struct {
@@ -47,7 +54,7 @@ type DomainEventOutbox struct {
 	Payload []byte `xorm:"'payload'"`
 }
 
-func (d *DB) Append(ctx context.Context, identity string, events ...event.Event) error {
+func (d *DB) Append(ctx context.Context, identity string, expectedVersion int, events ...event.Event) error {
 	serializedEvents := make([]interface{}, 0, len(events))
 	outboxEvents := make([]interface{}, 0, len(events))
 
@@ -67,9 +74,24 @@ func (d *DB) Append(ctx context.Context, identity string, events ...event.Event)
 	}
 
 	_, err := d.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
-		_, err := session.Context(ctx).Insert(serializedEvents...)
+		// The transaction id is assigned before the positions of the events are taken,
+		// so LoadAll knows which transactions could still fill a gap in the positions.
+		_, err := session.Context(ctx).Exec("SELECT txid_current()")
+		if err != nil {
+			return nil, fmt.Errorf("unable to assign the transaction to append events: %w", err)
+		}
+
+		currentVersion, err := d.currentVersion(ctx, session, identity)
+		if err != nil {
+			return nil, err
+		}
+		if currentVersion != expectedVersion {
+			return nil, fmt.Errorf("%w: expected version %d for entity %v, but it is %d", event.ErrConcurrencyConflict, expectedVersion, identity, currentVersion)
+		}
+
+		_, err = session.Context(ctx).Insert(serializedEvents...)
 		if isDuplicateError(err) {
-			return nil, fmt.Errorf("unable to insert event in database, check the version of the events: %w", err)
+			return nil, fmt.Errorf("%w: unable to insert event in database, check the version of the events: %s", event.ErrConcurrencyConflict, err)
 		}
 		if err != nil {
 			return nil, fmt.Errorf("unable to insert events in database: %w", err)
@@ -86,6 +108,17 @@ func (d *DB) Append(ctx context.Context, identity string, events ...event.Event)
 	return err
 }
 
+func (d *DB) currentVersion(ctx context.Context, session *xorm.Session, identity string) (int, error) {
+	var currentVersion int
+	_, err := session.Context(ctx).
+		SQL("SELECT COALESCE(MAX(version), ?) FROM domain_event WHERE id = ?", event.NewStreamVersion, identity).
+		Get(&currentVersion)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve the current version of the entity: %w", err)
+	}
+	return currentVersion, nil
+}
+
 func (d *DB) Load(ctx context.Context, identity string) (*event.Stream, error) {
 	resultInterface, err := d.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
 		var result []DomainEvent
@@ -119,6 +152,111 @@ func (d *DB) Load(ctx context.Context, identity string) (*event.Stream, error) {
 	return event.StreamFrom(events), nil
 }
 
+func (d *DB) LoadFromVersion(ctx context.Context, identity string, fromVersion int) (*event.Stream, error) {
+	var result []DomainEvent
+	err := d.engine.Context(ctx).Where("id = ? AND version > ?", identity, fromVersion).Find(&result)
+	if err != nil {
+		return nil, fmt.Errorf("unknown error retrieving events from version %d: %w", fromVersion, err)
+	}
+
+	events := make([]event.Event, 0, len(result))
+	for _, domainEvent := range result {
+		event, err := d.serializer.UnmarshalEvent(domainEvent.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving event from database: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return event.StreamFrom(events), nil
+}
+
+type transactionSnapshot struct {
+	// Xmin is the lowest transaction id that was still running when the snapshot was taken
+	Xmin int64 `xorm:"'xmin'"`
+	// Xmax is the first transaction id that was not assigned yet when the snapshot was taken
+	Xmax int64 `xorm:"'xmax'"`
+}
+
+// LoadAll implements the event.Store interface. The positions are taken when the events are appended, but
+// the events are only seen once their transactions commit, so the events after a gap in the positions
+// are only retrieved once no running transaction can fill the gap anymore.
+func (d *DB) LoadAll(ctx context.Context, fromPosition int64, batchSize int) ([]*event.RecordedEvent, error) {
+	resultInterface, err := d.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		// the snapshot of the transaction is the one the events are read with
+		_, err := session.Context(ctx).Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ")
+		if err != nil {
+			return nil, fmt.Errorf("unable to set the isolation level to retrieve events: %w", err)
+		}
+
+		var snapshot transactionSnapshot
+		_, err = session.Context(ctx).
+			SQL("SELECT txid_snapshot_xmin(txid_current_snapshot()) AS xmin, txid_snapshot_xmax(txid_current_snapshot()) AS xmax").
+			Get(&snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve the snapshot to retrieve events: %w", err)
+		}
+
+		var result []DomainEvent
+		err = session.Context(ctx).
+			Where("position > ?", fromPosition).
+			OrderBy("position").
+			Limit(batchSize).
+			Find(&result)
+		if err != nil {
+			return nil, fmt.Errorf("unknown error retrieving events from position %d: %w", fromPosition, err)
+		}
+		return d.untilOpenGap(fromPosition, result, snapshot), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, ok := resultInterface.([]DomainEvent)
+	if !ok {
+		return nil, fmt.Errorf("result from transaction is not slice of domain events")
+	}
+
+	events := make([]*event.RecordedEvent, 0, len(result))
+	for _, domainEvent := range result {
+		evt, err := d.serializer.UnmarshalEvent(domainEvent.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving event from database: %w", err)
+		}
+		events = append(events, &event.RecordedEvent{
+			Event:    evt,
+			Position: domainEvent.Position,
+		})
+	}
+
+	return events, nil
+}
+
+// untilOpenGap returns the events read before the first gap in their positions that could still be filled.
+// A gap is filled by a transaction that took its positions before the event after the gap was committed,
+// so it can't be filled anymore once all the transactions running when the gap was first seen are finished.
+func (d *DB) untilOpenGap(fromPosition int64, events []DomainEvent, snapshot transactionSnapshot) []DomainEvent {
+	d.gapsMutex.Lock()
+	defer d.gapsMutex.Unlock()
+
+	nextPosition := fromPosition + 1
+	for i, domainEvent := range events {
+		if domainEvent.Position > nextPosition {
+			fillingTransactions, seen := d.gaps[domainEvent.Position]
+			if !seen {
+				fillingTransactions = snapshot.Xmax
+				d.gaps[domainEvent.Position] = fillingTransactions
+			}
+			if snapshot.Xmin < fillingTransactions {
+				return events[:i]
+			}
+			delete(d.gaps, domainEvent.Position)
+		}
+		nextPosition = domainEvent.Position + 1
+	}
+	return events
+}
+
 // PullEvents implements the redirector.OutboxSource interface
 func (d *DB) PullEvents(ctx context.Context) ([]*redirector.OutboxEvent, error) {
 	var eventsInOutbox []DomainEventOutbox
@@ -182,5 +320,6 @@ func NewDB(connectionDetails *ConnectionDetails, serializer event.Serializer) (*
 	return &DB{
 		engine:     engine,
 		serializer: serializer,
+		gaps:       map[int64]int64{},
 	}, nil
 }
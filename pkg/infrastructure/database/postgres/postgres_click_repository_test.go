@@ -0,0 +1,153 @@
+package postgres_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event/serializer/json"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/postgres"
+)
+
+var _ = Describe("Infrastructure / Database / Postgres Click Repository", func() {
+	var (
+		db  *postgres.DB
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = postgres.NewDB(connectionDetails(), json.NewSerializer())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("retrieves the clicks saved for a hash", func() {
+		hash := randomHash()
+		clickedAt := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+		details := &click.Details{
+			Hash:           hash,
+			IP:             "2001:db8::1",
+			UserAgent:      "Mozilla/5.0",
+			Referer:        "https://twitter.com",
+			AcceptLanguage: "es-ES,es;q=0.9",
+			Country:        "ES",
+			City:           "Zaragoza",
+			Browser:        "Firefox",
+			OS:             "Linux",
+			Device:         click.Desktop,
+			ClickedAt:      clickedAt,
+		}
+		Expect(db.SaveClick(ctx, details)).To(Succeed())
+		Expect(db.SaveClick(ctx, &click.Details{Hash: hash, IP: "192.168.1.1", ClickedAt: clickedAt.Add(time.Minute)})).To(Succeed())
+
+		clicks, err := db.FindClicksByHash(ctx, hash)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(clicks).To(HaveLen(2))
+		Expect(clicks[0].IP).To(Equal("2001:db8::1"))
+		Expect(clicks[0].UserAgent).To(Equal("Mozilla/5.0"))
+		Expect(clicks[0].Referer).To(Equal("https://twitter.com"))
+		Expect(clicks[0].AcceptLanguage).To(Equal("es-ES,es;q=0.9"))
+		Expect(clicks[0].Country).To(Equal("ES"))
+		Expect(clicks[0].City).To(Equal("Zaragoza"))
+		Expect(clicks[0].Browser).To(Equal("Firefox"))
+		Expect(clicks[0].OS).To(Equal("Linux"))
+		Expect(clicks[0].Device).To(Equal(click.Desktop))
+		Expect(clicks[0].IsBot).To(BeFalse())
+		Expect(clicks[0].ClickedAt).To(BeTemporally("==", clickedAt))
+		Expect(clicks[1].IP).To(Equal("192.168.1.1"))
+	})
+
+	Context("aggregations", func() {
+		var (
+			hash   string
+			monday time.Time
+			query  click.StatsQuery
+		)
+
+		BeforeEach(func() {
+			hash = randomHash()
+			monday = time.Date(2021, 11, 29, 0, 0, 0, 0, time.UTC)
+			query = click.StatsQuery{Hash: hash, From: monday, To: monday.AddDate(0, 0, 14), Granularity: click.Week}
+			for _, details := range []*click.Details{
+				{IP: "1.1.1.1", Browser: "Firefox", ClickedAt: monday.Add(time.Hour)},
+				{IP: "1.1.1.1", Browser: "Firefox", ClickedAt: monday.AddDate(0, 0, 8)},
+				{IP: "2.2.2.2", Browser: "Chrome", ClickedAt: monday.AddDate(0, 0, 9)},
+				{IP: "3.3.3.3", Browser: "Bot", IsBot: true, ClickedAt: monday.AddDate(0, 0, 9)},
+				{IP: "4.4.4.4", Browser: "Chrome", ClickedAt: monday.Add(-time.Hour)},
+			} {
+				details.Hash = hash
+				Expect(db.SaveClick(ctx, details)).To(Succeed())
+			}
+		})
+
+		It("counts the clicks and the unique visitors of a range", func() {
+			clicks, uniqueVisitors, err := db.CountClicks(ctx, query)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clicks).To(Equal(4))
+			Expect(uniqueVisitors).To(Equal(3))
+		})
+
+		It("leaves the clicks of bots out when they are excluded", func() {
+			query.ExcludeBots = true
+
+			clicks, uniqueVisitors, err := db.CountClicks(ctx, query)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clicks).To(Equal(3))
+			Expect(uniqueVisitors).To(Equal(2))
+		})
+
+		It("counts all the clicks, even out of the range, leaving the clicks of bots out when they are excluded", func() {
+			clicks, err := db.CountAllClicks(ctx, hash, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clicks).To(Equal(5))
+
+			clicks, err = db.CountAllClicks(ctx, hash, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clicks).To(Equal(4))
+		})
+
+		It("compares the range as instants, whatever the offset of its times", func() {
+			madrid := time.FixedZone("CET", 60*60)
+			query.From = monday.Add(time.Hour).In(madrid)
+			query.To = monday.AddDate(0, 0, 8).Add(time.Second).In(madrid)
+
+			clicks, _, err := db.CountClicks(ctx, query)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clicks).To(Equal(2))
+		})
+
+		It("counts the clicks of each bucket with clicks, starting the weeks on Monday", func() {
+			buckets, err := db.CountClicksByBucket(ctx, query)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(buckets).To(HaveLen(2))
+			Expect(buckets[0].Start).To(Equal(monday))
+			Expect(buckets[0].Clicks).To(Equal(1))
+			Expect(buckets[1].Start).To(Equal(monday.AddDate(0, 0, 7)))
+			Expect(buckets[1].Clicks).To(Equal(3))
+		})
+
+		It("ranks the values with more clicks, up to the limit", func() {
+			top, err := db.TopValues(ctx, query, click.ByBrowser, 2)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(top).To(Equal([]*click.Count{{Value: "Firefox", Clicks: 2}, {Value: "Bot", Clicks: 1}}))
+		})
+	})
+
+	It("doesn't retrieve any click for a hash never clicked", func() {
+		clicks, err := db.FindClicksByHash(ctx, randomHash())
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(clicks).To(BeEmpty())
+	})
+})
@@ -0,0 +1,40 @@
+package postgres_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event/serializer/json"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/postgres"
+)
+
+var _ = Describe("Infrastructure / Database / Postgres Counter Store", func() {
+	var (
+		db  *postgres.DB
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = postgres.NewDB(connectionDetails(), json.NewSerializer())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("starts the counters at one", func() {
+		Expect(db.IncrementCounter(ctx, randomHash())).To(Equal(int64(1)))
+	})
+
+	It("increments each counter independently", func() {
+		aKey := randomHash()
+		anotherKey := randomHash()
+
+		Expect(db.IncrementCounter(ctx, aKey)).To(Equal(int64(1)))
+		Expect(db.IncrementCounter(ctx, aKey)).To(Equal(int64(2)))
+		Expect(db.IncrementCounter(ctx, anotherKey)).To(Equal(int64(1)))
+		Expect(db.IncrementCounter(ctx, aKey)).To(Equal(int64(3)))
+	})
+})
@@ -0,0 +1,28 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event/serializer/json"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/postgres"
+)
+
+var _ = Describe("Infrastructure / Database / Postgres Dead Letter Sink", func() {
+	It("saves the dead letters", func() {
+		db, err := postgres.NewDB(connectionDetails(), json.NewSerializer(&Event1{}))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = db.SendDeadLetter(context.Background(), &event.DeadLetter{
+			Event:    &Event1{Base: event.Base{ID: randomHash()}},
+			Err:      errors.New("unexpected error"),
+			Attempts: 3,
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -31,7 +31,7 @@ var _ = Describe("Infrastructure / Database / Postgres Outbox Source", func() {
 
 	It("retrieves the events from the database", func() {
 		identity := randomHash()
-		err := db.Append(ctx, identity, Event1{
+		err := db.Append(ctx, identity, event.NewStreamVersion, Event1{
 			Base: event.Base{
 				ID:      identity,
 				Version: 0,
@@ -59,7 +59,7 @@ var _ = Describe("Infrastructure / Database / Postgres Outbox Source", func() {
 
 	It("marks the events as sent in the database", func() {
 		identity := randomHash()
-		err := db.Append(ctx, identity, Event1{
+		err := db.Append(ctx, identity, event.NewStreamVersion, Event1{
 			Base: event.Base{
 				ID:      identity,
 				Version: 0,
@@ -0,0 +1,175 @@
+package postgres_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event/serializer/json"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url/expirer"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/postgres"
+)
+
+var _ = Describe("Infrastructure / Database / Postgres Projections", func() {
+	var (
+		db  *postgres.DB
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = postgres.NewDB(connectionDetails(), json.NewSerializer())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("checkpoints", func() {
+		It("retrieves the last checkpoint saved", func() {
+			name := randomHash()
+			Expect(db.SaveCheckpoint(ctx, name, 3)).To(Succeed())
+			Expect(db.SaveCheckpoint(ctx, name, 7)).To(Succeed())
+
+			Expect(db.LoadCheckpoint(ctx, name)).To(Equal(int64(7)))
+		})
+
+		It("retrieves the beginning position if there is no checkpoint", func() {
+			Expect(db.LoadCheckpoint(ctx, randomHash())).To(Equal(event.BeginningPosition))
+		})
+	})
+
+	Context("short url views", func() {
+		It("saves and retrieves the view, only if it is newer", func() {
+			hash := randomHash()
+			createdAt := time.Now().Truncate(time.Second)
+			Expect(db.SaveShortURLView(ctx, &projection.ShortURLView{Hash: hash, OriginalURL: "https://google.es", CreatedAt: createdAt, Version: 0})).To(Succeed())
+			Expect(db.SaveShortURLView(ctx, &projection.ShortURLView{Hash: hash, OriginalURL: "https://google.es", IsValid: true, Clicks: 2, CreatedAt: createdAt, Version: 3})).To(Succeed())
+			Expect(db.SaveShortURLView(ctx, &projection.ShortURLView{Hash: hash, OriginalURL: "https://google.es", Clicks: 1, CreatedAt: createdAt, Version: 2})).To(Succeed())
+
+			view, err := db.FindShortURLView(ctx, hash)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(view.IsValid).To(BeTrue())
+			Expect(view.Clicks).To(Equal(2))
+			Expect(view.Version).To(Equal(3))
+			Expect(view.CreatedAt).To(BeTemporally("==", createdAt))
+		})
+
+		It("saves and retrieves the rejection of the view", func() {
+			hash := randomHash()
+			Expect(db.SaveShortURLView(ctx, &projection.ShortURLView{Hash: hash, OriginalURL: "https://google.es", IsRejected: true, RejectionReason: "malware", CreatedAt: time.Now(), Version: 1})).To(Succeed())
+
+			view, err := db.FindShortURLView(ctx, hash)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(view.IsRejected).To(BeTrue())
+			Expect(view.RejectionReason).To(Equal("malware"))
+		})
+
+		It("returns an error if the view doesn't exist", func() {
+			_, err := db.FindShortURLView(ctx, randomHash())
+
+			Expect(err).To(MatchError(projection.ErrShortURLViewNotFound))
+		})
+
+		It("lists the views by their validity", func() {
+			validHash, invalidHash := randomHash(), randomHash()
+			createdAt := time.Now().Add(time.Hour)
+			Expect(db.SaveShortURLView(ctx, &projection.ShortURLView{Hash: validHash, OriginalURL: "https://google.es", IsValid: true, CreatedAt: createdAt})).To(Succeed())
+			Expect(db.SaveShortURLView(ctx, &projection.ShortURLView{Hash: invalidHash, OriginalURL: "https://google.es", CreatedAt: createdAt})).To(Succeed())
+			isValid := false
+
+			views, err := db.ListShortURLViews(ctx, projection.ShortURLViewFilter{IsValid: &isValid, Limit: 1})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(views).To(HaveLen(1))
+			Expect(views[0].Hash).To(Equal(invalidHash))
+		})
+
+		It("doesn't list the deleted views", func() {
+			deletedHash := randomHash()
+			createdAt := time.Now().Add(2 * time.Hour)
+			Expect(db.SaveShortURLView(ctx, &projection.ShortURLView{Hash: deletedHash, OriginalURL: "https://google.es", CreatedAt: createdAt})).To(Succeed())
+			Expect(db.SaveShortURLView(ctx, &projection.ShortURLView{Hash: deletedHash, OriginalURL: "https://google.es", CreatedAt: createdAt, Deleted: true, Version: 1})).To(Succeed())
+
+			views, err := db.ListShortURLViews(ctx, projection.ShortURLViewFilter{Limit: 1})
+			Expect(err).ToNot(HaveOccurred())
+			for _, view := range views {
+				Expect(view.Hash).ToNot(Equal(deletedHash))
+			}
+
+			view, err := db.FindShortURLView(ctx, deletedHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(view.Deleted).To(BeTrue())
+		})
+	})
+
+	Context("pending expirations", func() {
+		idsOf := func(expirations []*expirer.PendingExpiration) []string {
+			var ids []string
+			for _, expiration := range expirations {
+				ids = append(ids, expiration.ID)
+			}
+			return ids
+		}
+
+		It("retrieves the pending expirations that are due", func() {
+			now := time.Now().Truncate(time.Second)
+			due, notDue := randomHash(), randomHash()
+			Expect(db.SavePendingExpiration(ctx, &expirer.PendingExpiration{ID: due, LoadBalanced: true, ExpiresAt: now.Add(-time.Minute)})).To(Succeed())
+			Expect(db.SavePendingExpiration(ctx, &expirer.PendingExpiration{ID: notDue, ExpiresAt: now.Add(time.Minute)})).To(Succeed())
+
+			expirations, err := db.FindDueExpirations(ctx, now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(idsOf(expirations)).To(ContainElement(due))
+			Expect(idsOf(expirations)).ToNot(ContainElement(notDue))
+		})
+
+		It("keeps the fraction of a second of the expiration time", func() {
+			expiresAt := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+			id := randomHash()
+			Expect(db.SavePendingExpiration(ctx, &expirer.PendingExpiration{ID: id, ExpiresAt: expiresAt})).To(Succeed())
+
+			early, err := db.FindDueExpirations(ctx, expiresAt.Add(-time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(idsOf(early)).ToNot(ContainElement(id))
+
+			due, err := db.FindDueExpirations(ctx, expiresAt)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(idsOf(due)).To(ContainElement(id))
+		})
+
+		It("doesn't retrieve the pending expirations deleted", func() {
+			now := time.Now()
+			id := randomHash()
+			Expect(db.SavePendingExpiration(ctx, &expirer.PendingExpiration{ID: id, ExpiresAt: now.Add(-time.Minute)})).To(Succeed())
+			Expect(db.DeletePendingExpiration(ctx, id)).To(Succeed())
+
+			expirations, err := db.FindDueExpirations(ctx, now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(idsOf(expirations)).ToNot(ContainElement(id))
+		})
+	})
+
+	Context("monitored links", func() {
+		It("retrieves the links saved, even more than once, until they are deleted", func() {
+			kept, deleted := randomHash(), randomHash()
+			Expect(db.SaveMonitoredLink(ctx, kept)).To(Succeed())
+			Expect(db.SaveMonitoredLink(ctx, kept)).To(Succeed())
+			Expect(db.SaveMonitoredLink(ctx, deleted)).To(Succeed())
+			Expect(db.DeleteMonitoredLink(ctx, deleted)).To(Succeed())
+
+			ids, err := db.FindMonitoredLinks(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids).To(ContainElement(kept))
+			Expect(ids).ToNot(ContainElement(deleted))
+		})
+	})
+})
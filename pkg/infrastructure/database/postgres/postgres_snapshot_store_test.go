@@ -0,0 +1,91 @@
+package postgres_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event/serializer/json"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/database/postgres"
+)
+
+var _ = Describe("Infrastructure / Database / Postgres Snapshot Store", func() {
+	var (
+		db  *postgres.DB
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = postgres.NewDB(connectionDetails(), json.NewSerializer(&Event1{}, &Event2{}))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("retrieves the latest snapshot saved", func() {
+		entityID := randomHash()
+		err := db.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: entityID, SchemaVersion: "1", Version: 1, Payload: []byte(`{"count":1}`)})
+		Expect(err).ToNot(HaveOccurred())
+		err = db.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: entityID, SchemaVersion: "1", Version: 4, Payload: []byte(`{"count":4}`)})
+		Expect(err).ToNot(HaveOccurred())
+		err = db.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: entityID, SchemaVersion: "1", Version: 2, Payload: []byte(`{"count":2}`)})
+		Expect(err).ToNot(HaveOccurred())
+
+		snapshot, err := db.LoadSnapshot(ctx, "url.ShortURL", entityID)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshot.Version).To(Equal(4))
+		Expect(snapshot.Payload).To(MatchJSON(`{"count":4}`))
+	})
+
+	It("keeps the snapshots of the entities of each type apart", func() {
+		entityID := randomHash()
+		err := db.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: entityID, SchemaVersion: "1", Version: 1, Payload: []byte(`{"count":1}`)})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = db.LoadSnapshot(ctx, "url.LoadBalancedURL", entityID)
+
+		Expect(err).To(MatchError(event.ErrSnapshotNotFound))
+	})
+
+	It("replaces the snapshot taken with another schema version, even if it's newer", func() {
+		entityID := randomHash()
+		err := db.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: entityID, SchemaVersion: "1", Version: 4, Payload: []byte(`{"count":4}`)})
+		Expect(err).ToNot(HaveOccurred())
+		err = db.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: entityID, SchemaVersion: "2", Version: 2, Payload: []byte(`{"count":2}`)})
+		Expect(err).ToNot(HaveOccurred())
+
+		snapshot, err := db.LoadSnapshot(ctx, "url.ShortURL", entityID)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshot.SchemaVersion).To(Equal("2"))
+		Expect(snapshot.Version).To(Equal(2))
+	})
+
+	When("there is no snapshot for the entity", func() {
+		It("returns an error", func() {
+			snapshot, err := db.LoadSnapshot(ctx, "url.ShortURL", randomHash())
+
+			Expect(err).To(MatchError(event.ErrSnapshotNotFound))
+			Expect(snapshot).To(BeNil())
+		})
+	})
+
+	It("retrieves only the events newer than a version", func() {
+		entityID := randomHash()
+		err := db.Append(ctx, entityID, event.NewStreamVersion,
+			&Event1{Base: event.Base{ID: entityID, Version: 0}},
+			&Event2{Base: event.Base{ID: entityID, Version: 1}},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		stream, err := db.LoadFromVersion(ctx, entityID, 0)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stream.Version()).To(Equal(1))
+		Expect(stream.Events()).To(ConsistOf(&Event2{Base: event.Base{ID: entityID, Version: 1}}))
+	})
+})
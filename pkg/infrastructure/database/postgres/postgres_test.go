@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -28,7 +29,7 @@ var _ = Describe("Infrastructure / Database / Postgres Event Sourcing", func() {
 
 	It("retrieves the events correctly from the database", func() {
 		entityID := randomHash()
-		err := db.Append(ctx, entityID,
+		err := db.Append(ctx, entityID, event.NewStreamVersion,
 			&Event1{
 				Base: event.Base{
 					ID:      entityID,
@@ -67,10 +68,132 @@ var _ = Describe("Infrastructure / Database / Postgres Event Sourcing", func() {
 		Expect(stream.Version()).To(Equal(1))
 	})
 
+	It("retrieves the events of all the entities in the order they were appended", func() {
+		someEntityID, otherEntityID := randomHash(), randomHash()
+		err := db.Append(ctx, someEntityID, event.NewStreamVersion, &Event1{Base: event.Base{ID: someEntityID, Version: 0}})
+		Expect(err).ToNot(HaveOccurred())
+		err = db.Append(ctx, otherEntityID, event.NewStreamVersion, &Event2{Base: event.Base{ID: otherEntityID, Version: 0}})
+		Expect(err).ToNot(HaveOccurred())
+		err = db.Append(ctx, someEntityID, 0, &Event2{Base: event.Base{ID: someEntityID, Version: 1}})
+		Expect(err).ToNot(HaveOccurred())
+
+		var appendedEvents []*event.RecordedEvent
+		position := event.BeginningPosition
+		for {
+			events, err := db.LoadAll(ctx, position, 100)
+			Expect(err).ToNot(HaveOccurred())
+			for _, recordedEvent := range events {
+				Expect(recordedEvent.Position).To(BeNumerically(">", position))
+				position = recordedEvent.Position
+				if id := recordedEvent.Event.EntityID(); id == someEntityID || id == otherEntityID {
+					appendedEvents = append(appendedEvents, recordedEvent)
+				}
+			}
+			if len(events) < 100 {
+				break
+			}
+		}
+
+		Expect(appendedEvents).To(HaveLen(3))
+		Expect(appendedEvents[0].Event).To(Equal(&Event1{Base: event.Base{ID: someEntityID, Version: 0}}))
+		Expect(appendedEvents[1].Event).To(Equal(&Event2{Base: event.Base{ID: otherEntityID, Version: 0}}))
+		Expect(appendedEvents[2].Event).To(Equal(&Event2{Base: event.Base{ID: someEntityID, Version: 1}}))
+	})
+
+	When("an event appended before is not committed yet", func() {
+		var (
+			lastPosition int64
+			connection   *sql.DB
+			transaction  *sql.Tx
+			pendingID    string
+		)
+
+		loadAllFrom := func(position int64) []event.Event {
+			recordedEvents, err := db.LoadAll(ctx, position, 100)
+			Expect(err).ToNot(HaveOccurred())
+			events := make([]event.Event, 0, len(recordedEvents))
+			for _, recordedEvent := range recordedEvents {
+				events = append(events, recordedEvent.Event)
+			}
+			return events
+		}
+
+		BeforeEach(func() {
+			lastPosition = event.BeginningPosition
+			for {
+				events, err := db.LoadAll(ctx, lastPosition, 100)
+				Expect(err).ToNot(HaveOccurred())
+				if len(events) == 0 {
+					break
+				}
+				lastPosition = events[len(events)-1].Position
+			}
+
+			var err error
+			connection, err = sql.Open("postgres", connectionDetails().ConnectionString())
+			Expect(err).ToNot(HaveOccurred())
+			transaction, err = connection.BeginTx(ctx, nil)
+			Expect(err).ToNot(HaveOccurred())
+			pendingID = randomHash()
+			payload, err := json.NewSerializer(&Event1{}).MarshalEvent(&Event1{Base: event.Base{ID: pendingID}})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = transaction.ExecContext(ctx, "SELECT txid_current()")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = transaction.ExecContext(ctx, "INSERT INTO domain_event (id, version, payload) VALUES ($1, 0, $2)", pendingID, payload)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = transaction.Rollback()
+			Expect(connection.Close()).To(Succeed())
+		})
+
+		It("doesn't retrieve the events appended after it until it is committed", func() {
+			entityID := randomHash()
+			err := db.Append(ctx, entityID, event.NewStreamVersion, &Event2{Base: event.Base{ID: entityID}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(loadAllFrom(lastPosition)).To(BeEmpty())
+
+			Expect(transaction.Commit()).To(Succeed())
+
+			Expect(loadAllFrom(lastPosition)).To(Equal([]event.Event{
+				&Event1{Base: event.Base{ID: pendingID}},
+				&Event2{Base: event.Base{ID: entityID}},
+			}))
+		})
+
+		It("retrieves the events appended after it once it is rolled back", func() {
+			entityID := randomHash()
+			err := db.Append(ctx, entityID, event.NewStreamVersion, &Event2{Base: event.Base{ID: entityID}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(loadAllFrom(lastPosition)).To(BeEmpty())
+
+			Expect(transaction.Rollback()).To(Succeed())
+
+			Expect(loadAllFrom(lastPosition)).To(Equal([]event.Event{
+				&Event2{Base: event.Base{ID: entityID}},
+			}))
+		})
+	})
+
+	When("the expected version is not the current version of the entity", func() {
+		It("returns a concurrency conflict", func() {
+			entityID := randomHash()
+			err := db.Append(ctx, entityID, event.NewStreamVersion, &Event1{Base: event.Base{ID: entityID, Version: 0}})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = db.Append(ctx, entityID, event.NewStreamVersion, &Event2{Base: event.Base{ID: entityID, Version: 0}})
+
+			Expect(err).To(MatchError(event.ErrConcurrencyConflict))
+		})
+	})
+
 	When("saving a duplicated version of an entity", func() {
 		It("doesn't save it, but saves the other events", func() {
 			entityID := randomHash()
-			err := db.Append(ctx, entityID,
+			err := db.Append(ctx, entityID, event.NewStreamVersion,
 				&Event1{
 					Base: event.Base{
 						ID:      entityID,
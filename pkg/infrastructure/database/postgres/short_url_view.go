@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/projection"
+)
+
+type ShortURLView struct {
+	Hash            string    `xorm:"'hash'"`
+	OriginalURL     string    `xorm:"'original_url'"`
+	IsValid         bool      `xorm:"'is_valid'"`
+	IsRejected      bool      `xorm:"'is_rejected'"`
+	RejectionReason string    `xorm:"'rejection_reason'"`
+	Clicks          int       `xorm:"'clicks'"`
+	CreatedAt       time.Time `xorm:"'created_at'"`
+	Deleted         bool      `xorm:"'deleted'"`
+	Version         int       `xorm:"'version'"`
+}
+
+func (s *ShortURLView) TableName() string {
+	return "short_url_view"
+}
+
+// SaveShortURLView implements the projection.ShortURLViewRepository interface
+func (d *DB) SaveShortURLView(ctx context.Context, view *projection.ShortURLView) error {
+	_, err := d.engine.Context(ctx).Exec(`INSERT INTO short_url_view (hash, original_url, is_valid, is_rejected, rejection_reason, clicks, created_at, deleted, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (hash) DO UPDATE SET original_url = EXCLUDED.original_url, is_valid = EXCLUDED.is_valid, is_rejected = EXCLUDED.is_rejected, rejection_reason = EXCLUDED.rejection_reason,
+clicks = EXCLUDED.clicks, deleted = EXCLUDED.deleted, version = EXCLUDED.version
+WHERE short_url_view.version < EXCLUDED.version`,
+		view.Hash, view.OriginalURL, view.IsValid, view.IsRejected, view.RejectionReason, view.Clicks, view.CreatedAt, view.Deleted, view.Version)
+	if err != nil {
+		return fmt.Errorf("unable to save short url view in database: %w", err)
+	}
+	return nil
+}
+
+// FindShortURLView implements the projection.ShortURLViewRepository interface
+func (d *DB) FindShortURLView(ctx context.Context, hash string) (*projection.ShortURLView, error) {
+	view := &ShortURLView{Hash: hash}
+	found, err := d.engine.Context(ctx).Get(view)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve short url view from database: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: no view found with hash %v", projection.ErrShortURLViewNotFound, hash)
+	}
+	return view.toDomain(), nil
+}
+
+// ListShortURLViews implements the projection.ShortURLViewRepository interface
+func (d *DB) ListShortURLViews(ctx context.Context, filter projection.ShortURLViewFilter) ([]*projection.ShortURLView, error) {
+	session := d.engine.Context(ctx).Where("deleted = ?", false).Desc("created_at").Asc("hash")
+	if filter.IsValid != nil {
+		session = session.And("is_valid = ?", *filter.IsValid)
+	}
+	if filter.Limit > 0 {
+		session = session.Limit(filter.Limit, filter.Offset)
+	} else if filter.Offset > 0 {
+		session = session.Limit(math.MaxInt32, filter.Offset)
+	}
+
+	var result []ShortURLView
+	err := session.Find(&result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list short url views from database: %w", err)
+	}
+
+	views := make([]*projection.ShortURLView, 0, len(result))
+	for i := range result {
+		views = append(views, result[i].toDomain())
+	}
+	return views, nil
+}
+
+func (s *ShortURLView) toDomain() *projection.ShortURLView {
+	return &projection.ShortURLView{
+		Hash:            s.Hash,
+		OriginalURL:     s.OriginalURL,
+		IsValid:         s.IsValid,
+		IsRejected:      s.IsRejected,
+		RejectionReason: s.RejectionReason,
+		Clicks:          s.Clicks,
+		CreatedAt:       s.CreatedAt,
+		Deleted:         s.Deleted,
+		Version:         s.Version,
+	}
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+type DomainEventSnapshot struct {
+	EntityType    string `xorm:"'entity_type'"`
+	ID            string `xorm:"'id'"`
+	SchemaVersion string `xorm:"'schema_version'"`
+	Version       int    `xorm:"'version'"`
+	Payload       []byte `xorm:"'payload'"`
+}
+
+// SaveSnapshot implements the event.SnapshotStore interface
+func (d *DB) SaveSnapshot(ctx context.Context, snapshot *event.Snapshot) error {
+	_, err := d.engine.Context(ctx).Exec(`INSERT INTO domain_event_snapshot (entity_type, id, schema_version, version, payload) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (entity_type, id) DO UPDATE SET schema_version = EXCLUDED.schema_version, version = EXCLUDED.version, payload = EXCLUDED.payload, created_at = now()
+WHERE domain_event_snapshot.version < EXCLUDED.version OR domain_event_snapshot.schema_version <> EXCLUDED.schema_version`,
+		snapshot.EntityType, snapshot.EntityID, snapshot.SchemaVersion, snapshot.Version, snapshot.Payload)
+	if err != nil {
+		return fmt.Errorf("unable to save snapshot in database: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot implements the event.SnapshotStore interface
+func (d *DB) LoadSnapshot(ctx context.Context, entityType, entityID string) (*event.Snapshot, error) {
+	snapshot := &DomainEventSnapshot{EntityType: entityType, ID: entityID}
+	found, err := d.engine.Context(ctx).Get(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve snapshot from database: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: no snapshot found for entity [type=%v, id=%v]", event.ErrSnapshotNotFound, entityType, entityID)
+	}
+
+	return &event.Snapshot{
+		EntityType:    snapshot.EntityType,
+		EntityID:      snapshot.ID,
+		SchemaVersion: snapshot.SchemaVersion,
+		Version:       snapshot.Version,
+		Payload:       snapshot.Payload,
+	}, nil
+}
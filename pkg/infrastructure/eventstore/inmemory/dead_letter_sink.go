@@ -0,0 +1,36 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+// DeadLetterSink provides an in-memory implementation of event.DeadLetterSink
+type DeadLetterSink struct {
+	mux         *sync.Mutex
+	deadLetters []*event.DeadLetter
+}
+
+func (d *DeadLetterSink) SendDeadLetter(ctx context.Context, deadLetter *event.DeadLetter) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.deadLetters = append(d.deadLetters, deadLetter)
+	return nil
+}
+
+// DeadLetters returns the dead letters received so far
+func (d *DeadLetterSink) DeadLetters() []*event.DeadLetter {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	return append([]*event.DeadLetter{}, d.deadLetters...)
+}
+
+func NewDeadLetterSink() *DeadLetterSink {
+	return &DeadLetterSink{
+		mux: &sync.Mutex{},
+	}
+}
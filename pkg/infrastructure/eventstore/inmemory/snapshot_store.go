@@ -0,0 +1,51 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+)
+
+// SnapshotStore provides an in-memory implementation of event.SnapshotStore
+type SnapshotStore struct {
+	mux       *sync.Mutex
+	snapshots map[snapshotKey]*event.Snapshot
+}
+
+type snapshotKey struct {
+	entityType string
+	entityID   string
+}
+
+func (s *SnapshotStore) SaveSnapshot(ctx context.Context, snapshot *event.Snapshot) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	key := snapshotKey{entityType: snapshot.EntityType, entityID: snapshot.EntityID}
+	previous, ok := s.snapshots[key]
+	if ok && previous.Version >= snapshot.Version && previous.SchemaVersion == snapshot.SchemaVersion {
+		return nil
+	}
+	s.snapshots[key] = snapshot
+	return nil
+}
+
+func (s *SnapshotStore) LoadSnapshot(ctx context.Context, entityType, entityID string) (*event.Snapshot, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	snapshot, ok := s.snapshots[snapshotKey{entityType: entityType, entityID: entityID}]
+	if !ok {
+		return nil, fmt.Errorf("%w: no snapshot found for entity [type=%v, id=%v]", event.ErrSnapshotNotFound, entityType, entityID)
+	}
+	return snapshot, nil
+}
+
+func NewSnapshotStore() *SnapshotStore {
+	return &SnapshotStore{
+		mux:       &sync.Mutex{},
+		snapshots: map[snapshotKey]*event.Snapshot{},
+	}
+}
@@ -0,0 +1,80 @@
+package inmemory_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/event"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/eventstore/inmemory"
+)
+
+var _ = Describe("EventStore / InMemory Snapshot Store", func() {
+	var (
+		store *inmemory.SnapshotStore
+		ctx   context.Context
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		store = inmemory.NewSnapshotStore()
+	})
+
+	It("retrieves the latest snapshot saved", func() {
+		err := store.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: "someID", Version: 1, Payload: []byte("first")})
+		Expect(err).ToNot(HaveOccurred())
+		err = store.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: "someID", Version: 5, Payload: []byte("second")})
+		Expect(err).ToNot(HaveOccurred())
+
+		snapshot, err := store.LoadSnapshot(ctx, "url.ShortURL", "someID")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshot).To(Equal(&event.Snapshot{EntityType: "url.ShortURL", EntityID: "someID", Version: 5, Payload: []byte("second")}))
+	})
+
+	When("saving a snapshot older than the current one", func() {
+		It("keeps the current one", func() {
+			err := store.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: "someID", Version: 5, Payload: []byte("newer")})
+			Expect(err).ToNot(HaveOccurred())
+			err = store.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: "someID", Version: 3, Payload: []byte("older")})
+			Expect(err).ToNot(HaveOccurred())
+
+			snapshot, err := store.LoadSnapshot(ctx, "url.ShortURL", "someID")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot.Version).To(Equal(5))
+		})
+	})
+
+	When("saving a snapshot older than the current one, with another schema version", func() {
+		It("replaces the current one", func() {
+			err := store.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: "someID", SchemaVersion: "old", Version: 5, Payload: []byte("newer")})
+			Expect(err).ToNot(HaveOccurred())
+			err = store.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: "someID", SchemaVersion: "new", Version: 3, Payload: []byte("older")})
+			Expect(err).ToNot(HaveOccurred())
+
+			snapshot, err := store.LoadSnapshot(ctx, "url.ShortURL", "someID")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot.SchemaVersion).To(Equal("new"))
+		})
+	})
+
+	It("keeps the snapshots of the entities of each type apart", func() {
+		err := store.SaveSnapshot(ctx, &event.Snapshot{EntityType: "url.ShortURL", EntityID: "someID", Version: 1, Payload: []byte("short")})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = store.LoadSnapshot(ctx, "url.LoadBalancedURL", "someID")
+
+		Expect(err).To(MatchError(event.ErrSnapshotNotFound))
+	})
+
+	When("there is no snapshot for the entity", func() {
+		It("returns an error", func() {
+			snapshot, err := store.LoadSnapshot(ctx, "url.ShortURL", "someID")
+
+			Expect(err).To(MatchError(event.ErrSnapshotNotFound))
+			Expect(snapshot).To(BeNil())
+		})
+	})
+})
@@ -12,15 +12,34 @@ import (
 type EventStore struct {
 	mux        *sync.Mutex
 	eventsByID map[string]*event.Stream
+	allEvents  []*event.RecordedEvent
 }
 
-func (m *EventStore) Append(ctx context.Context, entityID string, records ...event.Event) error {
+func (m *EventStore) Append(ctx context.Context, entityID string, expectedVersion int, records ...event.Event) error {
 	if len(records) == 0 {
 		return nil
 	}
 
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	currentVersion := event.NewStreamVersion
+	if stream, ok := m.eventsByID[entityID]; ok {
+		currentVersion = stream.Version()
+	}
+	if currentVersion != expectedVersion {
+		return fmt.Errorf("%w: expected version %d for entity %v, but it is %d", event.ErrConcurrencyConflict, expectedVersion, entityID, currentVersion)
+	}
+
+	for _, record := range records {
+		m.allEvents = append(m.allEvents, &event.RecordedEvent{
+			Event:    record,
+			Position: int64(len(m.allEvents) + 1),
+		})
+	}
+
 	if _, ok := m.eventsByID[entityID]; !ok {
-		m.eventsByID[entityID] = event.StreamFrom(records)
+		m.eventsByID[entityID] = event.StreamFrom(append([]event.Event{}, records...))
 		return nil
 	}
 
@@ -29,11 +48,44 @@ func (m *EventStore) Append(ctx context.Context, entityID string, records ...eve
 }
 
 func (m *EventStore) Load(ctx context.Context, entityID string) (*event.Stream, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
 	eventStream, ok := m.eventsByID[entityID]
 	if !ok {
 		return nil, fmt.Errorf("%w: no entity found with id, %v", event.ErrEntityNotFound, entityID)
 	}
-	return eventStream, nil
+	return event.StreamFrom(append([]event.Event{}, eventStream.Events()...)), nil
+}
+
+func (m *EventStore) LoadFromVersion(ctx context.Context, entityID string, fromVersion int) (*event.Stream, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var events []event.Event
+	if eventStream, ok := m.eventsByID[entityID]; ok {
+		for _, evt := range eventStream.Events() {
+			if evt.EventVersion() > fromVersion {
+				events = append(events, evt)
+			}
+		}
+	}
+	return event.StreamFrom(events), nil
+}
+
+func (m *EventStore) LoadAll(ctx context.Context, fromPosition int64, batchSize int) ([]*event.RecordedEvent, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if fromPosition < event.BeginningPosition || fromPosition >= int64(len(m.allEvents)) {
+		return []*event.RecordedEvent{}, nil
+	}
+
+	toPosition := fromPosition + int64(batchSize)
+	if toPosition > int64(len(m.allEvents)) {
+		toPosition = int64(len(m.allEvents))
+	}
+	return append([]*event.RecordedEvent{}, m.allEvents[fromPosition:toPosition]...), nil
 }
 
 func NewEventStore() *EventStore {
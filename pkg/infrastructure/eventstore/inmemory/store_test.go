@@ -22,7 +22,7 @@ var _ = Describe("EventStore / InMemory Store", func() {
 	})
 
 	It("appends the events correctly", func() {
-		err := store.Append(context.Background(), "someID",
+		err := store.Append(context.Background(), "someID", event.NewStreamVersion,
 			&SomeEvent1{Base: event.Base{
 				ID:      "someID",
 				Version: 0,
@@ -39,7 +39,7 @@ var _ = Describe("EventStore / InMemory Store", func() {
 	})
 
 	It("retrieves the events correctly", func() {
-		err := store.Append(context.Background(), "someID",
+		err := store.Append(context.Background(), "someID", event.NewStreamVersion,
 			&SomeEvent1{Base: event.Base{
 				ID:      "someID",
 				Version: 0,
@@ -71,6 +71,62 @@ var _ = Describe("EventStore / InMemory Store", func() {
 		))
 	})
 
+	It("retrieves only the events newer than a version", func() {
+		err := store.Append(ctx, "someID", event.NewStreamVersion,
+			&SomeEvent1{Base: event.Base{ID: "someID", Version: 0}},
+			&SomeEvent2{Base: event.Base{ID: "someID", Version: 1}},
+			&SomeEvent1{Base: event.Base{ID: "someID", Version: 2}},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		stream, err := store.LoadFromVersion(ctx, "someID", 0)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stream.Version()).To(Equal(2))
+		Expect(stream.Events()).To(ConsistOf(
+			&SomeEvent2{Base: event.Base{ID: "someID", Version: 1}},
+			&SomeEvent1{Base: event.Base{ID: "someID", Version: 2}},
+		))
+	})
+
+	It("retrieves the events of all the entities in the order they were appended", func() {
+		err := store.Append(ctx, "someID", event.NewStreamVersion, &SomeEvent1{Base: event.Base{ID: "someID", Version: 0}})
+		Expect(err).ToNot(HaveOccurred())
+		err = store.Append(ctx, "otherID", event.NewStreamVersion, &SomeEvent2{Base: event.Base{ID: "otherID", Version: 0}})
+		Expect(err).ToNot(HaveOccurred())
+		err = store.Append(ctx, "someID", 0, &SomeEvent2{Base: event.Base{ID: "someID", Version: 1}})
+		Expect(err).ToNot(HaveOccurred())
+
+		events, err := store.LoadAll(ctx, event.BeginningPosition, 10)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(Equal([]*event.RecordedEvent{
+			{Event: &SomeEvent1{Base: event.Base{ID: "someID", Version: 0}}, Position: 1},
+			{Event: &SomeEvent2{Base: event.Base{ID: "otherID", Version: 0}}, Position: 2},
+			{Event: &SomeEvent2{Base: event.Base{ID: "someID", Version: 1}}, Position: 3},
+		}))
+
+		events, err = store.LoadAll(ctx, 1, 1)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(Equal([]*event.RecordedEvent{
+			{Event: &SomeEvent2{Base: event.Base{ID: "otherID", Version: 0}}, Position: 2},
+		}))
+	})
+
+	When("the expected version is not the current version of the stream", func() {
+		It("returns a concurrency conflict and doesn't append the events", func() {
+			err := store.Append(ctx, "someID", event.NewStreamVersion, &SomeEvent1{Base: event.Base{ID: "someID", Version: 0}})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = store.Append(ctx, "someID", event.NewStreamVersion, &SomeEvent2{Base: event.Base{ID: "someID", Version: 0}})
+
+			Expect(err).To(MatchError(event.ErrConcurrencyConflict))
+			stream, err := store.Load(ctx, "someID")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stream.Events()).To(ConsistOf(&SomeEvent1{Base: event.Base{ID: "someID", Version: 0}}))
+		})
+	})
 })
 
 type SomeEvent1 struct {
@@ -0,0 +1,119 @@
+package geolocation
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+)
+
+var (
+	ErrInvalidCSVDatabase = errors.New("invalid csv database")
+)
+
+// ipRange is a range of IPs located in the same place, from first to last, both included.
+// The IPs are stored in their 16-byte form, so they can be compared byte by byte.
+type ipRange struct {
+	first    net.IP
+	last     net.IP
+	location click.Geolocation
+}
+
+// CSV implements click.Geolocator with a CSV file of IP ranges, like the ones of DB-IP or IP2Location LITE.
+// Each row has the first and last IP of a range, the ISO 3166-1 alpha-2 code of its country and,
+// optionally, its city. The ranges must not overlap, and an optional header is skipped.
+// The whole file is loaded in memory.
+type CSV struct {
+	ranges []ipRange
+}
+
+func (c *CSV) Locate(rawIP string) (click.Geolocation, error) {
+	ip := net.ParseIP(rawIP)
+	if ip == nil {
+		return click.Geolocation{}, fmt.Errorf("%w: %q", ErrInvalidIP, rawIP)
+	}
+	ip = ip.To16()
+
+	// the first range that starts after the ip, so the candidate is the previous one
+	next := sort.Search(len(c.ranges), func(i int) bool {
+		return bytes.Compare(c.ranges[i].first, ip) > 0
+	})
+	if next == 0 {
+		return click.Geolocation{}, nil
+	}
+	candidate := c.ranges[next-1]
+	if bytes.Compare(ip, candidate.last) > 0 {
+		return click.Geolocation{}, nil
+	}
+	return candidate.location, nil
+}
+
+func NewCSV(path string) (*CSV, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the csv database %s: %w", path, err)
+	}
+	defer file.Close()
+
+	ranges, err := readIPRanges(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the csv database %s: %w", path, err)
+	}
+	return &CSV{ranges: ranges}, nil
+}
+
+func readIPRanges(reader io.Reader) ([]ipRange, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	var ranges []ipRange
+	for line := 1; ; line++ {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && net.ParseIP(row[0]) == nil {
+			continue
+		}
+
+		parsed, err := parseIPRange(row)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidCSVDatabase, line, err)
+		}
+		ranges = append(ranges, parsed)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].first, ranges[j].first) < 0
+	})
+	return ranges, nil
+}
+
+func parseIPRange(row []string) (ipRange, error) {
+	if len(row) < 3 || len(row) > 4 {
+		return ipRange{}, fmt.Errorf("expected 3 or 4 fields but found %d", len(row))
+	}
+	first := net.ParseIP(row[0])
+	last := net.ParseIP(row[1])
+	if first == nil || last == nil {
+		return ipRange{}, fmt.Errorf("the range %s - %s has invalid ips", row[0], row[1])
+	}
+	if bytes.Compare(first.To16(), last.To16()) > 0 {
+		return ipRange{}, fmt.Errorf("the range %s - %s ends before it starts", row[0], row[1])
+	}
+
+	location := click.Geolocation{Country: row[2]}
+	if len(row) == 4 {
+		location.City = row[3]
+	}
+	return ipRange{first: first.To16(), last: last.To16(), location: location}, nil
+}
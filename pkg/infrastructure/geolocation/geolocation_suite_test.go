@@ -0,0 +1,13 @@
+package geolocation_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestGeolocation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Geolocation Suite")
+}
@@ -0,0 +1,76 @@
+package geolocation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/geolocation"
+)
+
+var _ = Describe("Infrastructure / Geolocation", func() {
+	// both databases of testdata have the same ranges
+	describeGeolocator := func(newGeolocator func() click.Geolocator) {
+		var geolocator click.Geolocator
+
+		BeforeEach(func() {
+			geolocator = newGeolocator()
+		})
+		AfterEach(func() {
+			if mmdb, ok := geolocator.(*geolocation.MMDB); ok {
+				Expect(mmdb.Close()).To(Succeed())
+			}
+		})
+
+		DescribeTable("locates the IPs",
+			func(ip string, expected click.Geolocation) {
+				location, err := geolocator.Locate(ip)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(location).To(Equal(expected))
+			},
+			Entry("in a city", "192.0.2.10", click.Geolocation{Country: "ES", City: "Zaragoza"}),
+			Entry("at the start of a range", "192.0.2.0", click.Geolocation{Country: "ES", City: "Zaragoza"}),
+			Entry("at the end of a range", "192.0.2.255", click.Geolocation{Country: "ES", City: "Zaragoza"}),
+			Entry("in a country without city", "198.51.100.7", click.Geolocation{Country: "FR"}),
+			Entry("of IPv6", "2001:db8::1", click.Geolocation{Country: "DE", City: "Berlin"}),
+			Entry("that are unknown", "203.0.113.1", click.Geolocation{}),
+			Entry("that are unknown between ranges", "193.0.0.1", click.Geolocation{}),
+		)
+
+		It("fails for an invalid IP", func() {
+			_, err := geolocator.Locate("unknown")
+
+			Expect(err).To(MatchError(geolocation.ErrInvalidIP))
+		})
+	}
+
+	Context("with a MaxMind DB", func() {
+		describeGeolocator(func() click.Geolocator {
+			mmdb, err := geolocation.NewMMDB("testdata/test-city.mmdb")
+			Expect(err).ToNot(HaveOccurred())
+			return mmdb
+		})
+
+		It("fails when the database doesn't exist", func() {
+			_, err := geolocation.NewMMDB("testdata/non-existing.mmdb")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with a CSV of IP ranges", func() {
+		describeGeolocator(func() click.Geolocator {
+			csv, err := geolocation.NewCSV("testdata/test-city.csv")
+			Expect(err).ToNot(HaveOccurred())
+			return csv
+		})
+
+		It("fails when the database isn't valid", func() {
+			_, err := geolocation.NewCSV("testdata/test-city.mmdb")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
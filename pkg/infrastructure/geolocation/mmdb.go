@@ -0,0 +1,62 @@
+package geolocation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+)
+
+var (
+	ErrInvalidIP = errors.New("invalid ip")
+)
+
+// englishNames is the language the names of the cities are read in
+const englishNames = "en"
+
+// MMDB implements click.Geolocator reading a MaxMind DB file, like the GeoLite2 City database
+type MMDB struct {
+	reader *maxminddb.Reader
+}
+
+// mmdbRecord is the part of the records of the GeoIP2 and GeoLite2 City databases that is read
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+func (m *MMDB) Locate(rawIP string) (click.Geolocation, error) {
+	ip := net.ParseIP(rawIP)
+	if ip == nil {
+		return click.Geolocation{}, fmt.Errorf("%w: %q", ErrInvalidIP, rawIP)
+	}
+
+	var record mmdbRecord
+	err := m.reader.Lookup(ip, &record)
+	if err != nil {
+		return click.Geolocation{}, fmt.Errorf("unable to look up the ip in the database: %w", err)
+	}
+	return click.Geolocation{
+		Country: record.Country.ISOCode,
+		City:    record.City.Names[englishNames],
+	}, nil
+}
+
+func (m *MMDB) Close() error {
+	return m.reader.Close()
+}
+
+func NewMMDB(path string) (*MMDB, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the mmdb database %s: %w", path, err)
+	}
+	return &MMDB{reader: reader}, nil
+}
@@ -9,6 +9,7 @@ type PrometheusMetrics struct {
 	singleUrlsProcessed   prometheus.Counter
 	multipleUrlsProcessed prometheus.Counter
 	fileUrlsProcessed     prometheus.Counter
+	lostClicks            prometheus.Counter
 }
 
 func NewPrometheusMetrics() *PrometheusMetrics {
@@ -27,10 +28,16 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 		Help: "The total number of file shorted urls",
 	})
 
+	var lostClicks = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "urlshortener_lost_clicks_total",
+		Help: "The total number of clicks that couldn't be saved",
+	})
+
 	return &PrometheusMetrics{
 		singleUrlsProcessed:   singleUrlsProcessed,
 		multipleUrlsProcessed: multipleUrlsProcessed,
 		fileUrlsProcessed:     fileUrlsProcessed,
+		lostClicks:            lostClicks,
 	}
 }
 
@@ -41,3 +48,7 @@ func (r *PrometheusMetrics) RecordSingleURLMetrics() {
 func (r *PrometheusMetrics) RecordFileURLMetrics() {
 	r.fileUrlsProcessed.Inc()
 }
+
+func (r *PrometheusMetrics) RecordLostClick() {
+	r.lostClicks.Inc()
+}
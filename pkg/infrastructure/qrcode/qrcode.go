@@ -0,0 +1,134 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+var (
+	ErrInvalidOptions = errors.New("invalid qr code options")
+)
+
+// Format is the image format of a QR code
+type Format string
+
+const (
+	PNG Format = "png"
+	SVG Format = "svg"
+)
+
+// Level is the error correction level of a QR code, the fraction of it that can be damaged but still be read
+type Level string
+
+const (
+	Low      Level = "L" // 7%
+	Medium   Level = "M" // 15%
+	Quartile Level = "Q" // 25%
+	High     Level = "H" // 30%
+)
+
+const (
+	MinSize     = 64
+	MaxSize     = 2048
+	DefaultSize = 256
+)
+
+var recoveryLevels = map[Level]qrcode.RecoveryLevel{
+	Low:      qrcode.Low,
+	Medium:   qrcode.Medium,
+	Quartile: qrcode.High,
+	High:     qrcode.Highest,
+}
+
+var contentTypes = map[Format]string{
+	PNG: "image/png",
+	SVG: "image/svg+xml",
+}
+
+// Options are how a QR code is rendered. Size is the width and height of the image, in pixels.
+type Options struct {
+	Format Format
+	Size   int
+	Level  Level
+}
+
+// DefaultOptions renders a PNG of DefaultSize pixels with a Medium error correction level
+func DefaultOptions() Options {
+	return Options{
+		Format: PNG,
+		Size:   DefaultSize,
+		Level:  Medium,
+	}
+}
+
+// Image is a rendered QR code
+type Image struct {
+	ContentType string
+	Data        []byte
+}
+
+// Generator renders the QR codes, including a quiet zone around them
+type Generator struct{}
+
+func (g *Generator) Generate(content string, options Options) (*Image, error) {
+	err := validateOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := qrcode.New(content, recoveryLevels[options.Level])
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the qr code: %w", err)
+	}
+
+	var data []byte
+	switch options.Format {
+	case SVG:
+		data = svg(code.Bitmap(), options.Size)
+	default:
+		data, err = code.PNG(options.Size)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render the qr code as png: %w", err)
+		}
+	}
+	return &Image{ContentType: contentTypes[options.Format], Data: data}, nil
+}
+
+func validateOptions(options Options) error {
+	if _, ok := contentTypes[options.Format]; !ok {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidOptions, options.Format)
+	}
+	if _, ok := recoveryLevels[options.Level]; !ok {
+		return fmt.Errorf("%w: unknown error correction level %q", ErrInvalidOptions, options.Level)
+	}
+	if options.Size < MinSize || options.Size > MaxSize {
+		return fmt.Errorf("%w: the size must be between %d and %d pixels", ErrInvalidOptions, MinSize, MaxSize)
+	}
+	return nil
+}
+
+// svg draws each dark module of the bitmap as a square of one unit, scaling the whole code to the size
+func svg(bitmap [][]bool, size int) []byte {
+	modules := len(bitmap)
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, modules, modules)
+	fmt.Fprintf(&buffer, `<rect width="%d" height="%d" fill="#ffffff"/>`, modules, modules)
+	buffer.WriteString(`<path fill="#000000" d="`)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buffer, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buffer.WriteString(`"/></svg>`)
+	return buffer.Bytes()
+}
+
+func NewGenerator() *Generator {
+	return &Generator{}
+}
@@ -0,0 +1,71 @@
+package qrcode_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/qrcode"
+)
+
+var _ = Describe("Infrastructure / QR code", func() {
+	var generator *qrcode.Generator
+
+	BeforeEach(func() {
+		generator = qrcode.NewGenerator()
+	})
+
+	It("renders a PNG of the size", func() {
+		image, err := generator.Generate("http://example.com/r/lxqrJ9xF", qrcode.Options{Format: qrcode.PNG, Size: 300, Level: qrcode.High})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(image.ContentType).To(Equal("image/png"))
+
+		decoded, err := png.Decode(bytes.NewReader(image.Data))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.Bounds().Dx()).To(Equal(300))
+		Expect(decoded.Bounds().Dy()).To(Equal(300))
+	})
+
+	It("renders an SVG of the size", func() {
+		image, err := generator.Generate("http://example.com/r/lxqrJ9xF", qrcode.Options{Format: qrcode.SVG, Size: 300, Level: qrcode.Low})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(image.ContentType).To(Equal("image/svg+xml"))
+
+		var svg struct {
+			Width  string `xml:"width,attr"`
+			Height string `xml:"height,attr"`
+			Path   struct {
+				D string `xml:"d,attr"`
+			} `xml:"path"`
+		}
+		Expect(xml.Unmarshal(image.Data, &svg)).To(Succeed())
+		Expect(svg.Width).To(Equal("300"))
+		Expect(svg.Height).To(Equal("300"))
+		Expect(svg.Path.D).ToNot(BeEmpty())
+	})
+
+	It("renders a bigger code for a higher error correction level", func() {
+		low, err := generator.Generate("http://example.com/r/lxqrJ9xF", qrcode.Options{Format: qrcode.SVG, Size: 300, Level: qrcode.Low})
+		Expect(err).ToNot(HaveOccurred())
+		high, err := generator.Generate("http://example.com/r/lxqrJ9xF", qrcode.Options{Format: qrcode.SVG, Size: 300, Level: qrcode.High})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(len(high.Data)).To(BeNumerically(">", len(low.Data)))
+	})
+
+	DescribeTable("rejects invalid options",
+		func(options qrcode.Options) {
+			_, err := generator.Generate("http://example.com/r/lxqrJ9xF", options)
+
+			Expect(err).To(MatchError(qrcode.ErrInvalidOptions))
+		},
+		Entry("an unknown format", qrcode.Options{Format: "gif", Size: 256, Level: qrcode.Medium}),
+		Entry("an unknown level", qrcode.Options{Format: qrcode.PNG, Size: 256, Level: "X"}),
+		Entry("a size too small", qrcode.Options{Format: qrcode.PNG, Size: qrcode.MinSize - 1, Level: qrcode.Medium}),
+		Entry("a size too big", qrcode.Options{Format: qrcode.PNG, Size: qrcode.MaxSize + 1, Level: qrcode.Medium}),
+	)
+})
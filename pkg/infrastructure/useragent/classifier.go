@@ -0,0 +1,114 @@
+package useragent
+
+import (
+	"strings"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+)
+
+// botTokens are the fragments of the user agents of crawlers, link previewers and HTTP libraries.
+// They are matched in lower case.
+var botTokens = []string{
+	"bot", "crawler", "spider", "slurp", "facebookexternalhit", "embedly", "whatsapp", "skypeuripreview",
+	"slack-imgproxy", "bitlyurlexpander", "headlesschrome", "lighthouse", "curl/", "wget/", "python-requests",
+	"python-urllib", "go-http-client", "java/", "okhttp", "axios", "node-fetch", "libwww-perl", "httpclient",
+}
+
+// match associates a fragment of a user agent with the name of what it identifies
+type match struct {
+	token string
+	name  string
+}
+
+// browsers are checked in order, as most user agents mention several of them
+var browsers = []match{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Opera", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"Vivaldi/", "Vivaldi"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Chromium/", "Chromium"},
+	{"MSIE ", "Internet Explorer"},
+	{"Trident/", "Internet Explorer"},
+	{"Safari/", "Safari"},
+}
+
+// operatingSystems are checked in order, as iOS user agents also mention Mac OS X and Android ones mention Linux
+var operatingSystems = []match{
+	{"Windows Phone", "Windows Phone"},
+	{"Windows", "Windows"},
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"iPod", "iOS"},
+	{"Android", "Android"},
+	{"CrOS", "Chrome OS"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// Classifier implements click.UserAgentClassifier looking for well-known fragments of the user agents
+type Classifier struct{}
+
+func (c *Classifier) Classify(userAgent string) click.UserAgent {
+	if isBot(userAgent) {
+		return click.UserAgent{
+			Browser: firstMatch(browsers, userAgent),
+			OS:      firstMatch(operatingSystems, userAgent),
+			Device:  click.BotDevice,
+			IsBot:   true,
+		}
+	}
+
+	os := firstMatch(operatingSystems, userAgent)
+	return click.UserAgent{
+		Browser: firstMatch(browsers, userAgent),
+		OS:      os,
+		Device:  deviceClass(userAgent, os),
+	}
+}
+
+func isBot(userAgent string) bool {
+	lowerCase := strings.ToLower(userAgent)
+	for _, token := range botTokens {
+		if strings.Contains(lowerCase, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstMatch(matches []match, userAgent string) string {
+	for _, m := range matches {
+		if strings.Contains(userAgent, m.token) {
+			return m.name
+		}
+	}
+	return ""
+}
+
+func deviceClass(userAgent string, os string) click.DeviceClass {
+	switch {
+	case strings.Contains(userAgent, "iPad") || strings.Contains(userAgent, "Tablet"):
+		return click.Tablet
+	case os == "Android" && !strings.Contains(userAgent, "Mobile"):
+		return click.Tablet
+	case strings.Contains(userAgent, "Mobi") || os == "iOS" || os == "Android" || os == "Windows Phone":
+		return click.Mobile
+	case os != "":
+		return click.Desktop
+	default:
+		return click.UnknownDevice
+	}
+}
+
+func NewClassifier() *Classifier {
+	return &Classifier{}
+}
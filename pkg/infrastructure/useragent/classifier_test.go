@@ -0,0 +1,70 @@
+package useragent_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/click"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/useragent"
+)
+
+var _ = Describe("User agent classifier", func() {
+	var classifier *useragent.Classifier
+
+	BeforeEach(func() {
+		classifier = useragent.NewClassifier()
+	})
+
+	DescribeTable("classifies the browsers of people",
+		func(userAgent string, expected click.UserAgent) {
+			Expect(classifier.Classify(userAgent)).To(Equal(expected))
+		},
+		Entry("Chrome on Windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.45 Safari/537.36",
+			click.UserAgent{Browser: "Chrome", OS: "Windows", Device: click.Desktop}),
+		Entry("Firefox on Linux",
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:94.0) Gecko/20100101 Firefox/94.0",
+			click.UserAgent{Browser: "Firefox", OS: "Linux", Device: click.Desktop}),
+		Entry("Safari on macOS",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.1 Safari/605.1.15",
+			click.UserAgent{Browser: "Safari", OS: "macOS", Device: click.Desktop}),
+		Entry("Edge on Windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.45 Safari/537.36 Edg/96.0.1054.29",
+			click.UserAgent{Browser: "Edge", OS: "Windows", Device: click.Desktop}),
+		Entry("Safari on iPhone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 15_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.1 Mobile/15E148 Safari/604.1",
+			click.UserAgent{Browser: "Safari", OS: "iOS", Device: click.Mobile}),
+		Entry("Chrome on an Android phone",
+			"Mozilla/5.0 (Linux; Android 12; Pixel 6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.45 Mobile Safari/537.36",
+			click.UserAgent{Browser: "Chrome", OS: "Android", Device: click.Mobile}),
+		Entry("Chrome on an Android tablet",
+			"Mozilla/5.0 (Linux; Android 11; SM-T870) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.45 Safari/537.36",
+			click.UserAgent{Browser: "Chrome", OS: "Android", Device: click.Tablet}),
+		Entry("Safari on iPad",
+			"Mozilla/5.0 (iPad; CPU OS 15_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.1 Mobile/15E148 Safari/604.1",
+			click.UserAgent{Browser: "Safari", OS: "iOS", Device: click.Tablet}),
+		Entry("an unknown user agent",
+			"SomethingNew/1.0",
+			click.UserAgent{Device: click.UnknownDevice}),
+		Entry("an empty user agent",
+			"",
+			click.UserAgent{Device: click.UnknownDevice}),
+	)
+
+	DescribeTable("flags crawlers, link previewers and HTTP libraries as bots",
+		func(userAgent string) {
+			classification := classifier.Classify(userAgent)
+
+			Expect(classification.IsBot).To(BeTrue())
+			Expect(classification.Device).To(Equal(click.BotDevice))
+		},
+		Entry("Slackbot", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"),
+		Entry("Twitterbot", "Twitterbot/1.0"),
+		Entry("Googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"),
+		Entry("Facebook", "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)"),
+		Entry("WhatsApp", "WhatsApp/2.21.22.23 A"),
+		Entry("curl", "curl/7.79.1"),
+		Entry("Go", "Go-http-client/1.1"),
+	)
+})
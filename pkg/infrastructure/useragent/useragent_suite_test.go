@@ -0,0 +1,13 @@
+package useragent_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestUserAgent(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "User Agent Suite")
+}
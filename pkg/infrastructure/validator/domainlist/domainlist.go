@@ -0,0 +1,231 @@
+package domainlist
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	neturl "net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+var ErrInvalidRule = errors.New("invalid domain list rule")
+
+// Validator checks the hosts of the URLs against the allow and deny rules of a file, which is reloaded
+// when it changes. The rules are lines with an action and a pattern:
+//
+//	# comments and empty lines are ignored
+//	allow good.example.com
+//	deny *.example.com
+//	deny 10.0.0.0/8
+//
+// The pattern is a host, a wildcard matching any subdomain of a domain, "*" matching every host, an IP
+// or a CIDR range matching the IP hosts in it. The hosts matched by an allow rule are valid, otherwise the
+// hosts matched by a deny rule are rejected. A "deny *" rule only accepts the hosts allowed.
+type Validator struct {
+	path           string
+	reloadInterval time.Duration
+
+	mutex   sync.RWMutex
+	rules   *rules
+	modTime time.Time
+}
+
+func (v *Validator) Name() string {
+	return "domainlist"
+}
+
+func (v *Validator) ValidateURLs(ctx context.Context, urls []string) (bool, error) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	for _, aLongURL := range urls {
+		parsed, err := neturl.Parse(aLongURL)
+		if err != nil || parsed.Hostname() == "" {
+			return false, &url.RejectionError{Validator: v.Name(), Reason: fmt.Sprintf("the url %s has no host", aLongURL)}
+		}
+		host := parsed.Hostname()
+		if !v.rules.allows(host) {
+			return false, &url.RejectionError{Validator: v.Name(), Reason: fmt.Sprintf("the host %s is denied", host)}
+		}
+	}
+	return true, nil
+}
+
+// Start reloads the rules every reloadInterval if the file changed, blocking until the context is cancelled.
+// The previous rules are kept while the file can't be loaded.
+func (v *Validator) Start(ctx context.Context) {
+	ticker := time.NewTicker(v.reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := v.Reload()
+			if err != nil {
+				log.Printf("unable to reload the domain list %s: %s", v.path, err)
+			}
+		}
+	}
+}
+
+// Reload loads the rules again if the file was modified since they were loaded.
+func (v *Validator) Reload() error {
+	info, err := os.Stat(v.path)
+	if err != nil {
+		return fmt.Errorf("unable to stat the domain list: %w", err)
+	}
+	v.mutex.RLock()
+	unchanged := info.ModTime().Equal(v.modTime)
+	v.mutex.RUnlock()
+	if unchanged {
+		return nil
+	}
+	return v.load()
+}
+
+func (v *Validator) load() error {
+	file, err := os.Open(v.path)
+	if err != nil {
+		return fmt.Errorf("unable to open the domain list: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat the domain list: %w", err)
+	}
+	loaded, err := parseRules(file)
+	if err != nil {
+		return err
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.rules = loaded
+	v.modTime = info.ModTime()
+	return nil
+}
+
+// rules are the patterns of a domain list, by their action
+type rules struct {
+	allow patterns
+	deny  patterns
+}
+
+func (r *rules) allows(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return r.allow.match(host) || !r.deny.match(host)
+}
+
+type patterns struct {
+	all        bool
+	hosts      map[string]bool
+	subdomains []string
+	networks   []*net.IPNet
+}
+
+func (p *patterns) add(pattern string) error {
+	pattern = strings.TrimSuffix(strings.ToLower(pattern), ".")
+	switch {
+	case pattern == "*":
+		p.all = true
+	case strings.HasPrefix(pattern, "*."):
+		p.subdomains = append(p.subdomains, pattern[1:])
+	case strings.Contains(pattern, "/"):
+		_, network, err := net.ParseCIDR(pattern)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidRule, err)
+		}
+		p.networks = append(p.networks, network)
+	case strings.Contains(pattern, "*"):
+		return fmt.Errorf("%w: the wildcard must be the first label of %s", ErrInvalidRule, pattern)
+	default:
+		if ip := net.ParseIP(pattern); ip != nil {
+			pattern = ip.String()
+		}
+		p.hosts[pattern] = true
+	}
+	return nil
+}
+
+func (p *patterns) match(host string) bool {
+	if p.all {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip != nil {
+		for _, network := range p.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return p.hosts[ip.String()]
+	}
+	if p.hosts[host] {
+		return true
+	}
+	for _, suffix := range p.subdomains {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRules(reader io.Reader) (*rules, error) {
+	parsed := &rules{
+		allow: patterns{hosts: map[string]bool{}},
+		deny:  patterns{hosts: map[string]bool{}},
+	}
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: line %d must have an action and a pattern", ErrInvalidRule, line)
+		}
+
+		var err error
+		switch fields[0] {
+		case "allow":
+			err = parsed.allow.add(fields[1])
+		case "deny":
+			err = parsed.deny.add(fields[1])
+		default:
+			err = fmt.Errorf("%w: unknown action %s", ErrInvalidRule, fields[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read the domain list: %w", err)
+	}
+	return parsed, nil
+}
+
+// NewValidator loads the rules of the file at path, which are reloaded every reloadInterval once it's started.
+func NewValidator(path string, reloadInterval time.Duration) (*Validator, error) {
+	v := &Validator{
+		path:           path,
+		reloadInterval: reloadInterval,
+	}
+	err := v.load()
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
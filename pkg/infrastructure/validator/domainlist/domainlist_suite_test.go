@@ -0,0 +1,13 @@
+package domainlist_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestDomainlist(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Domainlist Suite")
+}
@@ -0,0 +1,152 @@
+package domainlist_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/domainlist"
+)
+
+var _ = Describe("Domain list Validator", func() {
+	var (
+		ctx  context.Context
+		dir  string
+		path string
+	)
+
+	writeRules := func(rules string, modTime time.Time) {
+		Expect(os.WriteFile(path, []byte(rules), 0o600)).To(Succeed())
+		Expect(os.Chtimes(path, modTime, modTime)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		dir, err = os.MkdirTemp("", "domainlist")
+		Expect(err).ToNot(HaveOccurred())
+
+		// the rules are copied, as some tests change them
+		rules, err := os.ReadFile("testdata/domains.list")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "domains.list")
+		writeRules(string(rules), time.Now().Add(-time.Hour))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	DescribeTable("checks the host of the URLs against the rules",
+		func(aLongURL string, isValidURL bool) {
+			validator, err := domainlist.NewValidator(path, time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+
+			isValid, err := validator.ValidateURLs(ctx, []string{aLongURL})
+
+			Expect(isValid).To(Equal(isValidURL))
+			if isValidURL {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(url.ErrURLRejected))
+			}
+		},
+		Entry("a host not in the list", "https://google.com", true),
+		Entry("a denied host", "https://evil.com/login", false),
+		Entry("a denied host in uppercase", "https://EVIL.com", false),
+		Entry("a subdomain of a denied host", "https://www.evil.com", true),
+		Entry("a subdomain of a denied domain", "https://login.phishing.net", false),
+		Entry("a nested subdomain of a denied domain", "https://a.b.phishing.net", false),
+		Entry("a denied domain itself", "https://phishing.net", true),
+		Entry("an allowed subdomain of a denied domain", "https://safe.phishing.net", true),
+		Entry("an IP in a denied range", "http://10.1.2.3:8080", false),
+		Entry("an IP out of the denied ranges", "http://11.1.2.3", true),
+		Entry("a denied IP", "http://203.0.113.7", false),
+		Entry("an IPv6 in a denied range", "http://[2001:db8::1]/", false),
+		Entry("a URL without host", "foo", false),
+	)
+
+	It("tells why the URL is rejected", func() {
+		validator, err := domainlist.NewValidator(path, time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = validator.ValidateURLs(ctx, []string{"https://google.com", "https://evil.com"})
+
+		Expect(err).To(Equal(&url.RejectionError{Validator: "domainlist", Reason: "the host evil.com is denied"}))
+	})
+
+	When("every host is denied", func() {
+		It("only accepts the allowed hosts", func() {
+			writeRules("deny *\nallow unizar.es\nallow *.unizar.es\n", time.Now())
+			validator, err := domainlist.NewValidator(path, time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(validator.ValidateURLs(ctx, []string{"https://unizar.es", "https://moodle.unizar.es"})).To(BeTrue())
+			Expect(validator.ValidateURLs(ctx, []string{"https://google.com"})).Error().To(MatchError(url.ErrURLRejected))
+		})
+	})
+
+	When("the file changes", func() {
+		It("uses the new rules once it's reloaded", func() {
+			validator, err := domainlist.NewValidator(path, time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+
+			writeRules("deny google.com\n", time.Now())
+			Expect(validator.Reload()).To(Succeed())
+
+			Expect(validator.ValidateURLs(ctx, []string{"https://evil.com"})).To(BeTrue())
+			Expect(validator.ValidateURLs(ctx, []string{"https://google.com"})).Error().To(MatchError(url.ErrURLRejected))
+		})
+
+		It("reloads it periodically once it's started", func() {
+			validator, err := domainlist.NewValidator(path, 10*time.Millisecond)
+			Expect(err).ToNot(HaveOccurred())
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go validator.Start(ctx)
+
+			writeRules("deny google.com\n", time.Now())
+
+			Eventually(func() error {
+				_, err := validator.ValidateURLs(ctx, []string{"https://google.com"})
+				return err
+			}).Should(MatchError(url.ErrURLRejected))
+		})
+
+		It("keeps the previous rules if the new ones are not valid", func() {
+			validator, err := domainlist.NewValidator(path, time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+
+			writeRules("block google.com\n", time.Now())
+
+			Expect(validator.Reload()).To(MatchError(domainlist.ErrInvalidRule))
+			Expect(validator.ValidateURLs(ctx, []string{"https://evil.com"})).Error().To(MatchError(url.ErrURLRejected))
+		})
+	})
+
+	DescribeTable("rejects the files with invalid rules",
+		func(rules string) {
+			writeRules(rules, time.Now())
+
+			_, err := domainlist.NewValidator(path, time.Minute)
+
+			Expect(err).To(MatchError(domainlist.ErrInvalidRule))
+		},
+		Entry("an unknown action", "block evil.com"),
+		Entry("a rule without pattern", "deny"),
+		Entry("a wildcard in the middle", "deny www.*.com"),
+		Entry("an invalid CIDR range", "deny 10.0.0.0/33"),
+	)
+
+	It("returns an error if the file doesn't exist", func() {
+		_, err := domainlist.NewValidator("testdata/missing.list", time.Minute)
+
+		Expect(err).To(HaveOccurred())
+	})
+})
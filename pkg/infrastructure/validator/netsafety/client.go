@@ -0,0 +1,123 @@
+package netsafety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrTooManyRedirects = errors.New("too many redirects")
+	ErrResponseTooLarge = errors.New("response too large")
+)
+
+// NewHTTPClient returns a client that only connects to public addresses. The hosts are resolved and checked on
+// every connection, including the ones of the redirects, and the connection is made to the address checked, so
+// the host can't resolve to another address after it's checked. The redirects and the bodies read are limited.
+func NewHTTPClient(timeout time.Duration, options ...Option) *http.Client {
+	c := newConfig(options)
+	dialer := &safeDialer{config: c, dialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}}
+	transport := &http.Transport{
+		// the proxies would connect to the hosts without checking them
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &limitedTransport{next: transport, maxResponseSize: c.maxResponseSize},
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) > c.maxRedirects {
+				return fmt.Errorf("%w: more than %d", ErrTooManyRedirects, c.maxRedirects)
+			}
+			if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to an unsupported scheme: %s", request.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+type safeDialer struct {
+	config *config
+	dialer *net.Dialer
+}
+
+// DialContext connects to the first of the checked addresses of the host that accepts the connection
+func (d *safeDialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := d.config.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialErr error
+	for _, ip := range ips {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+	}
+	return nil, dialErr
+}
+
+// limitedTransport fails the reads of the bodies after maxResponseSize bytes. The advertised length of the
+// bodies is not checked, so the responses whose bodies are not read, like the ones of HEAD, never fail.
+type limitedTransport struct {
+	next            http.RoundTripper
+	maxResponseSize int64
+}
+
+func (t *limitedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := t.next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body = &limitedBody{body: response.Body, remaining: t.maxResponseSize}
+	return response, nil
+}
+
+// CloseIdleConnections lets the http.Client close the idle connections of the next transport
+func (t *limitedTransport) CloseIdleConnections() {
+	if closer, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	// one more byte than the remaining ones is read to know if the body is larger
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrResponseTooLarge
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
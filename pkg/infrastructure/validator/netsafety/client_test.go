@@ -0,0 +1,199 @@
+package netsafety_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/netsafety"
+)
+
+var _ = Describe("Network safety HTTP client", func() {
+	var (
+		server   *httptest.Server
+		mux      *http.ServeMux
+		resolver *FakeResolver
+		loopback *net.IPNet
+		port     string
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		_, port, _ = net.SplitHostPort(server.Listener.Addr().String())
+		resolver = &FakeResolver{addresses: map[string]string{"public.test": "127.0.0.1", "internal.test": "10.0.0.1"}}
+		_, loopback, _ = net.ParseCIDR("127.0.0.1/32")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// the loopback address of the testing server is allowed as if it were public
+	newClient := func(options ...netsafety.Option) *http.Client {
+		options = append([]netsafety.Option{netsafety.WithResolver(resolver), netsafety.WithAllowedNetworks(loopback)}, options...)
+		return netsafety.NewHTTPClient(5*time.Second, options...)
+	}
+	urlOf := func(host string, path string) string {
+		return fmt.Sprintf("http://%s:%s%s", host, port, path)
+	}
+
+	It("connects to the address checked", func() {
+		mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {})
+
+		response, err := newClient().Get(urlOf("public.test", "/"))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).To(HaveHTTPStatus(http.StatusOK))
+		Expect(resolver.lookups("public.test")).To(Equal(1))
+	})
+
+	It("doesn't connect to the non public addresses", func() {
+		_, err := netsafety.NewHTTPClient(5 * time.Second).Get(server.URL)
+
+		Expect(err).To(MatchError(netsafety.ErrNonPublicAddress))
+	})
+
+	It("checks the address of every redirect", func() {
+		mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+			http.Redirect(writer, request, urlOf("internal.test", "/admin"), http.StatusFound)
+		})
+
+		_, err := newClient().Get(urlOf("public.test", "/"))
+
+		Expect(err).To(MatchError(netsafety.ErrNonPublicAddress))
+	})
+
+	It("checks the address of every connection, even if the host resolved to a public address before", func() {
+		mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {})
+		client := newClient()
+		_, err := client.Get(urlOf("public.test", "/"))
+		Expect(err).ToNot(HaveOccurred())
+
+		resolver.rebind("public.test", "169.254.169.254")
+		client.CloseIdleConnections()
+		_, err = client.Get(urlOf("public.test", "/"))
+
+		Expect(err).To(MatchError(netsafety.ErrNonPublicAddress))
+	})
+
+	It("follows a limited number of redirects", func() {
+		hits := 0
+		mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+			hits++
+			http.Redirect(writer, request, "/", http.StatusFound)
+		})
+
+		_, err := newClient(netsafety.WithMaxRedirects(2)).Get(urlOf("public.test", "/"))
+
+		Expect(err).To(MatchError(netsafety.ErrTooManyRedirects))
+		Expect(hits).To(Equal(3))
+	})
+
+	It("stops reading the bodies larger than the limit at the limit", func() {
+		mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = writer.Write([]byte(strings.Repeat("a", 2048)))
+		})
+
+		response, err := newClient(netsafety.WithMaxResponseSize(1024)).Get(urlOf("public.test", "/"))
+		Expect(err).ToNot(HaveOccurred())
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+
+		Expect(err).To(MatchError(netsafety.ErrResponseTooLarge))
+		Expect(body).To(HaveLen(1024))
+	})
+
+	It("doesn't fail the responses larger than the limit if their bodies are not read", func() {
+		mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Content-Length", "2097152")
+			if request.Method == http.MethodGet {
+				_, _ = writer.Write([]byte(strings.Repeat("a", 2097152)))
+			}
+		})
+		client := newClient(netsafety.WithMaxResponseSize(1024))
+
+		headResponse, err := client.Head(urlOf("public.test", "/"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(headResponse).To(HaveHTTPStatus(http.StatusOK))
+		Expect(headResponse.ContentLength).To(BeEquivalentTo(2097152))
+		getResponse, err := client.Get(urlOf("public.test", "/"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getResponse).To(HaveHTTPStatus(http.StatusOK))
+		Expect(getResponse.Body.Close()).To(Succeed())
+	})
+
+	It("stops reading the bodies of unknown length at the limit", func() {
+		mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+			for i := 0; i < 4; i++ {
+				_, _ = writer.Write([]byte(strings.Repeat("a", 512)))
+				writer.(http.Flusher).Flush()
+			}
+		})
+
+		response, err := newClient(netsafety.WithMaxResponseSize(1024)).Get(urlOf("public.test", "/"))
+		Expect(err).ToNot(HaveOccurred())
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+
+		Expect(err).To(MatchError(netsafety.ErrResponseTooLarge))
+		Expect(body).To(HaveLen(1024))
+	})
+
+	It("reads the bodies within the limit", func() {
+		mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+			writer.(http.Flusher).Flush()
+			_, _ = writer.Write([]byte(strings.Repeat("a", 1024)))
+		})
+
+		response, err := newClient(netsafety.WithMaxResponseSize(1024)).Get(urlOf("public.test", "/"))
+		Expect(err).ToNot(HaveOccurred())
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(body).To(HaveLen(1024))
+	})
+})
+
+// FakeResolver resolves the hosts to a single address, counting the lookups of each host
+type FakeResolver struct {
+	mutex     sync.Mutex
+	addresses map[string]string
+	counts    map[string]int
+}
+
+func (f *FakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.counts == nil {
+		f.counts = map[string]int{}
+	}
+	f.counts[host]++
+	address, ok := f.addresses[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+	return []net.IPAddr{{IP: net.ParseIP(address)}}, nil
+}
+
+func (f *FakeResolver) rebind(host string, address string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.addresses[host] = address
+}
+
+func (f *FakeResolver) lookups(host string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.counts[host]
+}
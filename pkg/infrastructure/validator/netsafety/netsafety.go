@@ -0,0 +1,179 @@
+package netsafety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	neturl "net/url"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+var ErrNonPublicAddress = errors.New("non public address")
+
+// reservedNetworks are not reachable from the internet, besides the private, loopback, link-local and multicast ones
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // this network
+	"100.64.0.0/10", // shared address space of the carrier-grade NATs
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved, including the broadcast address
+	"64:ff9b::/96",  // NAT64, which may translate to private IPv4 addresses
+)
+
+//go:generate mockgen -source=$GOFILE -destination=./mocks/${GOFILE} -package=mocks
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// Option configures the optional behaviour of the Validator and the HTTP client
+type Option func(c *config)
+
+type config struct {
+	resolver        Resolver
+	allowedNetworks []*net.IPNet
+	maxRedirects    int
+	maxResponseSize int64
+}
+
+// WithResolver replaces the resolver of the hosts, which is the net.DefaultResolver by default
+func WithResolver(resolver Resolver) Option {
+	return func(c *config) {
+		c.resolver = resolver
+	}
+}
+
+// WithAllowedNetworks allows the addresses of the networks even if they are not public, like the ones of the
+// services of an internal network that must be reachable
+func WithAllowedNetworks(networks ...*net.IPNet) Option {
+	return func(c *config) {
+		c.allowedNetworks = append(c.allowedNetworks, networks...)
+	}
+}
+
+// WithMaxRedirects limits the redirects followed by the HTTP client, 5 by default
+func WithMaxRedirects(maxRedirects int) Option {
+	return func(c *config) {
+		c.maxRedirects = maxRedirects
+	}
+}
+
+// WithMaxResponseSize limits the bytes of the bodies read by the HTTP client, 1MiB by default
+func WithMaxResponseSize(maxResponseSize int64) Option {
+	return func(c *config) {
+		c.maxResponseSize = maxResponseSize
+	}
+}
+
+func newConfig(options []Option) *config {
+	c := &config{
+		resolver:        net.DefaultResolver,
+		maxRedirects:    5,
+		maxResponseSize: 1 << 20,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// resolve returns the addresses of the host, or ErrNonPublicAddress if any of them is not public,
+// as any of them could be used to connect to the host.
+func (c *config) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, c.checkIP(host, ip)
+	}
+
+	addresses, err := c.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve %s: %w", host, err)
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("unable to resolve %s: no addresses found", host)
+	}
+	ips := make([]net.IP, 0, len(addresses))
+	for _, address := range addresses {
+		err = c.checkIP(host, address.IP)
+		if err != nil {
+			return nil, err
+		}
+		ips = append(ips, address.IP)
+	}
+	return ips, nil
+}
+
+func (c *config) checkIP(host string, ip net.IP) error {
+	for _, network := range c.allowedNetworks {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	if !IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s resolves to %s", ErrNonPublicAddress, host, ip)
+	}
+	return nil
+}
+
+// IsPublicIP tells if the IP is reachable from the internet, so it's not a private, loopback, link-local,
+// multicast or reserved address.
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validator rejects the URLs whose hosts resolve to addresses that are not public, so they can't be used
+// to reach the internal services from the validators.
+type Validator struct {
+	config *config
+}
+
+func (v *Validator) Name() string {
+	return "netsafety"
+}
+
+func (v *Validator) ValidateURLs(ctx context.Context, urls []string) (bool, error) {
+	for _, aLongURL := range urls {
+		parsed, err := neturl.Parse(aLongURL)
+		if err != nil || parsed.Hostname() == "" {
+			return false, &url.RejectionError{Validator: v.Name(), Reason: fmt.Sprintf("the url %s has no host", aLongURL)}
+		}
+		_, err = v.config.resolve(ctx, parsed.Hostname())
+		if errors.Is(err, ErrNonPublicAddress) {
+			return false, &url.RejectionError{Validator: v.Name(), Reason: err.Error()}
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: %s", url.ErrUnableToValidateURLs, err)
+		}
+	}
+	return true, nil
+}
+
+func NewValidator(options ...Option) *Validator {
+	return &Validator{
+		config: newConfig(options),
+	}
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
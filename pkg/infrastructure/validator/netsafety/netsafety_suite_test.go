@@ -0,0 +1,13 @@
+package netsafety_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestNetsafety(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Netsafety Suite")
+}
@@ -0,0 +1,102 @@
+package netsafety_test
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/netsafety"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/netsafety/mocks"
+)
+
+var _ = Describe("Network safety Validator", func() {
+	var (
+		ctx       context.Context
+		ctrl      *gomock.Controller
+		resolver  *mocks.MockResolver
+		validator *netsafety.Validator
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+		resolver = mocks.NewMockResolver(ctrl)
+		validator = netsafety.NewValidator(netsafety.WithResolver(resolver))
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	DescribeTable("tells if an IP is public",
+		func(ip string, isPublic bool) {
+			Expect(netsafety.IsPublicIP(net.ParseIP(ip))).To(Equal(isPublic))
+		},
+		Entry("a public IPv4", "142.250.184.174", true),
+		Entry("a public IPv6", "2a00:1450:4003:80f::200e", true),
+		Entry("a loopback IPv4", "127.0.0.1", false),
+		Entry("a loopback IPv6", "::1", false),
+		Entry("a private IPv4", "192.168.1.1", false),
+		Entry("a private IPv6", "fd00::1", false),
+		Entry("a link-local IPv4", "169.254.169.254", false),
+		Entry("a link-local IPv6", "fe80::1", false),
+		Entry("a multicast IPv4", "224.0.0.1", false),
+		Entry("a multicast IPv6", "ff02::1", false),
+		Entry("the unspecified IPv4", "0.0.0.0", false),
+		Entry("an IPv4 of this network", "0.1.2.3", false),
+		Entry("a carrier-grade NAT IPv4", "100.64.0.1", false),
+		Entry("the broadcast IPv4", "255.255.255.255", false),
+		Entry("a private IPv4 mapped to IPv6", "::ffff:10.0.0.1", false),
+		Entry("a private IPv4 translated by NAT64", "64:ff9b::a00:1", false),
+	)
+
+	It("accepts the URLs whose hosts resolve to public addresses", func() {
+		resolver.EXPECT().LookupIPAddr(ctx, "google.com").Return([]net.IPAddr{{IP: net.ParseIP("142.250.184.174")}}, nil)
+
+		isValid, err := validator.ValidateURLs(ctx, []string{"https://google.com/search"})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(isValid).To(BeTrue())
+	})
+
+	It("rejects the URLs whose hosts resolve to a non public address", func() {
+		resolver.EXPECT().LookupIPAddr(ctx, "internal.example.com").Return([]net.IPAddr{
+			{IP: net.ParseIP("142.250.184.174")},
+			{IP: net.ParseIP("10.0.0.1")},
+		}, nil)
+
+		isValid, err := validator.ValidateURLs(ctx, []string{"https://internal.example.com"})
+
+		Expect(err).To(Equal(&url.RejectionError{Validator: "netsafety", Reason: "non public address: internal.example.com resolves to 10.0.0.1"}))
+		Expect(isValid).To(BeFalse())
+	})
+
+	It("rejects the URLs with a non public IP without resolving it", func() {
+		isValid, err := validator.ValidateURLs(ctx, []string{"http://169.254.169.254/latest/meta-data"})
+
+		Expect(err).To(MatchError(url.ErrURLRejected))
+		Expect(isValid).To(BeFalse())
+	})
+
+	It("accepts the non public addresses of the allowed networks", func() {
+		_, network, _ := net.ParseCIDR("10.0.0.0/8")
+		validator = netsafety.NewValidator(netsafety.WithResolver(resolver), netsafety.WithAllowedNetworks(network))
+
+		Expect(validator.ValidateURLs(ctx, []string{"http://10.1.2.3"})).To(BeTrue())
+	})
+
+	It("is unable to validate the URLs whose hosts can't be resolved", func() {
+		resolver.EXPECT().LookupIPAddr(ctx, "unknown.example.com").Return(nil, errors.New("no such host"))
+
+		isValid, err := validator.ValidateURLs(ctx, []string{"https://unknown.example.com"})
+
+		Expect(err).To(MatchError(url.ErrUnableToValidateURLs))
+		Expect(isValid).To(BeFalse())
+	})
+})
@@ -2,29 +2,119 @@ package pipeline
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
 )
 
+// Mode tells if the pipeline stops at the first validator that doesn't accept the URLs
+type Mode int
+
+const (
+	// StopOnFirstFailure doesn't run the validators after the first one that doesn't accept the URLs
+	StopOnFirstFailure Mode = iota
+	// RunAll runs every validator, so there is a verdict of each one
+	RunAll
+)
+
+// namedValidator is implemented by the validators that tell their name in the rejections
+type namedValidator interface {
+	Name() string
+}
+
+// Validator runs the validators in order, reporting the verdict of each one. The URLs are valid if all of them
+// accept the URLs. Otherwise, it returns a url.RejectionError with the name of the first validator that rejected
+// them, or the error of the first validator that was unable to check them if none rejected them.
 type Validator struct {
+	mode       Mode
 	validators []url.Validator
 }
 
-func (v *Validator) ValidateURLs(ctx context.Context, url []string) (bool, error) {
+func (v *Validator) ValidateURLs(ctx context.Context, urls []string) (bool, error) {
+	verdicts, err := v.run(ctx, urls)
+	if failed, isFailed := url.FirstFailedVerdict(verdicts); isFailed {
+		return false, &url.RejectionError{Validator: failed.Validator, Reason: failed.Reason}
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Verdicts implements the url.VerdictValidator interface. The verdicts of the validators that are
+// url.VerdictValidator too are reported instead of theirs.
+func (v *Validator) Verdicts(ctx context.Context, urls []string) []url.Verdict {
+	verdicts, _ := v.run(ctx, urls)
+	return verdicts
+}
+
+// run returns the verdicts of the validators, and the error of the first one that was unable to check the URLs
+func (v *Validator) run(ctx context.Context, urls []string) ([]url.Verdict, error) {
+	var verdicts []url.Verdict
+	var firstErr error
 	for _, validator := range v.validators {
-		areURLsValid, err := validator.ValidateURLs(ctx, url)
-		if err != nil {
-			return false, err
+		validatorVerdicts, err := verdictsOf(ctx, validator, urls)
+		verdicts = append(verdicts, validatorVerdicts...)
+		if firstErr == nil {
+			firstErr = err
 		}
-		if !areURLsValid {
-			return areURLsValid, err
+
+		_, isFailed := url.FirstFailedVerdict(validatorVerdicts)
+		if (isFailed || err != nil) && v.mode == StopOnFirstFailure {
+			break
 		}
 	}
-	return true, nil
+	return verdicts, firstErr
+}
+
+func verdictsOf(ctx context.Context, validator url.Validator, urls []string) ([]url.Verdict, error) {
+	switch validator := validator.(type) {
+	case *Validator:
+		return validator.run(ctx, urls)
+	case url.VerdictValidator:
+		verdicts := validator.Verdicts(ctx, urls)
+		if errored, isErrored := url.FirstErrorVerdict(verdicts); isErrored {
+			return verdicts, fmt.Errorf("%w: %s was unable to check them: %s", url.ErrUnableToValidateURLs, errored.Validator, errored.Reason)
+		}
+		return verdicts, nil
+	}
+
+	start := time.Now()
+	areURLsValid, err := validator.ValidateURLs(ctx, urls)
+	verdict := url.Verdict{Validator: nameOf(validator), Result: url.VerdictPass, Latency: time.Since(start)}
+	var rejection *url.RejectionError
+	switch {
+	case errors.As(err, &rejection):
+		verdict.Validator = rejection.Validator
+		verdict.Result = url.VerdictFail
+		verdict.Reason = rejection.Reason
+		err = nil
+	case err != nil:
+		verdict.Result = url.VerdictError
+		verdict.Reason = err.Error()
+	case !areURLsValid:
+		verdict.Result = url.VerdictFail
+		verdict.Reason = "the url is not valid"
+	}
+	return []url.Verdict{verdict}, err
+}
+
+func nameOf(validator url.Validator) string {
+	if named, ok := validator.(namedValidator); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", validator)
 }
 
 func NewValidator(validators ...url.Validator) *Validator {
+	return NewValidatorWithMode(StopOnFirstFailure, validators...)
+}
+
+func NewValidatorWithMode(mode Mode, validators ...url.Validator) *Validator {
 	return &Validator{
+		mode:       mode,
 		validators: validators,
 	}
 }
@@ -3,10 +3,14 @@ package pipeline_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
 
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
 	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/pipeline"
 )
 
@@ -19,8 +23,8 @@ var _ = Describe("Multiple Validator", func() {
 	)
 	BeforeEach(func() {
 		ctx = context.Background()
-		validatorOne = &FakeValidator{valueToReturn: true}
-		validatorTwo = &FakeValidator{valueToReturn: true}
+		validatorOne = &FakeValidator{name: "one", valueToReturn: true}
+		validatorTwo = &FakeValidator{name: "two", valueToReturn: true}
 		validator = pipeline.NewValidator(validatorOne, validatorTwo)
 	})
 
@@ -38,7 +42,7 @@ var _ = Describe("Multiple Validator", func() {
 			validatorOne.shouldReturn(false)
 			validURLs, err := validator.ValidateURLs(ctx, []string{"google.com"})
 
-			Expect(err).ToNot(HaveOccurred())
+			Expect(err).To(Equal(&url.RejectionError{Validator: "one", Reason: "the url is not valid"}))
 			Expect(validURLs).To(BeFalse())
 			Expect(validatorOne.shouldHaveBeenCalled()).To(BeTrue())
 			Expect(validatorTwo.shouldHaveBeenCalled()).To(BeFalse())
@@ -59,7 +63,7 @@ var _ = Describe("Multiple Validator", func() {
 			validatorTwo.shouldReturn(false)
 			validURLs, err := validator.ValidateURLs(ctx, []string{"google.com"})
 
-			Expect(err).ToNot(HaveOccurred())
+			Expect(err).To(Equal(&url.RejectionError{Validator: "two", Reason: "the url is not valid"}))
 			Expect(validURLs).To(BeFalse())
 			Expect(validatorOne.shouldHaveBeenCalled()).To(BeTrue())
 			Expect(validatorTwo.shouldHaveBeenCalled()).To(BeTrue())
@@ -74,9 +78,147 @@ var _ = Describe("Multiple Validator", func() {
 			Expect(validatorTwo.shouldHaveBeenCalled()).To(BeTrue())
 		})
 	})
+
+	When("a validator tells why it rejects the URLs", func() {
+		It("returns its rejection", func() {
+			rejection := &url.RejectionError{Validator: "inner", Reason: "the host is blocked"}
+			validatorOne.shouldErrorWith(rejection)
+
+			validURLs, err := validator.ValidateURLs(ctx, []string{"google.com"})
+
+			Expect(err).To(Equal(rejection))
+			Expect(validURLs).To(BeFalse())
+		})
+	})
+
+	Context("reporting the verdicts", func() {
+		It("reports the verdict of each validator", func() {
+			verdicts := validator.Verdicts(ctx, []string{"google.com"})
+
+			Expect(verdicts).To(HaveLen(2))
+			Expect(verdicts[0]).To(MatchFields(IgnoreExtras, Fields{"Validator": Equal("one"), "Result": Equal(url.VerdictPass), "Reason": BeEmpty()}))
+			Expect(verdicts[1]).To(MatchFields(IgnoreExtras, Fields{"Validator": Equal("two"), "Result": Equal(url.VerdictPass), "Reason": BeEmpty()}))
+		})
+
+		It("measures how long each validator takes", func() {
+			validatorOne.takes(10 * time.Millisecond)
+
+			verdicts := validator.Verdicts(ctx, []string{"google.com"})
+
+			Expect(verdicts[0].Latency).To(BeNumerically(">=", 10*time.Millisecond))
+		})
+
+		It("stops at the first validator that doesn't pass", func() {
+			validatorOne.shouldReturn(false)
+
+			verdicts := validator.Verdicts(ctx, []string{"google.com"})
+
+			Expect(verdicts).To(HaveLen(1))
+			Expect(verdicts[0]).To(MatchFields(IgnoreExtras, Fields{"Validator": Equal("one"), "Result": Equal(url.VerdictFail), "Reason": Equal("the url is not valid")}))
+			Expect(validatorTwo.shouldHaveBeenCalled()).To(BeFalse())
+		})
+
+		It("reports the validators that are unable to check the URLs", func() {
+			validatorTwo.shouldErrorWith(errors.New("unknown error"))
+
+			verdicts := validator.Verdicts(ctx, []string{"google.com"})
+
+			Expect(verdicts[1]).To(MatchFields(IgnoreExtras, Fields{"Validator": Equal("two"), "Result": Equal(url.VerdictError), "Reason": Equal("unknown error")}))
+		})
+
+		It("stops at the first validator that is unable to check the URLs", func() {
+			validatorOne.shouldErrorWith(errors.New("unknown error"))
+
+			verdicts := validator.Verdicts(ctx, []string{"google.com"})
+
+			Expect(verdicts).To(HaveLen(1))
+			Expect(verdicts[0].Result).To(Equal(url.VerdictError))
+			Expect(validatorTwo.shouldHaveBeenCalled()).To(BeFalse())
+		})
+
+		It("returns the error of the nested pipelines that were unable to check the URLs", func() {
+			validatorTwo.shouldErrorWith(errors.New("unknown error"))
+			validator = pipeline.NewValidator(validatorOne, pipeline.NewValidator(validatorTwo))
+
+			validURLs, err := validator.ValidateURLs(ctx, []string{"google.com"})
+
+			Expect(err).To(MatchError("unknown error"))
+			Expect(validURLs).To(BeFalse())
+		})
+
+		It("reports the verdicts of the nested pipelines", func() {
+			validatorThree := &FakeValidator{name: "three"}
+			validator = pipeline.NewValidator(validatorOne, pipeline.NewValidator(validatorTwo, validatorThree))
+
+			verdicts := validator.Verdicts(ctx, []string{"google.com"})
+
+			Expect(verdicts).To(HaveLen(3))
+			Expect(verdicts[2]).To(MatchFields(IgnoreExtras, Fields{"Validator": Equal("three"), "Result": Equal(url.VerdictFail)}))
+		})
+
+		When("the pipeline runs all the validators", func() {
+			BeforeEach(func() {
+				validator = pipeline.NewValidatorWithMode(pipeline.RunAll, validatorOne, validatorTwo)
+			})
+
+			It("reports the verdicts of the validators after the first one that doesn't pass", func() {
+				validatorOne.shouldReturn(false)
+				validatorTwo.shouldErrorWith(errors.New("unknown error"))
+
+				verdicts := validator.Verdicts(ctx, []string{"google.com"})
+
+				Expect(verdicts).To(HaveLen(2))
+				Expect(verdicts[0].Result).To(Equal(url.VerdictFail))
+				Expect(verdicts[1].Result).To(Equal(url.VerdictError))
+				Expect(validatorTwo.shouldHaveBeenCalled()).To(BeTrue())
+			})
+
+			It("rejects the URLs with the first validator that rejects them", func() {
+				validatorTwo.shouldReturn(false)
+
+				validURLs, err := validator.ValidateURLs(ctx, []string{"google.com"})
+
+				Expect(err).To(Equal(&url.RejectionError{Validator: "two", Reason: "the url is not valid"}))
+				Expect(validURLs).To(BeFalse())
+			})
+
+			It("rejects the URLs even if a previous validator was unable to check them", func() {
+				validatorOne.shouldErrorWith(errors.New("unknown error"))
+				validatorTwo.shouldReturn(false)
+
+				validURLs, err := validator.ValidateURLs(ctx, []string{"google.com"})
+
+				Expect(err).To(Equal(&url.RejectionError{Validator: "two", Reason: "the url is not valid"}))
+				Expect(validURLs).To(BeFalse())
+			})
+
+			It("doesn't reject the URLs if a validator was unable to check them and none rejected them", func() {
+				validatorOne.shouldErrorWith(fmt.Errorf("%w: temporary failure in name resolution", url.ErrUnableToValidateURLs))
+
+				validURLs, err := validator.ValidateURLs(ctx, []string{"google.com"})
+
+				Expect(err).To(MatchError(url.ErrUnableToValidateURLs))
+				Expect(err).ToNot(MatchError(url.ErrURLRejected))
+				Expect(validURLs).To(BeFalse())
+				Expect(validatorTwo.shouldHaveBeenCalled()).To(BeTrue())
+			})
+		})
+	})
+
+	When("a validator has no name", func() {
+		It("is named by its type", func() {
+			validator = pipeline.NewValidator(&UnnamedValidator{})
+
+			_, err := validator.ValidateURLs(ctx, []string{"google.com"})
+
+			Expect(err).To(Equal(&url.RejectionError{Validator: "*pipeline_test.UnnamedValidator", Reason: "the url is not valid"}))
+		})
+	})
 })
 
 type FakeValidator struct {
+	name          string
+	latency       time.Duration
 	hasBeenCalled bool
 	valueToReturn bool
 	errorToReturn error
@@ -89,11 +231,26 @@ func (f *FakeValidator) shouldReturn(value bool) {
 func (f *FakeValidator) shouldHaveBeenCalled() bool {
 	return f.hasBeenCalled
 }
+func (f *FakeValidator) takes(latency time.Duration) {
+	f.latency = latency
+}
+
 func (f *FakeValidator) shouldErrorWith(err error) {
 	f.errorToReturn = err
 }
 
+func (f *FakeValidator) Name() string {
+	return f.name
+}
+
 func (f *FakeValidator) ValidateURLs(ctx context.Context, url []string) (bool, error) {
 	f.hasBeenCalled = true
+	time.Sleep(f.latency)
 	return f.valueToReturn, f.errorToReturn
 }
+
+type UnnamedValidator struct{}
+
+func (u *UnnamedValidator) ValidateURLs(ctx context.Context, url []string) (bool, error) {
+	return false, nil
+}
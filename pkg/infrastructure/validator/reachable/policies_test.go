@@ -0,0 +1,292 @@
+package reachable_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/netsafety"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/reachable"
+)
+
+var _ = Describe("Reachable policies", func() {
+	var (
+		ctx    context.Context
+		mux    *http.ServeMux
+		server *httptest.Server
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		mux = http.NewServeMux()
+		mux.HandleFunc("/ok", func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusOK)
+		})
+		mux.HandleFunc("/no-content", func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNoContent)
+		})
+		mux.HandleFunc("/forbidden", func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusForbidden)
+		})
+		mux.HandleFunc("/redirect", func(writer http.ResponseWriter, request *http.Request) {
+			http.Redirect(writer, request, "/ok", http.StatusMovedPermanently)
+		})
+		mux.HandleFunc("/redirect-twice", func(writer http.ResponseWriter, request *http.Request) {
+			http.Redirect(writer, request, "/redirect", http.StatusFound)
+		})
+		server = httptest.NewServer(mux)
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("accepts any 2xx status code by default", func() {
+		validator := reachable.NewValidator(http.DefaultClient, time.Second)
+
+		isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/ok", server.URL + "/no-content"})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(isValid).To(BeTrue())
+	})
+
+	It("records the final URL and status code of each URL", func() {
+		validator := reachable.NewValidator(http.DefaultClient, time.Second)
+
+		results := validator.Check(ctx, []string{server.URL + "/redirect", server.URL + "/forbidden"})
+
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].URL).To(Equal(server.URL + "/redirect"))
+		Expect(results[0].FinalURL).To(Equal(server.URL + "/ok"))
+		Expect(results[0].StatusCode).To(Equal(http.StatusOK))
+		Expect(results[0].Err).ToNot(HaveOccurred())
+		Expect(results[1].FinalURL).To(Equal(server.URL + "/forbidden"))
+		Expect(results[1].StatusCode).To(Equal(http.StatusForbidden))
+		Expect(results[1].Err).To(MatchError(ContainSubstring("403 Forbidden")))
+	})
+
+	When("the accepted status classes are configured", func() {
+		It("accepts the status codes of those classes", func() {
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithAcceptedStatusClasses(2, 4))
+
+			isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/forbidden"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isValid).To(BeTrue())
+		})
+
+		It("rejects the status codes of other classes", func() {
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithAcceptedStatusClasses(4))
+
+			isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/ok"})
+
+			Expect(err).To(MatchError(url.ErrURLRejected))
+			Expect(err.Error()).To(ContainSubstring("200 OK"))
+			Expect(isValid).To(BeFalse())
+		})
+	})
+
+	When("the redirects are limited", func() {
+		It("follows the redirects up to the limit", func() {
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithMaxRedirects(2))
+
+			results := validator.Check(ctx, []string{server.URL + "/redirect-twice"})
+
+			Expect(results[0].Err).ToNot(HaveOccurred())
+			Expect(results[0].FinalURL).To(Equal(server.URL + "/ok"))
+		})
+
+		It("fails when there are more redirects than the limit", func() {
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithMaxRedirects(1))
+
+			isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/redirect-twice"})
+
+			Expect(err).To(MatchError(url.ErrURLRejected))
+			Expect(err.Error()).To(ContainSubstring(reachable.ErrTooManyRedirects.Error()))
+			Expect(isValid).To(BeFalse())
+		})
+
+		It("checks the redirect status code when the limit is zero", func() {
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithMaxRedirects(0), reachable.WithAcceptedStatusClasses(3))
+
+			results := validator.Check(ctx, []string{server.URL + "/redirect"})
+
+			Expect(results[0].Err).ToNot(HaveOccurred())
+			Expect(results[0].FinalURL).To(Equal(server.URL + "/redirect"))
+			Expect(results[0].StatusCode).To(Equal(http.StatusMovedPermanently))
+		})
+	})
+
+	When("HEAD is tried first", func() {
+		It("doesn't make a GET request if the HEAD one is accepted", func() {
+			var methods []string
+			mux.HandleFunc("/methods", func(writer http.ResponseWriter, request *http.Request) {
+				methods = append(methods, request.Method)
+			})
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithHeadFirst())
+
+			isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/methods"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isValid).To(BeTrue())
+			Expect(methods).To(Equal([]string{http.MethodHead}))
+		})
+
+		It("falls back to GET if the HEAD one is not accepted", func() {
+			var methods []string
+			mux.HandleFunc("/get-only", func(writer http.ResponseWriter, request *http.Request) {
+				methods = append(methods, request.Method)
+				if request.Method != http.MethodGet {
+					writer.WriteHeader(http.StatusMethodNotAllowed)
+				}
+			})
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithHeadFirst())
+
+			results := validator.Check(ctx, []string{server.URL + "/get-only"})
+
+			Expect(results[0].Err).ToNot(HaveOccurred())
+			Expect(results[0].StatusCode).To(Equal(http.StatusOK))
+			Expect(methods).To(Equal([]string{http.MethodHead, http.MethodGet}))
+		})
+	})
+
+	When("the retries are configured", func() {
+		It("retries the URLs answering with a 5xx status code", func() {
+			var requests int32
+			mux.HandleFunc("/flaky", func(writer http.ResponseWriter, request *http.Request) {
+				if atomic.AddInt32(&requests, 1) < 3 {
+					writer.WriteHeader(http.StatusServiceUnavailable)
+				}
+			})
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithRetries(2, time.Millisecond))
+
+			isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/flaky"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isValid).To(BeTrue())
+			Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(3))
+		})
+
+		It("retries the URLs that time out", func() {
+			var requests int32
+			mux.HandleFunc("/slow", func(writer http.ResponseWriter, request *http.Request) {
+				if atomic.AddInt32(&requests, 1) < 2 {
+					time.Sleep(200 * time.Millisecond)
+				}
+			})
+			validator := reachable.NewValidator(http.DefaultClient, 50*time.Millisecond, reachable.WithRetries(1, time.Millisecond))
+
+			isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/slow"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isValid).To(BeTrue())
+			Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(2))
+		})
+
+		It("doesn't retry the URLs answering with a 4xx status code", func() {
+			var requests int32
+			mux.HandleFunc("/not-found", func(writer http.ResponseWriter, request *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				writer.WriteHeader(http.StatusNotFound)
+			})
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithRetries(2, time.Millisecond))
+
+			isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/not-found"})
+
+			Expect(err).To(MatchError(url.ErrURLRejected))
+			Expect(isValid).To(BeFalse())
+			Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(1))
+		})
+
+		It("fails when the retries are exhausted", func() {
+			mux.HandleFunc("/down", func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusBadGateway)
+			})
+			validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithRetries(1, time.Millisecond))
+
+			isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/down"})
+
+			Expect(err).To(MatchError(url.ErrUnableToValidateURLs))
+			Expect(err.Error()).To(ContainSubstring("502 Bad Gateway"))
+			Expect(isValid).To(BeFalse())
+		})
+	})
+
+	It("rejects the URLs answered with a status code that is not accepted, even if others can't be checked", func() {
+		mux.HandleFunc("/down", func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		})
+		validator := reachable.NewValidator(http.DefaultClient, time.Second)
+
+		isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/down", server.URL + "/forbidden"})
+
+		var rejection *url.RejectionError
+		Expect(errors.As(err, &rejection)).To(BeTrue())
+		Expect(rejection.Validator).To(Equal("reachable"))
+		Expect(rejection.Reason).To(ContainSubstring("403 Forbidden"))
+		Expect(isValid).To(BeFalse())
+	})
+
+	It("reports a verdict for each URL with the final URL and the status code", func() {
+		mux.HandleFunc("/down", func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		})
+		validator := reachable.NewValidator(http.DefaultClient, time.Second)
+
+		verdicts := validator.Verdicts(ctx, []string{server.URL + "/redirect", server.URL + "/forbidden", server.URL + "/down"})
+
+		Expect(verdicts).To(HaveLen(3))
+		Expect(verdicts[0]).To(MatchFields(IgnoreExtras, Fields{
+			"Validator":  Equal("reachable"),
+			"Result":     Equal(url.VerdictPass),
+			"URL":        Equal(server.URL + "/redirect"),
+			"FinalURL":   Equal(server.URL + "/ok"),
+			"StatusCode": Equal(http.StatusOK),
+		}))
+		Expect(verdicts[1]).To(MatchFields(IgnoreExtras, Fields{
+			"Result":     Equal(url.VerdictFail),
+			"FinalURL":   Equal(server.URL + "/forbidden"),
+			"StatusCode": Equal(http.StatusForbidden),
+			"Reason":     ContainSubstring("403 Forbidden"),
+		}))
+		Expect(verdicts[2]).To(MatchFields(IgnoreExtras, Fields{
+			"Result":     Equal(url.VerdictError),
+			"StatusCode": Equal(http.StatusServiceUnavailable),
+		}))
+	})
+
+	It("reaches the responses larger than the limit of the network safety client", func() {
+		mux.HandleFunc("/large", func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = writer.Write([]byte(strings.Repeat("a", 2<<20)))
+		})
+		_, loopback, _ := net.ParseCIDR("127.0.0.1/32")
+		client := netsafety.NewHTTPClient(time.Second, netsafety.WithAllowedNetworks(loopback))
+		validator := reachable.NewValidator(client, time.Second, reachable.WithHeadFirst())
+
+		isValid, err := validator.ValidateURLs(ctx, []string{server.URL + "/large"})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(isValid).To(BeTrue())
+	})
+
+	It("sends the configured User-Agent", func() {
+		userAgents := make(chan string, 1)
+		mux.HandleFunc("/user-agent", func(writer http.ResponseWriter, request *http.Request) {
+			userAgents <- request.UserAgent()
+		})
+		validator := reachable.NewValidator(http.DefaultClient, time.Second, reachable.WithUserAgent("url-validator/1.0"))
+
+		_, err := validator.ValidateURLs(ctx, []string{server.URL + "/user-agent"})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(userAgents).To(Receive(Equal("url-validator/1.0")))
+	})
+})
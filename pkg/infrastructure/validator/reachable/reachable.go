@@ -2,74 +2,262 @@ package reachable
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/netsafety"
 )
 
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// Validator checks that the URLs are reachable, following the redirects. By default a URL is reachable if it
+// answers a GET with a 2xx status code, which can be changed with the options.
 type Validator struct {
 	client     *http.Client
 	maxTimeout time.Duration
+
+	acceptedStatusClasses map[int]bool
+	headFirst             bool
+	maxRedirects          int
+	retries               int
+	retryBackoff          time.Duration
+	userAgent             string
+}
+
+// Option configures the policies of a Validator created through NewValidator
+type Option func(v *Validator)
+
+// WithAcceptedStatusClasses replaces the classes of the status codes of the reachable URLs, like 2 for the 2xx ones.
+// Only the 2xx status codes are accepted by default.
+func WithAcceptedStatusClasses(classes ...int) Option {
+	return func(v *Validator) {
+		v.acceptedStatusClasses = map[int]bool{}
+		for _, class := range classes {
+			v.acceptedStatusClasses[class] = true
+		}
+	}
 }
 
-type invalidURL struct {
-	url string
-	err error
+// WithHeadFirst tries a HEAD request before the GET one, so the body is only downloaded if the HEAD one
+// is not accepted, as some servers don't implement it.
+func WithHeadFirst() Option {
+	return func(v *Validator) {
+		v.headFirst = true
+	}
+}
+
+// WithMaxRedirects limits the redirects followed, the URLs that redirect more times are not reachable.
+// With 0, the redirects are not followed, so their status code is checked instead.
+func WithMaxRedirects(maxRedirects int) Option {
+	return func(v *Validator) {
+		v.maxRedirects = maxRedirects
+	}
+}
+
+// WithRetries checks the URLs again up to retries times when they time out or answer with a 5xx status code,
+// waiting backoff before the first retry, and doubling it for each of the next ones.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(v *Validator) {
+		v.retries = retries
+		v.retryBackoff = backoff
+	}
 }
 
+// WithUserAgent sets the User-Agent header of the requests, instead of the one of the Go HTTP client
+func WithUserAgent(userAgent string) Option {
+	return func(v *Validator) {
+		v.userAgent = userAgent
+	}
+}
+
+// Result is the outcome of checking if a URL is reachable
+type Result struct {
+	URL string
+	// FinalURL is the URL answered after following the redirects, empty if no URL answered
+	FinalURL string
+	// StatusCode is the status code of the FinalURL, zero if no URL answered
+	StatusCode int
+	// Err is why the URL is not reachable, nil if it is
+	Err     error
+	Latency time.Duration
+}
+
+// Rejected tells if the URL is not reachable because of the final answer of its server, like a status code
+// that is not accepted, instead of a failure that may be transient, like a timeout or a 5xx status code.
+func (r Result) Rejected() bool {
+	if r.Err == nil {
+		return false
+	}
+	if r.StatusCode != 0 {
+		return r.StatusCode < http.StatusInternalServerError
+	}
+	return errors.Is(r.Err, ErrTooManyRedirects) || errors.Is(r.Err, netsafety.ErrTooManyRedirects) || errors.Is(r.Err, netsafety.ErrNonPublicAddress)
+}
+
+func (v *Validator) Name() string {
+	return "reachable"
+}
+
+// ValidateURLs rejects the URLs answered with a status code that is not accepted, returning a url.RejectionError.
+// The URLs that can't be checked, because of a transport error, a timeout or a 5xx status code once retried,
+// make it return an error wrapping url.ErrUnableToValidateURLs if none is rejected.
 func (v *Validator) ValidateURLs(ctx context.Context, urls []string) (bool, error) {
-	validURLCh := make(chan string, len(urls))
-	invalidURLCh := make(chan invalidURL, len(urls))
+	var unableErr error
+	for _, result := range v.Check(ctx, urls) {
+		if result.Rejected() {
+			return false, &url.RejectionError{Validator: v.Name(), Reason: result.Err.Error()}
+		}
+		if result.Err != nil && unableErr == nil {
+			unableErr = fmt.Errorf("%w: %s", url.ErrUnableToValidateURLs, result.Err)
+		}
+	}
+	if unableErr != nil {
+		return false, unableErr
+	}
+	return true, nil
+}
+
+// Verdicts implements the url.VerdictValidator interface, reporting a verdict for each URL with
+// the final URL and the status code it answered.
+func (v *Validator) Verdicts(ctx context.Context, urls []string) []url.Verdict {
+	results := v.Check(ctx, urls)
+	verdicts := make([]url.Verdict, 0, len(results))
+	for _, result := range results {
+		verdict := url.Verdict{
+			Validator:  v.Name(),
+			Result:     url.VerdictPass,
+			URL:        result.URL,
+			FinalURL:   result.FinalURL,
+			StatusCode: result.StatusCode,
+			Latency:    result.Latency,
+		}
+		switch {
+		case result.Rejected():
+			verdict.Result = url.VerdictFail
+			verdict.Reason = result.Err.Error()
+		case result.Err != nil:
+			verdict.Result = url.VerdictError
+			verdict.Reason = result.Err.Error()
+		}
+		verdicts = append(verdicts, verdict)
+	}
+	return verdicts
+}
 
+// Check checks the URLs concurrently, returning the result of each one in the same order.
+func (v *Validator) Check(ctx context.Context, urls []string) []Result {
+	results := make([]Result, len(urls))
 	wg := &sync.WaitGroup{}
 	wg.Add(len(urls))
-	for _, url := range urls {
-		go v.validateURL(ctx, url, wg, validURLCh, invalidURLCh)
+	for i, aLongURL := range urls {
+		go func(i int, aLongURL string) {
+			defer wg.Done()
+			results[i] = v.checkURL(ctx, aLongURL)
+		}(i, aLongURL)
 	}
 	wg.Wait()
+	return results
+}
+
+func (v *Validator) checkURL(ctx context.Context, aLongURL string) Result {
+	start := time.Now()
+	backoff := v.retryBackoff
+	for attempt := 0; ; attempt++ {
+		result := v.checkURLOnce(ctx, aLongURL)
+		result.Latency = time.Since(start)
+		if attempt >= v.retries || !isRetryable(result) {
+			return result
+		}
 
-	for i := 0; i < len(urls); i++ {
 		select {
-		case <-validURLCh:
-		case invalid := <-invalidURLCh:
-			return false, fmt.Errorf("%w: %s", url.ErrUnableToValidateURLs, invalid.err)
+		case <-ctx.Done():
+			return result
+		case <-time.After(backoff):
+			backoff *= 2
 		}
 	}
-	return true, nil
 }
 
-func (v *Validator) validateURL(ctx context.Context, url string, wg *sync.WaitGroup, validURLCh chan<- string, invalidURLCh chan<- invalidURL) {
-	defer wg.Done()
+func (v *Validator) checkURLOnce(ctx context.Context, aLongURL string) Result {
+	if v.headFirst {
+		result := v.request(ctx, http.MethodHead, aLongURL)
+		if result.Err == nil {
+			return result
+		}
+	}
+	return v.request(ctx, http.MethodGet, aLongURL)
+}
 
+func (v *Validator) request(ctx context.Context, method string, aLongURL string) Result {
 	ctx, cancel := context.WithTimeout(ctx, v.maxTimeout)
 	defer cancel()
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	request, err := http.NewRequestWithContext(ctx, method, aLongURL, nil)
 	if err != nil {
-		invalidURLCh <- invalidURL{url, err}
-		return
+		return Result{URL: aLongURL, Err: err}
+	}
+	if v.userAgent != "" {
+		request.Header.Set("User-Agent", v.userAgent)
 	}
 
 	response, err := v.client.Do(request)
 	if err != nil {
-		invalidURLCh <- invalidURL{url, err}
-		return
+		return Result{URL: aLongURL, Err: err}
 	}
+	// the body is not read, so it's not downloaded
+	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		invalidURLCh <- invalidURL{url, fmt.Errorf("could not reach URL '%s': '%s'", url, response.Status)}
-		return
+	result := Result{URL: aLongURL, FinalURL: response.Request.URL.String(), StatusCode: response.StatusCode}
+	if !v.acceptedStatusClasses[response.StatusCode/100] {
+		result.Err = fmt.Errorf("could not reach URL '%s': '%s'", aLongURL, response.Status)
 	}
+	return result
+}
 
-	validURLCh <- url
+func isRetryable(result Result) bool {
+	if result.StatusCode >= http.StatusInternalServerError {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(result.Err, &netErr) && netErr.Timeout()
 }
 
-func NewValidator(client *http.Client, maxTimeout time.Duration) *Validator {
-	return &Validator{
-		client:     client,
-		maxTimeout: maxTimeout,
+// limitRedirects returns a copy of the client that fails when it's redirected more than maxRedirects times,
+// keeping the redirect policy of the client.
+func limitRedirects(client *http.Client, maxRedirects int) *http.Client {
+	limited := *client
+	limited.CheckRedirect = func(request *http.Request, via []*http.Request) error {
+		if maxRedirects == 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > maxRedirects {
+			return fmt.Errorf("%w: more than %d", ErrTooManyRedirects, maxRedirects)
+		}
+		if client.CheckRedirect != nil {
+			return client.CheckRedirect(request, via)
+		}
+		return nil
+	}
+	return &limited
+}
+
+func NewValidator(client *http.Client, maxTimeout time.Duration, options ...Option) *Validator {
+	v := &Validator{
+		client:                client,
+		maxTimeout:            maxTimeout,
+		acceptedStatusClasses: map[int]bool{2: true},
+		maxRedirects:          -1,
+	}
+	for _, option := range options {
+		option(v)
+	}
+	if v.maxRedirects >= 0 {
+		v.client = limitRedirects(client, v.maxRedirects)
 	}
+	return v
 }
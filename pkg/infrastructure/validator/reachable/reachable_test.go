@@ -51,13 +51,13 @@ var _ = Describe("Reachable", func() {
 		})
 	})
 	When("a URL returns an HTTP code different from 200", func() {
-		It("fails with an error", func() {
+		It("rejects it", func() {
 			done := make(chan interface{})
 			go func() {
 				defer GinkgoRecover()
 				isValid, err := validator.ValidateURLs(ctx, errorURLsToValidate())
 
-				Expect(err).To(MatchError(url.ErrUnableToValidateURLs))
+				Expect(err).To(MatchError(url.ErrURLRejected))
 				Expect(err.Error()).To(ContainSubstring("404 Not Found"))
 				Expect(isValid).To(BeFalse())
 				close(done)
@@ -13,6 +13,10 @@ type Validator struct {
 	safebrowser *safebrowsing.SafeBrowser
 }
 
+func (s *Validator) Name() string {
+	return "safebrowsing"
+}
+
 func (s *Validator) ValidateURLs(ctx context.Context, urls []string) (bool, error) {
 	threats, err := s.safebrowser.LookupURLsContext(ctx, urls)
 	if err != nil {
@@ -10,6 +10,10 @@ type Validator struct {
 	allowedPrefixes []string
 }
 
+func (v *Validator) Name() string {
+	return "schema"
+}
+
 func (v *Validator) ValidateURLs(ctx context.Context, urls []string) (bool, error) {
 	for _, prefix := range v.allowedPrefixes {
 		for _, url := range urls {
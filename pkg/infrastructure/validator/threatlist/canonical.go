@@ -0,0 +1,299 @@
+package threatlist
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidURL = errors.New("invalid url")
+
+// canonicalURL is a URL canonicalized following the rules of the Safe Browsing API
+// https://developers.google.com/safe-browsing/v4/urls-hashing#canonicalization
+type canonicalURL struct {
+	scheme   string
+	host     string
+	path     string
+	query    string
+	hasQuery bool
+	isIP     bool
+}
+
+func (u *canonicalURL) String() string {
+	return u.scheme + "://" + u.expression()
+}
+
+// expression is the URL without the scheme, which is what the lists match
+func (u *canonicalURL) expression() string {
+	return u.host + u.pathAndQuery()
+}
+
+func (u *canonicalURL) pathAndQuery() string {
+	if u.hasQuery {
+		return u.path + "?" + u.query
+	}
+	return u.path
+}
+
+// Canonicalize returns the URL canonicalized as the Safe Browsing API does before looking it up
+func Canonicalize(rawURL string) (string, error) {
+	canonical, err := canonicalize(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return canonical.String(), nil
+}
+
+func canonicalize(rawURL string) (*canonicalURL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	rawURL = strings.NewReplacer("\t", "", "\r", "", "\n", "").Replace(rawURL)
+	if fragment := strings.IndexByte(rawURL, '#'); fragment >= 0 {
+		rawURL = rawURL[:fragment]
+	}
+
+	canonical := &canonicalURL{scheme: "http"}
+	if separator := strings.Index(rawURL, "://"); separator > 0 && isScheme(rawURL[:separator]) {
+		canonical.scheme = strings.ToLower(rawURL[:separator])
+		rawURL = rawURL[separator+3:]
+	}
+
+	host, pathAndQuery := rawURL, "/"
+	if end := strings.IndexAny(rawURL, "/?"); end >= 0 {
+		host, pathAndQuery = rawURL[:end], rawURL[end:]
+	}
+	path, query := pathAndQuery, ""
+	if start := strings.IndexByte(pathAndQuery, '?'); start >= 0 {
+		path, query = pathAndQuery[:start], pathAndQuery[start+1:]
+		canonical.hasQuery = true
+	}
+
+	canonical.host, canonical.isIP = canonicalHost(host)
+	if canonical.host == "" {
+		return nil, fmt.Errorf("%w: the url %s has no host", ErrInvalidURL, rawURL)
+	}
+	canonical.path = escape(canonicalPath(unescape(path)))
+	canonical.query = escape(unescape(query))
+	return canonical, nil
+}
+
+func isScheme(scheme string) bool {
+	for i, c := range scheme {
+		isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		isOther := (c >= '0' && c <= '9') || c == '+' || c == '-' || c == '.'
+		if !isLetter && (i == 0 || !isOther) {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalHost removes the user info and the port, and normalizes the dots, the case and the IPs of the host
+func canonicalHost(host string) (string, bool) {
+	if at := strings.LastIndexByte(host, '@'); at >= 0 {
+		host = host[at+1:]
+	}
+	if colon := strings.LastIndexByte(host, ':'); colon >= 0 && isDigits(host[colon+1:]) {
+		host = host[:colon]
+	}
+
+	host = lowerASCII(unescape(host))
+	labels := strings.FieldsFunc(host, func(c rune) bool { return c == '.' })
+	host = strings.Join(labels, ".")
+	if ip, ok := canonicalIP(labels); ok {
+		return ip, true
+	}
+	return escape(host), false
+}
+
+// canonicalIP normalizes the IPv4 addresses written with less than four parts, or with octal or hexadecimal parts
+func canonicalIP(parts []string) (string, bool) {
+	if len(parts) == 0 || len(parts) > 4 {
+		return "", false
+	}
+	values := make([]uint64, len(parts))
+	for i, part := range parts {
+		value, err := parseIPPart(part)
+		if err != nil {
+			return "", false
+		}
+		values[i] = value
+	}
+
+	// the last part fills the bytes not filled by the previous ones
+	var ip uint64
+	for _, value := range values[:len(values)-1] {
+		if value > 0xff {
+			return "", false
+		}
+		ip = ip<<8 | value
+	}
+	remainingBits := uint(8 * (5 - len(values)))
+	last := values[len(values)-1]
+	if last >= 1<<remainingBits {
+		return "", false
+	}
+	ip = ip<<remainingBits | last
+	return fmt.Sprintf("%d.%d.%d.%d", ip>>24, ip>>16&0xff, ip>>8&0xff, ip&0xff), true
+}
+
+func parseIPPart(part string) (uint64, error) {
+	switch {
+	case strings.HasPrefix(part, "0x"):
+		return strconv.ParseUint(part[2:], 16, 32)
+	case len(part) > 1 && part[0] == '0':
+		return strconv.ParseUint(part[1:], 8, 32)
+	default:
+		return strconv.ParseUint(part, 10, 32)
+	}
+}
+
+// canonicalPath resolves the "." and ".." segments and removes the empty ones
+func canonicalPath(path string) string {
+	var segments []string
+	rawSegments := strings.Split(path, "/")
+	for _, segment := range rawSegments {
+		switch segment {
+		case "", ".":
+		case "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+		default:
+			segments = append(segments, segment)
+		}
+	}
+
+	canonical := "/" + strings.Join(segments, "/")
+	last := rawSegments[len(rawSegments)-1]
+	if len(segments) > 0 && (last == "" || last == "." || last == "..") {
+		canonical += "/"
+	}
+	return canonical
+}
+
+// unescape percent-unescapes the text until it has no more escapes, leaving the invalid ones
+func unescape(text string) string {
+	for {
+		unescaped := unescapeOnce(text)
+		if unescaped == text {
+			return text
+		}
+		text = unescaped
+	}
+}
+
+func unescapeOnce(text string) string {
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '%' && i+2 < len(text) && isHex(text[i+1]) && isHex(text[i+2]) {
+			value, _ := strconv.ParseUint(text[i+1:i+3], 16, 8)
+			builder.WriteByte(byte(value))
+			i += 2
+			continue
+		}
+		builder.WriteByte(text[i])
+	}
+	return builder.String()
+}
+
+// escape percent-escapes the control, space, non ASCII, '#' and '%' characters
+func escape(text string) string {
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c <= 0x20 || c >= 0x7f || c == '#' || c == '%' {
+			fmt.Fprintf(&builder, "%%%02X", c)
+			continue
+		}
+		builder.WriteByte(c)
+	}
+	return builder.String()
+}
+
+// lowerASCII lowercases the ASCII letters only, keeping the bytes that are not valid UTF-8
+func lowerASCII(text string) string {
+	lowered := []byte(text)
+	for i, c := range lowered {
+		if c >= 'A' && c <= 'Z' {
+			lowered[i] = c + 'a' - 'A'
+		}
+	}
+	return string(lowered)
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isDigits(text string) bool {
+	for _, c := range text {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return text != ""
+}
+
+// Expressions returns the host suffix and path prefix expressions of the canonicalized URL that are
+// looked up in the lists, as the Safe Browsing API does.
+// https://developers.google.com/safe-browsing/v4/urls-hashing#suffixprefix-expressions
+func Expressions(rawURL string) ([]string, error) {
+	canonical, err := canonicalize(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return canonical.expressions(), nil
+}
+
+func (u *canonicalURL) expressions() []string {
+	var expressions []string
+	for _, host := range u.hostSuffixes() {
+		for _, path := range u.pathPrefixes() {
+			expressions = append(expressions, host+path)
+		}
+	}
+	return expressions
+}
+
+// hostSuffixes are the exact host and up to four hosts formed from its last five labels, without the top level domain
+func (u *canonicalURL) hostSuffixes() []string {
+	suffixes := []string{u.host}
+	if u.isIP {
+		return suffixes
+	}
+	labels := strings.Split(u.host, ".")
+	start := len(labels) - 5
+	if start < 1 {
+		start = 1
+	}
+	for i := start; i < len(labels)-1; i++ {
+		suffixes = append(suffixes, strings.Join(labels[i:], "."))
+	}
+	return suffixes
+}
+
+// pathPrefixes are the exact path with and without the query, and up to four directories from the root
+func (u *canonicalURL) pathPrefixes() []string {
+	prefixes := []string{u.pathAndQuery()}
+	add := func(prefix string) {
+		for _, existing := range prefixes {
+			if existing == prefix {
+				return
+			}
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	add(u.path)
+	directory := "/"
+	segments := strings.Split(u.path[1:], "/")
+	for i := 0; i < 4; i++ {
+		add(directory)
+		if i >= len(segments)-1 {
+			break
+		}
+		directory += segments[i] + "/"
+	}
+	return prefixes
+}
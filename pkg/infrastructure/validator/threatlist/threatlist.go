@@ -0,0 +1,263 @@
+package threatlist
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+)
+
+var ErrInvalidList = errors.New("invalid threat list")
+
+// LookupMode is how the URLs are matched against the lists
+type LookupMode int
+
+const (
+	// LookupCanonical canonicalizes the URLs and the URL prefixes, and matches their host suffix and path prefix
+	// expressions as the Safe Browsing API does, so the subdomains of a listed host are matched too
+	LookupCanonical LookupMode = iota
+	// LookupExact matches the hosts and the URLs without the scheme as they are written
+	LookupExact
+)
+
+// List loads a threat list into the validator
+type List func(v *Validator) error
+
+// HostList loads a file with a host per line, ignoring the empty lines and the comments starting with #
+func HostList(path string) List {
+	return func(v *Validator) error {
+		return readLines(path, func(line string) error {
+			host := v.normalizeHost(line)
+			if host == "" {
+				return fmt.Errorf("%w: empty host", ErrInvalidList)
+			}
+			v.hosts[host] = filepath.Base(path)
+			return nil
+		})
+	}
+}
+
+// URLPrefixList loads a file with a URL prefix per line, ignoring the empty lines and the comments starting with #.
+// The scheme of the prefixes is ignored.
+func URLPrefixList(path string) List {
+	return func(v *Validator) error {
+		return readLines(path, func(line string) error {
+			prefix, err := v.normalizeURLPrefix(line)
+			if err != nil {
+				return err
+			}
+			v.urlPrefixes = append(v.urlPrefixes, listed{value: prefix, list: filepath.Base(path)})
+			return nil
+		})
+	}
+}
+
+// HashPrefixFile loads a file with the SHA256 prefixes of prefixSize bytes of the expressions, concatenated as the
+// raw hashes of the threat lists of the Safe Browsing v4 API. The hashes are of the canonical expressions, so
+// they can't be loaded with LookupExact.
+//
+// Only the full hashes of 32 bytes reject the URLs. A shorter prefix may be shared with safe expressions, and the
+// Safe Browsing API confirms it with the full hashes, which are not available locally, so the URLs matching it
+// can't be validated.
+func HashPrefixFile(path string, prefixSize int) List {
+	return func(v *Validator) error {
+		if v.mode == LookupExact {
+			return fmt.Errorf("%w: the hash prefixes of %s can only be looked up canonicalizing the URLs", ErrInvalidList, path)
+		}
+		if prefixSize < 4 || prefixSize > sha256.Size {
+			return fmt.Errorf("%w: the hash prefix size must be between 4 and %d bytes", ErrInvalidList, sha256.Size)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read the threat list: %w", err)
+		}
+		if len(content)%prefixSize != 0 {
+			return fmt.Errorf("%w: %s is not a list of %d bytes hash prefixes", ErrInvalidList, path, prefixSize)
+		}
+
+		if v.hashPrefixes[prefixSize] == nil {
+			v.hashPrefixes[prefixSize] = map[string]string{}
+		}
+		for start := 0; start < len(content); start += prefixSize {
+			v.hashPrefixes[prefixSize][string(content[start:start+prefixSize])] = filepath.Base(path)
+		}
+		return nil
+	}
+}
+
+type listed struct {
+	value string
+	list  string
+}
+
+// Validator rejects the URLs found in locally loaded threat lists, so the URLs can be checked without
+// the Safe Browsing API. The lists are only loaded once.
+type Validator struct {
+	mode         LookupMode
+	hosts        map[string]string
+	urlPrefixes  []listed
+	hashPrefixes map[int]map[string]string
+}
+
+func (v *Validator) Name() string {
+	return "threatlist"
+}
+
+// ValidateURLs rejects the URLs found in the lists. The URLs only matching a hash prefix shorter than the full hash
+// are not rejected, but they can't be validated either, so an error wrapping url.ErrUnableToValidateURLs is returned.
+func (v *Validator) ValidateURLs(ctx context.Context, urls []string) (bool, error) {
+	var unconfirmedErr error
+	for _, aLongURL := range urls {
+		list, confirmed, err := v.lookup(aLongURL)
+		if err != nil {
+			return false, &url.RejectionError{Validator: v.Name(), Reason: err.Error()}
+		}
+		if list != "" && confirmed {
+			return false, &url.RejectionError{Validator: v.Name(), Reason: fmt.Sprintf("the url %s is in the threat list %s", aLongURL, list)}
+		}
+		if list != "" && unconfirmedErr == nil {
+			unconfirmedErr = fmt.Errorf("%w: the url %s matches a hash prefix of the threat list %s, which can't be confirmed without its full hash",
+				url.ErrUnableToValidateURLs, aLongURL, list)
+		}
+	}
+	if unconfirmedErr != nil {
+		return false, unconfirmedErr
+	}
+	return true, nil
+}
+
+// lookup returns the name of the list the URL is found in, empty if it's not listed. It's not confirmed if the URL
+// only matches a hash prefix shorter than the full hash.
+func (v *Validator) lookup(aLongURL string) (string, bool, error) {
+	hosts, expressions, err := v.lookupValues(aLongURL)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, host := range hosts {
+		if list, ok := v.hosts[host]; ok {
+			return list, true, nil
+		}
+	}
+	unconfirmedList := ""
+	for _, expression := range expressions {
+		for _, prefix := range v.urlPrefixes {
+			if strings.HasPrefix(expression, prefix.value) {
+				return prefix.list, true, nil
+			}
+		}
+		if len(v.hashPrefixes) == 0 {
+			continue
+		}
+		hash := sha256.Sum256([]byte(expression))
+		for prefixSize, prefixes := range v.hashPrefixes {
+			list, ok := prefixes[string(hash[:prefixSize])]
+			if !ok {
+				continue
+			}
+			if prefixSize == sha256.Size {
+				return list, true, nil
+			}
+			unconfirmedList = list
+		}
+	}
+	return unconfirmedList, false, nil
+}
+
+// lookupValues are the hosts and the expressions of the URL matched against the lists, depending on the mode.
+// The expressions are only hashed with LookupCanonical, as the hash prefixes can't be loaded with LookupExact.
+func (v *Validator) lookupValues(aLongURL string) ([]string, []string, error) {
+	if v.mode == LookupExact {
+		expression := withoutScheme(aLongURL)
+		host := v.normalizeHost(expression)
+		if host == "" {
+			return nil, nil, fmt.Errorf("the url %s has no host", aLongURL)
+		}
+		return []string{host}, []string{expression}, nil
+	}
+
+	canonical, err := canonicalize(aLongURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return canonical.hostSuffixes(), canonical.expressions(), nil
+}
+
+func (v *Validator) normalizeHost(host string) string {
+	if v.mode == LookupExact {
+		host = withoutScheme(host)
+		if end := strings.IndexAny(host, "/?#"); end >= 0 {
+			host = host[:end]
+		}
+		if colon := strings.LastIndexByte(host, ':'); colon >= 0 && isDigits(host[colon+1:]) {
+			host = host[:colon]
+		}
+		return strings.ToLower(host)
+	}
+	canonical, _ := canonicalHost(host)
+	return canonical
+}
+
+func (v *Validator) normalizeURLPrefix(prefix string) (string, error) {
+	if v.mode == LookupExact {
+		return withoutScheme(prefix), nil
+	}
+	canonical, err := canonicalize(prefix)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidList, err)
+	}
+	return canonical.expression(), nil
+}
+
+func withoutScheme(aURL string) string {
+	if separator := strings.Index(aURL, "://"); separator > 0 && isScheme(aURL[:separator]) {
+		return aURL[separator+3:]
+	}
+	return aURL
+}
+
+func readLines(path string, parse func(line string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open the threat list: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		err = parse(text)
+		if err != nil {
+			return fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to read the threat list: %w", err)
+	}
+	return nil
+}
+
+// NewValidator loads the lists the URLs are looked up in with the mode
+func NewValidator(mode LookupMode, lists ...List) (*Validator, error) {
+	v := &Validator{
+		mode:         mode,
+		hosts:        map[string]string{},
+		hashPrefixes: map[int]map[string]string{},
+	}
+	for _, list := range lists {
+		err := list(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
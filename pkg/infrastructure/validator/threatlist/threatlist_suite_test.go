@@ -0,0 +1,13 @@
+package threatlist_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestThreatlist(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Threatlist Suite")
+}
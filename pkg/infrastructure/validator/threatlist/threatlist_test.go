@@ -0,0 +1,182 @@
+package threatlist_test
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/WebEngineeringGroupI/backend/pkg/domain/url"
+	"github.com/WebEngineeringGroupI/backend/pkg/infrastructure/validator/threatlist"
+)
+
+const (
+	hostList      = "testdata/malware.hosts"
+	urlPrefixList = "testdata/phishing.prefixes"
+	// hashFile has the SHA256 hashes of evil.example.io/ and cdn.example.io/payload/
+	hashFile = "testdata/social-engineering.hashes"
+	// hashPrefixFile has the SHA256 prefix of 4 bytes of unwanted.example.io/
+	hashPrefixFile = "testdata/unwanted-software.hashes"
+)
+
+var _ = Describe("Threat list Validator", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	DescribeTable("rejects the URLs found in the lists looking them up as the Safe Browsing API does",
+		func(aLongURL string, isValidURL bool) {
+			validator, err := threatlist.NewValidator(threatlist.LookupCanonical,
+				threatlist.HostList(hostList),
+				threatlist.URLPrefixList(urlPrefixList),
+				threatlist.HashPrefixFile(hashFile, 32))
+			Expect(err).ToNot(HaveOccurred())
+
+			isValid, err := validator.ValidateURLs(ctx, []string{aLongURL})
+
+			Expect(isValid).To(Equal(isValidURL))
+			if isValidURL {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(url.ErrURLRejected))
+			}
+		},
+		Entry("a URL not in the lists", "https://google.com", true),
+		Entry("a listed host", "https://malware.example.com/download", false),
+		Entry("a subdomain of a listed host", "https://cdn.malware.example.com", false),
+		Entry("a listed host written with uppercase and dots", "https://MALWARE.example.com../", false),
+		Entry("a listed host escaped", "https://%6dalware.example.com", false),
+		Entry("the parent domain of a listed host", "https://example.com", true),
+		Entry("a listed IP written in decimal", "http://3405803783/", false),
+		Entry("a URL with a listed prefix", "https://bank.example.org/login/index.html", false),
+		Entry("a URL with a listed prefix and another scheme", "http://bank.example.org/login/", false),
+		Entry("a URL with a listed prefix after resolving its path", "https://bank.example.org/static/../login//form", false),
+		Entry("a URL of the same host without a listed prefix", "https://bank.example.org/", true),
+		Entry("a URL with a listed prefix written with other case", "https://shared.example.net/~phisher/page", false),
+		Entry("a URL with a listed hash", "https://evil.example.io/any/path?query=1", false),
+		Entry("a subdomain of a URL with a listed hash", "https://www.evil.example.io/", false),
+		Entry("a URL whose path prefix has a listed hash", "https://cdn.example.io/payload/bin.exe", false),
+		Entry("a URL whose path has no listed hash", "https://cdn.example.io/assets/app.js", true),
+		Entry("a URL without host", "https:///path", false),
+	)
+
+	DescribeTable("rejects the URLs found in the lists as they are written",
+		func(aLongURL string, isValidURL bool) {
+			validator, err := threatlist.NewValidator(threatlist.LookupExact,
+				threatlist.HostList(hostList),
+				threatlist.URLPrefixList(urlPrefixList))
+			Expect(err).ToNot(HaveOccurred())
+
+			isValid, err := validator.ValidateURLs(ctx, []string{aLongURL})
+
+			Expect(isValid).To(Equal(isValidURL))
+			if isValidURL {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(url.ErrURLRejected))
+			}
+		},
+		Entry("a URL not in the lists", "https://google.com", true),
+		Entry("a listed host", "https://malware.example.com/download", false),
+		Entry("a listed host with a port", "https://MALWARE.example.com:8443", false),
+		Entry("a subdomain of a listed host", "https://cdn.malware.example.com", true),
+		Entry("a listed IP written in decimal", "http://3405803783/", true),
+		Entry("a URL with a listed prefix", "https://bank.example.org/login/index.html", false),
+		Entry("a URL with a listed prefix after resolving its path", "https://bank.example.org/static/../login/", true),
+	)
+
+	When("the hashes are shorter than the full hash", func() {
+		It("can't validate the URLs matching a hash prefix, as it can't be confirmed", func() {
+			validator, err := threatlist.NewValidator(threatlist.LookupCanonical, threatlist.HashPrefixFile(hashPrefixFile, 4))
+			Expect(err).ToNot(HaveOccurred())
+
+			isValid, err := validator.ValidateURLs(ctx, []string{"https://unwanted.example.io/download"})
+
+			Expect(err).To(MatchError(url.ErrUnableToValidateURLs))
+			Expect(err).ToNot(MatchError(url.ErrURLRejected))
+			Expect(err.Error()).To(ContainSubstring("unwanted-software.hashes"))
+			Expect(isValid).To(BeFalse())
+		})
+
+		It("accepts the URLs not matching any hash prefix", func() {
+			validator, err := threatlist.NewValidator(threatlist.LookupCanonical, threatlist.HashPrefixFile(hashPrefixFile, 4))
+			Expect(err).ToNot(HaveOccurred())
+
+			isValid, err := validator.ValidateURLs(ctx, []string{"https://google.com"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isValid).To(BeTrue())
+		})
+
+		It("rejects the URLs found in other lists", func() {
+			validator, err := threatlist.NewValidator(threatlist.LookupCanonical,
+				threatlist.HostList(hostList),
+				threatlist.HashPrefixFile(hashPrefixFile, 4))
+			Expect(err).ToNot(HaveOccurred())
+
+			isValid, err := validator.ValidateURLs(ctx, []string{"https://unwanted.example.io/", "https://malware.example.com"})
+
+			Expect(err).To(MatchError(url.ErrURLRejected))
+			Expect(isValid).To(BeFalse())
+		})
+	})
+
+	It("names the list the URL is found in", func() {
+		validator, err := threatlist.NewValidator(threatlist.LookupCanonical, threatlist.HostList(hostList))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = validator.ValidateURLs(ctx, []string{"https://google.com", "https://malware.example.com"})
+
+		Expect(err).To(Equal(&url.RejectionError{
+			Validator: "threatlist",
+			Reason:    "the url https://malware.example.com is in the threat list malware.hosts",
+		}))
+	})
+
+	It("accepts the URLs when there are no lists", func() {
+		validator, err := threatlist.NewValidator(threatlist.LookupCanonical)
+		Expect(err).ToNot(HaveOccurred())
+
+		isValid, err := validator.ValidateURLs(ctx, []string{"https://malware.example.com"})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(isValid).To(BeTrue())
+	})
+
+	When("a list can't be loaded", func() {
+		It("fails if the file doesn't exist", func() {
+			_, err := threatlist.NewValidator(threatlist.LookupCanonical, threatlist.HostList("testdata/missing.hosts"))
+
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+
+		It("fails if the hash prefixes don't have the prefix size", func() {
+			_, err := threatlist.NewValidator(threatlist.LookupCanonical, threatlist.HashPrefixFile("testdata/truncated.hashes", 4))
+
+			Expect(err).To(MatchError(threatlist.ErrInvalidList))
+		})
+
+		It("fails if the hash prefix size is not valid", func() {
+			_, err := threatlist.NewValidator(threatlist.LookupCanonical, threatlist.HashPrefixFile(hashPrefixFile, 2))
+
+			Expect(err).To(MatchError(threatlist.ErrInvalidList))
+		})
+
+		It("fails if the hash prefixes are looked up as they are written", func() {
+			_, err := threatlist.NewValidator(threatlist.LookupExact, threatlist.HashPrefixFile(hashFile, 32))
+
+			Expect(err).To(MatchError(threatlist.ErrInvalidList))
+		})
+
+		It("fails if a URL prefix has no host", func() {
+			_, err := threatlist.NewValidator(threatlist.LookupCanonical, threatlist.URLPrefixList("testdata/invalid.prefixes"))
+
+			Expect(err).To(MatchError(threatlist.ErrInvalidList))
+			Expect(err.Error()).To(ContainSubstring("line 1"))
+		})
+	})
+})